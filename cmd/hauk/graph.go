@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/mnesler/hauk-tui/internal/gograph"
+)
+
+// graphKinds generate mermaid source from their arguments, reporting
+// anything left out of the diagram to stderr
+var graphKinds = map[string]func(args []string, stderr io.Writer) (string, error){
+	"packages": func(args []string, stderr io.Writer) (string, error) {
+		g, err := gograph.Packages(dirArg(args, 0))
+		if err != nil {
+			return "", err
+		}
+		for _, err := range g.Skipped {
+			fmt.Fprintf(stderr, "hauk graph: %v\n", err)
+		}
+		return g.Mermaid(), nil
+	},
+	"types": func(args []string, stderr io.Writer) (string, error) {
+		g, err := gograph.Types(dirArg(args, 0))
+		if err != nil {
+			return "", err
+		}
+		return g.Mermaid(), nil
+	},
+	"func": func(args []string, stderr io.Writer) (string, error) {
+		if len(args) == 0 {
+			return "", errors.New("graph func needs a function name, e.g. app.Model.Update")
+		}
+		g, err := gograph.FuncFlow(dirArg(args, 1), args[0])
+		if err != nil {
+			return "", err
+		}
+		for _, err := range g.Skipped {
+			fmt.Fprintf(stderr, "hauk graph: %v\n", err)
+		}
+		return g.Mermaid(), nil
+	},
+}
+
+// dirArg returns args[i], defaulting to the current directory
+func dirArg(args []string, i int) string {
+	if len(args) > i {
+		return args[i]
+	}
+	return "."
+}
+
+// runGraph implements `hauk graph [packages|types|func name] [dir]`, printing the
+// diagram as mermaid source
+func runGraph(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("graph", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	output := flags.String("o", "", "write the diagram to `file` instead of stdout")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: hauk graph [-o file] [packages|types|func name] [dir]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	// The kind defaults to packages
+	kind, rest := "packages", flags.Args()
+	if len(rest) > 0 {
+		if _, ok := graphKinds[rest[0]]; ok {
+			kind, rest = rest[0], rest[1:]
+		}
+	}
+
+	source, err := graphKinds[kind](rest, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "hauk graph: %v\n", err)
+		return 1
+	}
+
+	if err := writeOutput(*output, source, stdout); err != nil {
+		fmt.Fprintf(stderr, "hauk graph: %v\n", err)
+		return 1
+	}
+	return 0
+}
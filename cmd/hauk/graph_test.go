@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunGraph(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":       "module example.com/demo\n",
+		"main.go":      "package main\n\nimport \"example.com/demo/api\"\n",
+		"api/serve.go": "package api\n\ntype Server struct{ Addr string }\n\nfunc (s *Server) Run() error {\n\tif s.Addr == \"\" {\n\t\treturn nil\n\t}\n\treturn nil\n}\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, args := range [][]string{{"graph", dir}, {"graph", "packages", dir}} {
+		var stdout, stderr bytes.Buffer
+		if code := runSubcommand(args, &stdout, &stderr); code != 0 {
+			t.Fatalf("hauk %v exit code = %d, stderr = %s", args, code, stderr.String())
+		}
+		if !strings.Contains(stdout.String(), `node["."]`) || !strings.Contains(stdout.String(), "node --> api") {
+			t.Errorf("hauk %v output = %q, want the package graph", args, stdout.String())
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := runSubcommand([]string{"graph", "types", filepath.Join(dir, "api")}, &stdout, &stderr); code != 0 {
+		t.Fatalf("hauk graph types exit code = %d, stderr = %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "classDiagram") {
+		t.Errorf("hauk graph types output = %q, want a class diagram", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := runSubcommand([]string{"graph", "func", "api.Server.Run", dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("hauk graph func exit code = %d, stderr = %s", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `(["api.Server.Run()"])`) {
+		t.Errorf("hauk graph func output = %q, want the control flow of Run", stdout.String())
+	}
+	if code := runSubcommand([]string{"graph", "func"}, &stdout, &stderr); code != 1 {
+		t.Errorf("hauk graph func without a name exit code = %d, want 1", code)
+	}
+
+	out := filepath.Join(t.TempDir(), "packages.mmd")
+	stdout.Reset()
+	if code := runSubcommand([]string{"graph", "-o", out, dir}, &stdout, &stderr); code != 0 {
+		t.Fatalf("hauk graph -o exit code = %d, stderr = %s", code, stderr.String())
+	}
+	if data, err := os.ReadFile(out); err != nil || !strings.HasPrefix(string(data), "graph TD") {
+		t.Errorf("hauk graph -o wrote %q, %v", data, err)
+	}
+}
+
+func TestRunSubcommand_Unknown(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if code := runSubcommand([]string{"bogus"}, &stdout, &stderr); code != 2 {
+		t.Errorf("exit code = %d, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "unknown command") {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+
+	if code := runSubcommand([]string{"graph", t.TempDir()}, &stdout, &stderr); code != 1 {
+		t.Errorf("hauk graph outside a module exit code = %d, want 1", code)
+	}
+}
@@ -6,12 +6,35 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/mnesler/hauk-tui/internal/app"
+	"github.com/mnesler/hauk-tui/internal/clipboard"
 	"github.com/mnesler/hauk-tui/internal/config"
 	"github.com/mnesler/hauk-tui/internal/logger"
 	"github.com/mnesler/hauk-tui/internal/ui"
 )
 
 func main() {
+	// hauk docs [dir] starts the TUI on the diagrams in Markdown files and
+	// hauk watch <file> on one mermaid file; other subcommands print
+	// diagrams instead of starting it
+	var docsRoot, watchPath string
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "docs":
+			docsRoot = "."
+			if len(os.Args) > 2 {
+				docsRoot = os.Args[2]
+			}
+		case "watch":
+			if len(os.Args) != 3 {
+				fmt.Fprintln(os.Stderr, "Usage: hauk watch <file.mmd>")
+				os.Exit(2)
+			}
+			watchPath = os.Args[2]
+		default:
+			os.Exit(runSubcommand(os.Args[1:], os.Stdout, os.Stderr))
+		}
+	}
+
 	// Initialize logger with 1000 entry buffer
 	logger.Init(1000)
 	logger.StartupMessage("v0.1.0")
@@ -25,16 +48,29 @@ func main() {
 		logger.Component("config").Infof("Loaded config: theme=%s", cfg.Theme)
 	}
 	ui.SetActiveTheme(cfg.Theme)
+	if clipboard.Preferred, err = clipboard.ParseMode(cfg.Clipboard); err != nil {
+		logger.Component("config").Warnf("%v; using auto", err)
+	}
 
 	// Create the initial model
 	m := app.NewModel()
 	logger.Component("app").Info("Application model created")
+	if docsRoot != "" {
+		m = m.OpenDocs(docsRoot)
+	}
+	if watchPath != "" {
+		if m, err = m.OpenWatch(watchPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
 	// Start the Bubble Tea program
 	p := tea.NewProgram(
 		m,
-		tea.WithAltScreen(),       // Use alternate screen buffer
-		tea.WithMouseCellMotion(), // Enable mouse support
+		tea.WithAltScreen(),              // Use alternate screen buffer
+		tea.WithMouseCellMotion(),        // Enable mouse support
+		tea.WithOutput(clipboard.Output), // Copies write OSC 52 between frames
 	)
 
 	logger.Component("app").Info("Starting Bubble Tea program...")
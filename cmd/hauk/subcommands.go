@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// subcommand runs a non-interactive command and returns the exit code
+type subcommand func(args []string, stdout, stderr io.Writer) int
+
+// subcommands are run instead of the TUI when named as the first argument
+var subcommands = map[string]subcommand{
+	"graph": runGraph,
+}
+
+// runSubcommand dispatches args[0] to a subcommand
+func runSubcommand(args []string, stdout, stderr io.Writer) int {
+	name := args[0]
+	if name == "-h" || name == "--help" || name == "help" {
+		usage(stdout)
+		return 0
+	}
+
+	run, ok := subcommands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "hauk: unknown command %q\n\n", name)
+		usage(stderr)
+		return 2
+	}
+	return run(args[1:], stdout, stderr)
+}
+
+// usage lists the subcommands
+func usage(w io.Writer) {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Usage: hauk [command]")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Run without a command to start the TUI, with docs [dir] to start it on")
+	fmt.Fprintln(w, "the mermaid diagrams in the Markdown files under dir, or with watch <file>")
+	fmt.Fprintln(w, "to preview a mermaid file, re-rendering it whenever it's saved.")
+	fmt.Fprintf(w, "Commands: %s\n", strings.Join(names, ", "))
+}
+
+// writeOutput writes s to the file named by path, or to stdout if path is empty
+func writeOutput(path, s string, stdout io.Writer) error {
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	if path == "" {
+		_, err := io.WriteString(stdout, s)
+		return err
+	}
+	return os.WriteFile(path, []byte(s), 0644)
+}
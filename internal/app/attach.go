@@ -0,0 +1,205 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/attach"
+	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/logger"
+	"github.com/mnesler/hauk-tui/internal/ui"
+)
+
+// handleFileCommand runs /file <path>..., /file clear or /file to list staged files
+func (m Model) handleFileCommand(args []string) Model {
+	if len(args) == 0 {
+		if len(m.attachments) == 0 {
+			logger.Component("attach").Info("No files attached. Usage: /file <path>... | clear")
+			return m
+		}
+		logger.Component("attach").Infof("Attached to the next message: %s", strings.Join(m.attachments, ", "))
+		return m
+	}
+
+	if len(args) == 1 && args[0] == "clear" {
+		m.attachments = nil
+		m.editAttachments = nil
+		logger.Component("attach").Info("Cleared attached files")
+		return m
+	}
+
+	for _, path := range args {
+		if info := attach.Stat(path); info.Err != nil {
+			logger.Component("attach").Errorf("Cannot attach %s: %v", path, info.Err)
+			continue
+		}
+		if containsString(m.attachments, path) {
+			continue
+		}
+		m.attachments = append(m.attachments, path)
+		logger.Component("attach").Infof("Attached %s to the next message", path)
+	}
+	return m
+}
+
+// attachmentPaths returns the files that sending content would attach:
+// those staged with /file followed by any @mentions
+func (m Model) attachmentPaths(content string) []string {
+	paths := append([]string(nil), m.attachments...)
+	for _, path := range attach.Mentions(content) {
+		if !containsString(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// keptAttachments returns the files an edited message was sent with that
+// aren't attached again among paths. They're resent as they were.
+func (m Model) keptAttachments(paths []string) []chat.Attachment {
+	var kept []chat.Attachment
+	for _, a := range m.editAttachments {
+		if !containsString(paths, a.Path) {
+			kept = append(kept, a)
+		}
+	}
+	return kept
+}
+
+// newUserMessage builds the message for content with its attachments
+// loaded, keeping those of the message being edited
+func (m Model) newUserMessage(content string) (chat.Message, error) {
+	msg := chat.NewMessage(chat.RoleUser, content)
+
+	paths := m.attachmentPaths(content)
+	kept := m.keptAttachments(paths)
+	attachments, err := attach.LoadAll(paths, kept...)
+	if err != nil {
+		return msg, err
+	}
+	msg.Attachments = append(kept, attachments...)
+	return msg, nil
+}
+
+// completePath completes the @mention or /file argument before the cursor.
+// Returns false if there is nothing to complete, so Tab can do something else.
+func (m Model) completePath() (Model, bool) {
+	value := m.input.Value()
+	if m.input.Position() != len([]rune(value)) {
+		return m, false
+	}
+
+	// The word being typed is everything after the last space
+	start := strings.LastIndexAny(value, " \t\n") + 1
+	word := value[start:]
+
+	var prefix string
+	quote := false
+	switch {
+	case strings.HasPrefix(word, "@"):
+		prefix = word[1:]
+	case strings.HasPrefix(strings.ToLower(value), "/file ") && start > 0:
+		prefix = word
+		// After an unclosed quote the path runs from it, spaces and all;
+		// a path with spaces gets its opening quote when completed
+		if open := strings.LastIndex(value, `"`); strings.Count(value, `"`)%2 == 1 {
+			prefix = value[open+1:]
+		} else {
+			quote = true
+		}
+	default:
+		return m, false
+	}
+
+	matches := attach.Complete(prefix)
+	if len(matches) == 0 {
+		logger.Component("attach").Debugf("No files match %q", prefix)
+		return m, true
+	}
+
+	completion := attach.CommonPrefix(matches)
+	if len(matches) > 1 {
+		logger.Component("attach").Infof("Matches: %s", strings.Join(matches, "  "))
+	}
+	if completion != prefix {
+		if quote && strings.ContainsAny(completion, " \t") {
+			completion = `"` + completion
+		}
+		m.input.SetValue(value[:len(value)-len(prefix)] + completion)
+		m.input.CursorEnd()
+	}
+	return m, true
+}
+
+// refreshAttachmentStats works out and checks the files the composer would
+// attach when its text or the staged files change, for the preview to show
+// without touching the filesystem
+func (m Model) refreshAttachmentStats() Model {
+	key := m.input.Value() + "\x00" + strings.Join(m.attachments, "\x00")
+	if key == m.attachStatsFor {
+		return m
+	}
+
+	m.attachPaths = m.attachmentPaths(m.input.Value())
+	m.attachStats = make(map[string]attach.Info, len(m.attachPaths))
+	for _, path := range m.attachPaths {
+		m.attachStats[path] = attach.Stat(path)
+	}
+	m.attachStatsFor = key
+	return m
+}
+
+// renderAttachmentPreview summarises what will be attached to the message
+// being composed, or "" if nothing will be
+func (m Model) renderAttachmentPreview() string {
+	paths := m.attachPaths
+	kept := m.keptAttachments(paths)
+	if len(paths) == 0 && len(kept) == 0 {
+		return ""
+	}
+
+	muted := ui.GetTextMutedStyle(ui.ActiveTheme.InputBg)
+	errStyle := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.AccentUser).
+		Background(ui.ActiveTheme.InputBg)
+
+	var parts []string
+	var total int64
+	for _, a := range kept {
+		size := int64(len(a.Content))
+		total += size
+		parts = append(parts, muted.Render(fmt.Sprintf("%s (%s, as sent)", a.Path, attach.FormatSize(size))))
+	}
+	for _, path := range paths {
+		info, checked := m.attachStats[path]
+		if !checked {
+			parts = append(parts, muted.Render(path))
+			continue
+		}
+		if info.Err != nil {
+			parts = append(parts, errStyle.Render("✗ "+path))
+			continue
+		}
+		total += info.Size
+		parts = append(parts, muted.Render(fmt.Sprintf("%s (%s)", path, attach.FormatSize(info.Size))))
+	}
+
+	summary := fmt.Sprintf(" — %s will be sent", attach.FormatSize(total))
+	if total > attach.MaxTotalSize {
+		summary = fmt.Sprintf(" — %s is over the %s limit", attach.FormatSize(total), attach.FormatSize(attach.MaxTotalSize))
+	}
+
+	line := muted.Render("📎 ") + strings.Join(parts, muted.Render(" • ")) + muted.Render(summary)
+	return lipgloss.NewStyle().MaxWidth(m.width - 4).Render(line)
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
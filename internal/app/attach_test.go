@@ -0,0 +1,166 @@
+package app
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newAttachModel returns a model in a temp working directory holding a couple of files
+func newAttachModel(t *testing.T) Model {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"internal/app", "My Docs"} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for path, content := range map[string]string{
+		"internal/app/update.go": "package app\n",
+		"schema.sql":             "create table users (id int);\n",
+		"My Docs/spec.md":        "# Spec\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewModel()
+	m.width = 100
+	m.height = 40
+	return m
+}
+
+// send types content into the composer and presses Enter
+func send(m Model, content string) (Model, tea.Cmd) {
+	m.input.SetValue(content)
+	return press(m, tea.KeyMsg{Type: tea.KeyEnter})
+}
+
+func TestUpdate_FileCommand(t *testing.T) {
+	m := newAttachModel(t)
+
+	m, _ = send(m, "/file schema.sql missing.sql")
+	if len(m.attachments) != 1 || m.attachments[0] != "schema.sql" {
+		t.Fatalf("attachments = %v, want only the existing file", m.attachments)
+	}
+
+	m, cmd := send(m, "diagram this schema")
+	if cmd == nil {
+		t.Fatal("Sending should request a response")
+	}
+	last := m.messages.Last().Message
+	if len(last.Attachments) != 1 || last.Attachments[0].Content != "create table users (id int);\n" {
+		t.Errorf("Attachments = %+v, want schema.sql", last.Attachments)
+	}
+	if last.Content != "diagram this schema" {
+		t.Errorf("Content = %q, attachments should not be inlined into the shown text", last.Content)
+	}
+	if !strings.Contains(last.PromptContent(), "File: schema.sql") {
+		t.Error("PromptContent() should include the file for the provider")
+	}
+	if len(m.attachments) != 0 {
+		t.Error("Staged files should be cleared after sending")
+	}
+
+	m, _ = send(m, "/file schema.sql")
+	m, _ = send(m, "/file clear")
+	if len(m.attachments) != 0 {
+		t.Errorf("/file clear left %v", m.attachments)
+	}
+}
+
+func TestUpdate_Mention(t *testing.T) {
+	m := newAttachModel(t)
+
+	m, _ = send(m, "diagram the flow in @internal/app/update.go, ask @someone")
+	attachments := m.messages.Last().Message.Attachments
+	if len(attachments) != 1 || attachments[0].Path != "internal/app/update.go" {
+		t.Errorf("Attachments = %+v, want update.go only", attachments)
+	}
+}
+
+func TestUpdate_AttachFailureKeepsPrompt(t *testing.T) {
+	m := newAttachModel(t)
+	m, _ = send(m, "/file schema.sql")
+	if err := os.Remove("schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+
+	m, cmd := send(m, "diagram this schema")
+	if cmd != nil || m.messages.Len() != 0 {
+		t.Error("A message whose attachment can't be read should not be sent")
+	}
+	if m.input.Value() != "diagram this schema" {
+		t.Errorf("Composer = %q, want the prompt kept", m.input.Value())
+	}
+}
+
+func TestUpdate_CompletePath(t *testing.T) {
+	m := newAttachModel(t)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "look at @int", want: "look at @internal/"},
+		{input: "look at @internal/app/u", want: "look at @internal/app/update.go"},
+		{input: "/file sch", want: "/file schema.sql"},
+		{input: "/file My", want: `/file "My Docs/`},
+		{input: `/file "My Docs/s`, want: `/file "My Docs/spec.md`},
+		{input: "look at @nothing", want: "look at @nothing"},
+	}
+
+	for _, tt := range tests {
+		m.input.SetValue(tt.input)
+		m.input.CursorEnd()
+		got, _ := press(m, tea.KeyMsg{Type: tea.KeyTab})
+		if got.input.Value() != tt.want {
+			t.Errorf("Tab after %q = %q, want %q", tt.input, got.input.Value(), tt.want)
+		}
+		if got.selecting {
+			t.Errorf("Tab after %q should complete, not enter selection mode", tt.input)
+		}
+	}
+}
+
+func TestUpdate_FileQuotedPath(t *testing.T) {
+	m := newAttachModel(t)
+
+	m, _ = send(m, `/file "My Docs/spec.md" schema.sql`)
+	if want := []string{"My Docs/spec.md", "schema.sql"}; !reflect.DeepEqual(m.attachments, want) {
+		t.Errorf("attachments = %q, want %q", m.attachments, want)
+	}
+}
+
+func TestRenderAttachmentPreview(t *testing.T) {
+	m := newAttachModel(t)
+
+	if m.renderAttachmentPreview() != "" {
+		t.Error("No preview expected without attachments")
+	}
+
+	m, _ = send(m, "/file schema.sql")
+	m, _ = press(m, keys("and @internal/app/update.go")...)
+	preview := m.renderAttachmentPreview()
+	for _, want := range []string{"schema.sql (29 B)", "update.go (12 B)", "will be sent"} {
+		if !strings.Contains(preview, want) {
+			t.Errorf("Preview %q missing %q", preview, want)
+		}
+	}
+
+	// Rendering uses what was checked when the composer changed
+	if err := os.Remove("schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if again := m.renderAttachmentPreview(); again != preview {
+		t.Errorf("Preview changed without the composer changing:\n%s\n%s", preview, again)
+	}
+
+	if got := strings.Count(m.renderInputBar(), "\n"); got != strings.Count(NewModel().renderInputBar(), "\n") {
+		t.Error("The preview should not change the input bar height")
+	}
+}
@@ -0,0 +1,202 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/logger"
+	"github.com/mnesler/hauk-tui/internal/ui"
+)
+
+// branchRow is one line of the branch navigator
+type branchRow struct {
+	node   *chat.Node
+	depth  int
+	active bool // On the active branch
+}
+
+// branchRows flattens the conversation tree in display order
+func (m Model) branchRows() []branchRow {
+	active := make(map[int]bool)
+	for _, n := range m.messages.Path() {
+		active[n.ID] = true
+	}
+
+	var rows []branchRow
+	m.messages.Walk(func(n *chat.Node, depth int) bool {
+		rows = append(rows, branchRow{node: n, depth: depth, active: active[n.ID]})
+		return true
+	})
+	return rows
+}
+
+// showBranchNavigator opens the branch navigator with the newest message selected
+func (m Model) showBranchNavigator() Model {
+	rows := m.branchRows()
+	if len(rows) == 0 {
+		logger.Component("chat").Info("No conversation to navigate yet")
+		return m
+	}
+
+	m.branchCursor = 0
+	if last := m.messages.Last(); last != nil {
+		for i, row := range rows {
+			if row.node.ID == last.ID {
+				m.branchCursor = i
+			}
+		}
+	}
+
+	m.showBranches = true
+	m.input.Blur()
+	return m
+}
+
+// updateBranchNavigator handles input when the branch navigator is active
+func (m Model) updateBranchNavigator(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.branchRows()
+
+	switch msg.String() {
+	case "esc", "ctrl+t", "q":
+		m.showBranches = false
+		m.input.Focus()
+
+	case "up", "k":
+		if m.branchCursor > 0 {
+			m.branchCursor--
+		}
+
+	case "down", "j":
+		if m.branchCursor < len(rows)-1 {
+			m.branchCursor++
+		}
+
+	case "enter":
+		// Switch the conversation to the branch through the selected message
+		if m.branchCursor < len(rows) {
+			node := rows[m.branchCursor].node
+			m.messages.Activate(node.ID)
+			m.currentDiagram = latestDiagram(m.messages.Messages())
+			m.editingID = -1
+			logger.Component("chat").Infof("Switched to branch at message %d", node.ID)
+		}
+		m.showBranches = false
+		m.input.Focus()
+	}
+
+	return m, nil
+}
+
+// renderBranchNavigator renders the conversation tree as an indented list
+func (m Model) renderBranchNavigator() string {
+	modalWidth := m.width * 2 / 3
+	if modalWidth < 50 {
+		modalWidth = 50
+	}
+	maxRows := m.height - 10
+	if maxRows < 5 {
+		maxRows = 5
+	}
+
+	rows := m.branchRows()
+
+	// Scroll so the cursor stays visible
+	start := 0
+	if m.branchCursor >= maxRows {
+		start = m.branchCursor - maxRows + 1
+	}
+	end := start + maxRows
+	if end > len(rows) {
+		end = len(rows)
+	}
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.AccentUser).
+		Background(ui.ActiveTheme.UserMsgBg).
+		Bold(true)
+	activeStyle := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.TextPrimary)
+	inactiveStyle := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.TextMuted)
+
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		row := rows[i]
+
+		marker := "○"
+		style := inactiveStyle
+		if row.active {
+			marker = "●"
+			style = activeStyle
+		}
+		if i == m.branchCursor {
+			style = selectedStyle
+		}
+
+		label := "You"
+		switch row.node.Message.Role {
+		case chat.RoleAgent:
+			label = "Agent"
+		case chat.RoleSystem:
+			label = "Hauk"
+		}
+
+		line := fmt.Sprintf("%s%s %s: %s",
+			strings.Repeat("  ", row.depth),
+			marker,
+			label,
+			firstLine(row.node.Message.Content),
+		)
+		lines = append(lines, style.Render(truncate(line, modalWidth-6)))
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.TextPrimary).
+		Bold(true).
+		Render("Conversation Branches")
+
+	instructions := ui.GetTextMutedStyle(ui.ActiveTheme.ChatBg).
+		Render("↑/↓: navigate • Enter: switch to branch • Esc: close")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		instructions,
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Background(ui.ActiveTheme.ChatBg).
+		Foreground(ui.ActiveTheme.TextPrimary).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ActiveTheme.AccentUser).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	return modalStyle.Render(content)
+}
+
+// firstLine returns the first line of s
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// truncate shortens s to at most width runes, marking the cut with an ellipsis
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/chat"
+)
+
+func TestBranchNavigator_Switch(t *testing.T) {
+	m := newConversationModel()
+	original := m.messages.Path()
+	m.messages.Branch(original[2].ID, chat.NewMessage(chat.RoleUser, "make it top-down"))
+	m.currentDiagram = latestDiagram(m.messages.Messages())
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = newModel.(Model)
+	if !m.showBranches {
+		t.Fatal("After Ctrl+T, showBranches should be true")
+	}
+
+	rows := m.branchRows()
+	if len(rows) != 5 {
+		t.Fatalf("branchRows() length = %d, want 5", len(rows))
+	}
+	if rows[m.branchCursor].node.Message.Content != "make it top-down" {
+		t.Errorf("Cursor starts on %q, want newest message", rows[m.branchCursor].node.Message.Content)
+	}
+
+	// Rows are depth-first: the original continuation comes before the edit
+	for i, row := range rows {
+		if row.node.ID == original[3].ID {
+			m.branchCursor = i
+		}
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+
+	if m.showBranches {
+		t.Error("After Enter, showBranches should be false")
+	}
+	if m.messages.Len() != 4 || m.messages.Last().ID != original[3].ID {
+		t.Error("After Enter, the original branch should be active")
+	}
+	if m.currentDiagram != "graph LR\n    A --> B" {
+		t.Errorf("currentDiagram = %q, want the original branch's diagram", m.currentDiagram)
+	}
+}
+
+func TestBranchNavigator_Empty(t *testing.T) {
+	m := NewModel()
+	m = m.showBranchNavigator()
+
+	if m.showBranches {
+		t.Error("Branch navigator should not open without messages")
+	}
+}
+
+func TestUpdate_SessionSaveAndLoad(t *testing.T) {
+	m := newConversationModel()
+	m.input.SetValue("/session save demo")
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+
+	if m.sessionName != "demo" {
+		t.Errorf("sessionName = %q, want %q", m.sessionName, "demo")
+	}
+
+	fresh := NewModel()
+	fresh.input.SetValue("/session load demo")
+	newModel, _ = fresh.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	fresh = newModel.(Model)
+
+	if fresh.messages.Len() != 4 {
+		t.Errorf("Loaded messages length = %d, want 4", fresh.messages.Len())
+	}
+	if fresh.currentDiagram != m.currentDiagram {
+		t.Errorf("Loaded currentDiagram = %q, want %q", fresh.currentDiagram, m.currentDiagram)
+	}
+}
+
+func TestUpdate_SessionLoadDropsPendingRetry(t *testing.T) {
+	saved := newConversationModel()
+	saved, _ = send(saved, "/session save other")
+
+	m := newConversationModel()
+	m.editAttachments = []chat.Attachment{{Path: "spec.md"}}
+	retried := m.messages.Last().ID
+	m, retry := send(m, "/retry")
+	m, _ = send(m, "/session load other")
+	if m.editAttachments != nil || len(m.retryTips) != 0 {
+		t.Errorf("loading left state from the old conversation: attachments %v, retries %v", m.editAttachments, m.retryTips)
+	}
+
+	// The loaded tree reuses the retried message's ID; the answer mustn't land there
+	newModel, _ := m.Update(retry())
+	m = newModel.(Model)
+	if _, count := m.messages.Siblings(retried); count != 1 || m.messages.Last().Message.Content != "second answer" {
+		t.Errorf("late retry was grafted onto the loaded conversation")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"short", 10, "short"},
+		{"exactly", 7, "exactly"},
+		{"too long text", 6, "too l…"},
+		{"abc", 1, "…"},
+		{"abc", 0, "abc"},
+	}
+
+	for _, tt := range tests {
+		if got := truncate(tt.in, tt.width); got != tt.want {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tt.in, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestBranchNavigator_KeepsResponses(t *testing.T) {
+	m := newConversationModel()
+	m = m.showBranchNavigator()
+	count := m.messages.Len()
+
+	newModel, _ := m.Update(AgentResponseMsg{Content: "late answer"})
+	m = newModel.(Model)
+
+	if m.messages.Len() != count+1 || m.messages.Last().Message.Content != "late answer" {
+		t.Error("Responses arriving while the branch navigator is open should still be added")
+	}
+	if !m.showBranches {
+		t.Error("Branch navigator should stay open")
+	}
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/command"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// handleCommand runs a parsed slash command
+func (m Model) handleCommand(cmdType command.CommandType, args []string) (Model, tea.Cmd) {
+	switch cmdType {
+	case command.CommandTheme:
+		m = m.showThemeSelectorModal()
+		logger.Component("command").Info("Theme selector opened")
+
+	case command.CommandRetry:
+		return m.retryLastResponse()
+
+	case command.CommandBranches:
+		m = m.showBranchNavigator()
+
+	case command.CommandSession:
+		m = m.handleSessionCommand(args)
+
+	case command.CommandCopy:
+		return m, m.handleCopyCommand(args)
+
+	case command.CommandFile:
+		m = m.handleFileCommand(args)
+
+	case command.CommandGraph:
+		return m, m.handleGraphCommand(args)
+
+	case command.CommandImport:
+		return m, m.handleImportCommand(args)
+
+	case command.CommandDocs:
+		m = m.handleDocsCommand(args)
+
+	case command.CommandNew:
+		m = m.handleNewCommand(args)
+
+	case command.CommandEdit:
+		if len(args) > 0 && args[0] == "inline" {
+			return m.openSourceEditor(), nil
+		}
+		return m, m.handleEditCommand()
+	}
+
+	return m, nil
+}
@@ -0,0 +1,85 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/clipboard"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// copyToClipboard puts text on the clipboard; tests replace it
+var copyToClipboard = clipboard.Copy
+
+// CopiedMsg reports the result of a clipboard copy
+type CopiedMsg struct {
+	What   string // What was copied, for the log
+	Chars  int
+	Method clipboard.Method
+	Err    error
+}
+
+// handleCopyCommand runs /copy diagram|message|last
+func (m Model) handleCopyCommand(args []string) tea.Cmd {
+	target := "diagram"
+	if len(args) > 0 {
+		target = args[0]
+	}
+
+	switch target {
+	case "diagram":
+		return m.copyDiagram()
+	case "message":
+		return m.copyLastAgentMessage()
+	case "last":
+		return m.copyLastMessage()
+	}
+
+	logger.Component("clipboard").Warnf("Unknown copy target %q. Usage: /copy diagram|message|last", target)
+	return nil
+}
+
+// copyDiagram copies the current diagram's mermaid source
+func (m Model) copyDiagram() tea.Cmd {
+	if m.currentDiagram == "" {
+		logger.Component("clipboard").Info("No diagram to copy yet")
+		return nil
+	}
+	return copyText("diagram", m.currentDiagram)
+}
+
+// copyLastAgentMessage copies the most recent agent response
+func (m Model) copyLastAgentMessage() tea.Cmd {
+	node := m.messages.LastWithRole(chat.RoleAgent)
+	if node == nil {
+		logger.Component("clipboard").Info("No agent message to copy yet")
+		return nil
+	}
+	return copyText("agent message", node.Message.Content)
+}
+
+// copyLastMessage copies the most recent message from either side
+func (m Model) copyLastMessage() tea.Cmd {
+	node := m.messages.Last()
+	if node == nil {
+		logger.Component("clipboard").Info("No message to copy yet")
+		return nil
+	}
+	return copyText("last message", node.Message.Content)
+}
+
+// copyText copies text off the update loop and reports back with a CopiedMsg
+func copyText(what, text string) tea.Cmd {
+	return func() tea.Msg {
+		method, err := copyToClipboard(text)
+		return CopiedMsg{What: what, Chars: len(text), Method: method, Err: err}
+	}
+}
+
+// logCopied reports a finished copy in the log panel
+func logCopied(msg CopiedMsg) {
+	if msg.Err != nil {
+		logger.Component("clipboard").Errorf("Failed to copy %s: %v", msg.What, msg.Err)
+		return
+	}
+	logger.Component("clipboard").Infof("Copied %s (%d chars) via %s", msg.What, msg.Chars, msg.Method)
+}
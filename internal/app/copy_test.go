@@ -0,0 +1,107 @@
+package app
+
+import (
+	"errors"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/clipboard"
+)
+
+// stubClipboard replaces the clipboard for the duration of a test and
+// returns a pointer to the last copied text
+func stubClipboard(t *testing.T, err error) *string {
+	t.Helper()
+	var copied string
+	original := copyToClipboard
+	copyToClipboard = func(text string) (clipboard.Method, error) {
+		copied = text
+		return clipboard.MethodOSC52, err
+	}
+	t.Cleanup(func() { copyToClipboard = original })
+	return &copied
+}
+
+func TestUpdate_CopyCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "default copies diagram", input: "/copy", want: "graph LR\n    A --> B"},
+		{name: "diagram", input: "/copy diagram", want: "graph LR\n    A --> B"},
+		{name: "agent message", input: "/copy message", want: "second answer"},
+		{name: "last message", input: "/copy last", want: "follow-up"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copied := stubClipboard(t, nil)
+			m := newConversationModel()
+			m.messages.Append(chat.NewMessage(chat.RoleUser, "follow-up"))
+			m.input.SetValue(tt.input)
+
+			_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			if cmd == nil {
+				t.Fatalf("%s should produce a copy command", tt.input)
+			}
+
+			msg, ok := cmd().(CopiedMsg)
+			if !ok {
+				t.Fatalf("copy command produced %T, want CopiedMsg", msg)
+			}
+			if msg.Err != nil {
+				t.Errorf("CopiedMsg.Err = %v", msg.Err)
+			}
+			if *copied != tt.want {
+				t.Errorf("copied %q, want %q", *copied, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdate_CopyKey(t *testing.T) {
+	copied := stubClipboard(t, nil)
+	m := newConversationModel()
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	if cmd == nil {
+		t.Fatal("Ctrl+Y should produce a copy command")
+	}
+	cmd()
+
+	if *copied != m.currentDiagram {
+		t.Errorf("copied %q, want current diagram %q", *copied, m.currentDiagram)
+	}
+}
+
+func TestUpdate_CopyNothing(t *testing.T) {
+	stubClipboard(t, nil)
+
+	for _, input := range []string{"/copy diagram", "/copy message", "/copy last", "/copy bogus"} {
+		m := NewModel()
+		m.input.SetValue(input)
+
+		if _, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter}); cmd != nil {
+			t.Errorf("%s on an empty conversation should not copy anything", input)
+		}
+	}
+}
+
+func TestUpdate_CopyFailed(t *testing.T) {
+	stubClipboard(t, errors.New("no clipboard"))
+	m := newConversationModel()
+
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	msg := cmd().(CopiedMsg)
+	if msg.Err == nil {
+		t.Fatal("CopiedMsg.Err should carry the clipboard failure")
+	}
+
+	// The failure is only logged; the model is otherwise unchanged
+	newModel, _ := m.Update(msg)
+	if newModel.(Model).messages.Len() != m.messages.Len() {
+		t.Error("A failed copy should not change the conversation")
+	}
+}
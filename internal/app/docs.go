@@ -0,0 +1,235 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/docs"
+	"github.com/mnesler/hauk-tui/internal/logger"
+	"github.com/mnesler/hauk-tui/internal/ui"
+)
+
+// docsUsage lists the /docs subcommands
+const docsUsage = "Usage: /docs [dir] | /docs save"
+
+// OpenDocs starts in docs mode: the mermaid diagrams in the Markdown files
+// under root are offered for editing and /docs save writes them back
+func (m Model) OpenDocs(root string) Model {
+	m.docsRoot = root
+	return m.openDocsPicker(root)
+}
+
+// handleDocsCommand runs /docs, /docs <dir> and /docs save
+func (m Model) handleDocsCommand(args []string) Model {
+	if len(args) == 0 {
+		return m.openDocsPicker(m.docsRootOrDefault())
+	}
+	if args[0] == "save" {
+		return m.saveDocBlock()
+	}
+	if len(args) == 1 {
+		m.docsRoot = args[0]
+		return m.openDocsPicker(args[0])
+	}
+
+	logger.Component("docs").Warn(docsUsage)
+	return m
+}
+
+// docsRootOrDefault returns where /docs looks, the working directory
+// unless hauk docs or /docs <dir> said otherwise
+func (m Model) docsRootOrDefault() string {
+	if m.docsRoot == "" {
+		return "."
+	}
+	return m.docsRoot
+}
+
+// openDocsPicker scans root for mermaid blocks and lists them
+func (m Model) openDocsPicker(root string) Model {
+	blocks, err := docs.Scan(root)
+	if err != nil {
+		logger.Component("docs").Errorf("Failed to scan %s: %v", root, err)
+		return m
+	}
+	if len(blocks) == 0 {
+		logger.Component("docs").Infof("No mermaid diagrams found in Markdown files under %s", root)
+		return m
+	}
+
+	m.docBlocks = blocks
+	m.docsCursor = 0
+	m.showDocs = true
+	m.input.Blur()
+	logger.Component("docs").Infof("Found %d mermaid diagrams under %s", len(blocks), root)
+	return m
+}
+
+// closeDocsPicker hides the picker and returns focus to the composer
+func (m Model) closeDocsPicker() Model {
+	m.showDocs = false
+	m.input.Focus()
+	return m
+}
+
+// updateDocsPicker handles keys while the docs picker is open
+func (m Model) updateDocsPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		return m.closeDocsPicker(), nil
+
+	case "up", "k":
+		if m.docsCursor > 0 {
+			m.docsCursor--
+		}
+
+	case "down", "j":
+		if m.docsCursor < len(m.docBlocks)-1 {
+			m.docsCursor++
+		}
+
+	case "enter":
+		m = m.closeDocsPicker()
+		return m.loadDocBlock(m.docBlocks[m.docsCursor]), nil
+	}
+
+	return m, nil
+}
+
+// loadDocBlock makes a Markdown diagram current and remembers where to
+// write it back
+func (m Model) loadDocBlock(b docs.Block) Model {
+	m.docBlock = &b
+	m = m.loadGeneratedDiagram(DiagramGeneratedMsg{
+		Title:  "Diagram from " + b.Title(),
+		Source: b.Source,
+	})
+	m.docsNodeID = m.messages.Last().ID
+	logger.Component("docs").Infof("Editing %s; /docs save writes the current diagram back", b.Title())
+	return m
+}
+
+// saveDocBlock writes the current diagram back into its Markdown file
+func (m Model) saveDocBlock() Model {
+	if m.docBlock == nil {
+		logger.Component("docs").Warn("No Markdown diagram open. Pick one with /docs first")
+		return m
+	}
+	if m.currentDiagram == "" {
+		logger.Component("docs").Warn("No diagram to save")
+		return m
+	}
+	if !m.diagramFromDocBlock() {
+		logger.Component("docs").Warnf("The current diagram didn't come from %s; pick it again with /docs to save over it", m.docBlock.Title())
+		return m
+	}
+
+	saved, err := docs.Save(*m.docBlock, m.currentDiagram)
+	if err != nil {
+		logger.Component("docs").Errorf("Failed to save %s: %v", m.docBlock.Title(), err)
+		return m
+	}
+
+	m.docBlock = &saved
+	logger.Component("docs").Infof("Saved diagram to %s", saved.Title())
+	return m
+}
+
+// diagramFromDocBlock reports whether the current diagram descends from the
+// open docs block: the block was loaded on the active branch, no other
+// diagram was loaded after it, and the current diagram is one of those
+// since. The agent and /edit may have changed it along the way.
+func (m Model) diagramFromDocBlock() bool {
+	path := m.messages.Path()
+	start := pathIndex(path, m.docsNodeID)
+	if start == len(path) {
+		return false
+	}
+
+	for _, node := range path[start+1:] {
+		msg := node.Message
+		if msg.Role == chat.RoleSystem && msg.HasDiagram() && !strings.HasPrefix(msg.Content, editedDiagramTitle+":") {
+			return false
+		}
+	}
+	for _, node := range path[start:] {
+		if node.Message.Diagram == m.currentDiagram {
+			return true
+		}
+	}
+	return false
+}
+
+// renderDocsPicker lists the Markdown diagrams with a preview of each
+func (m Model) renderDocsPicker() string {
+	modalWidth := m.width * 2 / 3
+	if modalWidth < 50 {
+		modalWidth = 50
+	}
+	maxRows := m.height - 10
+	if maxRows < 5 {
+		maxRows = 5
+	}
+
+	// Scroll so the cursor stays visible
+	start := 0
+	if m.docsCursor >= maxRows {
+		start = m.docsCursor - maxRows + 1
+	}
+	end := start + maxRows
+	if end > len(m.docBlocks) {
+		end = len(m.docBlocks)
+	}
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.AccentUser).
+		Background(ui.ActiveTheme.UserMsgBg).
+		Bold(true)
+	normalStyle := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.TextPrimary)
+	mutedStyle := ui.GetTextMutedStyle(ui.ActiveTheme.ChatBg)
+
+	lines := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		b := m.docBlocks[i]
+		style := normalStyle
+		if i == m.docsCursor {
+			style = selectedStyle
+		}
+		line := style.Render(truncate(b.Title(), modalWidth-6))
+		if i == m.docsCursor {
+			line += "\n" + mutedStyle.Render(truncate("  "+firstLine(b.Source), modalWidth-6))
+		}
+		lines = append(lines, line)
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.TextPrimary).
+		Bold(true).
+		Render(fmt.Sprintf("Markdown Diagrams (%d)", len(m.docBlocks)))
+
+	instructions := mutedStyle.
+		Render("↑/↓: navigate • Enter: edit with the agent • Esc: close")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		instructions,
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Background(ui.ActiveTheme.ChatBg).
+		Foreground(ui.ActiveTheme.TextPrimary).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ActiveTheme.AccentUser).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	return modalStyle.Render(content)
+}
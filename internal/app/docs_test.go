@@ -0,0 +1,127 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const docsFixture = "# Design\n\nIntro.\n\n## Flow\n\n```mermaid\nflowchart TD\n    A --> B\n```\n\nOutro.\n"
+
+func writeDocsFixture(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "design.md"), []byte(docsFixture), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestOpenDocs_PickAndSave(t *testing.T) {
+	dir := writeDocsFixture(t)
+
+	m := NewModel().OpenDocs(dir)
+	if !m.showDocs || len(m.docBlocks) != 1 {
+		t.Fatalf("picker not opened with one block: show=%v blocks=%d", m.showDocs, len(m.docBlocks))
+	}
+
+	// Resizes still reach the layout while the picker is open
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	if !m.showDocs || m.width != 120 {
+		t.Fatalf("resize not applied under picker: show=%v width=%d", m.showDocs, m.width)
+	}
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.showDocs {
+		t.Fatal("picker still open after Enter")
+	}
+	if m.docBlock == nil || m.currentDiagram != "flowchart TD\n    A --> B" {
+		t.Fatalf("diagram not loaded: %q", m.currentDiagram)
+	}
+	msgs := m.messages.Messages()
+	if last := msgs[len(msgs)-1]; !strings.Contains(last.Content, "design.md:7 Flow") {
+		t.Errorf("message doesn't name the block: %q", last.Content)
+	}
+
+	// The agent refines the diagram
+	responded, _ := m.Update(AgentResponseMsg{Content: "Added C", Diagram: "flowchart TD\n    A --> B\n    B --> C"})
+	m = responded.(Model)
+	m, _ = send(m, "/docs save")
+
+	data, err := os.ReadFile(filepath.Join(dir, "design.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(docsFixture, "    A --> B\n", "    A --> B\n    B --> C\n", 1)
+	if string(data) != want {
+		t.Errorf("file after save =\n%q\nwant\n%q", data, want)
+	}
+	if m.docBlock.Source != m.currentDiagram {
+		t.Errorf("block not updated after save: %q", m.docBlock.Source)
+	}
+}
+
+func TestDocsPicker_Keys(t *testing.T) {
+	dir := writeDocsFixture(t)
+	second := "```mermaid\nsequenceDiagram\n```\n"
+	if err := os.WriteFile(filepath.Join(dir, "seq.md"), []byte(second), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, _ := send(NewModel(), "/docs "+dir)
+	if !m.showDocs || len(m.docBlocks) != 2 {
+		t.Fatalf("picker not opened with two blocks: show=%v blocks=%d", m.showDocs, len(m.docBlocks))
+	}
+
+	m, _ = press(m, keys("jj")...)
+	if m.docsCursor != 1 {
+		t.Errorf("cursor = %d, want 1", m.docsCursor)
+	}
+	m, _ = press(m, keys("k")...)
+	if m.docsCursor != 0 {
+		t.Errorf("cursor = %d, want 0", m.docsCursor)
+	}
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.showDocs || m.docBlock != nil {
+		t.Errorf("Esc should close without loading: show=%v block=%v", m.showDocs, m.docBlock)
+	}
+}
+
+func TestDocsSave_OtherDiagram(t *testing.T) {
+	dir := writeDocsFixture(t)
+	m, _ := send(NewModel(), "/docs "+dir)
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	// A diagram from somewhere else replaces the block's
+	m = m.loadGeneratedDiagram(DiagramGeneratedMsg{Title: "Package graph", Source: "graph LR\n    app --> chat"})
+	m, _ = send(m, "/docs save")
+
+	data, err := os.ReadFile(filepath.Join(dir, "design.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != docsFixture {
+		t.Errorf("file changed by saving an unrelated diagram:\n%s", data)
+	}
+
+	// Edits of the block's diagram can still be saved
+	m, _ = send(m, "/docs")
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m = m.recordEditedDiagram("flowchart TD\n    A --> C", m.currentDiagram)
+	m, _ = send(m, "/docs save")
+	if data, _ := os.ReadFile(filepath.Join(dir, "design.md")); !strings.Contains(string(data), "A --> C") {
+		t.Errorf("edited diagram not saved:\n%s", data)
+	}
+}
+
+func TestDocsSave_WithoutBlock(t *testing.T) {
+	m, _ := send(NewModel(), "/docs save")
+	if m.docBlock != nil {
+		t.Error("save without a picked block should do nothing")
+	}
+}
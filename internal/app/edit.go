@@ -0,0 +1,110 @@
+package app
+
+import (
+	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// isEditing reports whether an earlier user message is loaded in the composer
+func (m Model) isEditing() bool {
+	return m.editingID >= 0
+}
+
+// editPrev selects the previous (older) user message and loads it into the composer
+func (m Model) editPrev() Model {
+	path := m.messages.Path()
+	start := len(path)
+	if m.isEditing() {
+		start = pathIndex(path, m.editingID)
+	}
+
+	for i := start - 1; i >= 0; i-- {
+		if path[i].Message.Role == chat.RoleUser {
+			return m.startEdit(path[i])
+		}
+	}
+	return m
+}
+
+// editNext selects the next (newer) user message, or cancels editing past the newest
+func (m Model) editNext() Model {
+	if !m.isEditing() {
+		return m
+	}
+
+	path := m.messages.Path()
+	for i := pathIndex(path, m.editingID) + 1; i < len(path); i++ {
+		if path[i].Message.Role == chat.RoleUser {
+			return m.startEdit(path[i])
+		}
+	}
+	return m.cancelEdit()
+}
+
+// startEdit loads the user message in node into the composer
+func (m Model) startEdit(node *chat.Node) Model {
+	// Keep whatever was being typed so cancelling can restore it
+	if !m.isEditing() {
+		m.editDraft = m.input.Value()
+	}
+
+	m.editingID = node.ID
+	m.editAttachments = node.Message.Attachments
+	m.input.SetValue(node.Message.Content)
+	m.input.CursorEnd()
+	logger.Component("chat").Debugf("Editing message %d", node.ID)
+	return m
+}
+
+// cancelEdit leaves edit mode and restores the composer draft
+func (m Model) cancelEdit() Model {
+	if !m.isEditing() {
+		return m
+	}
+
+	m.editingID = -1
+	m.editAttachments = nil
+	m.input.SetValue(m.editDraft)
+	m.input.CursorEnd()
+	m.editDraft = ""
+	logger.Component("chat").Debug("Edit cancelled")
+	return m
+}
+
+// resendEdited sends msg as a new branch next to the message being
+// edited. The original message and everything after it stay reachable
+// from the branch navigator.
+func (m Model) resendEdited(msg chat.Message) Model {
+	node := m.messages.Branch(m.editingID, msg)
+	if node == nil {
+		// The edited message is gone; fall back to a plain send
+		m.messages.Append(msg)
+	}
+	m.currentDiagram = latestDiagram(m.messages.Messages())
+
+	logger.Component("chat").Infof("Resent edited message %d as a new branch", m.editingID)
+	m.editingID = -1
+	m.editDraft = ""
+	m.editAttachments = nil
+	return m
+}
+
+// latestDiagram returns the most recent diagram in messages, or "" if there is none
+func latestDiagram(messages []chat.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].HasDiagram() {
+			return messages[i].Diagram
+		}
+	}
+	return ""
+}
+
+// pathIndex returns the position of the node with the given ID in path, or len(path)
+func pathIndex(path []*chat.Node, id int) int {
+	for i, n := range path {
+		if n.ID == id {
+			return i
+		}
+	}
+	return len(path)
+}
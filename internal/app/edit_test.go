@@ -0,0 +1,202 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/attach"
+	"github.com/mnesler/hauk-tui/internal/chat"
+)
+
+// newConversationModel returns a model holding a two-turn conversation
+func newConversationModel() Model {
+	m := NewModel()
+	m.width = 100
+	m.height = 50
+
+	first := chat.NewMessage(chat.RoleAgent, "first answer")
+	first.Diagram = "graph TD\n    A --> B"
+	second := chat.NewMessage(chat.RoleAgent, "second answer")
+	second.Diagram = "graph LR\n    A --> B"
+
+	m.messages.Append(chat.NewMessage(chat.RoleUser, "draw it"))
+	m.messages.Append(first)
+	m.messages.Append(chat.NewMessage(chat.RoleUser, "now make it left-to-right"))
+	m.messages.Append(second)
+	m.currentDiagram = second.Diagram
+	return m
+}
+
+func TestUpdate_EditSelect(t *testing.T) {
+	m := newConversationModel()
+	m.input.SetValue("draft")
+	path := m.messages.Path()
+
+	press := func(k tea.KeyType) {
+		newModel, _ := m.Update(tea.KeyMsg{Type: k})
+		m = newModel.(Model)
+	}
+
+	press(tea.KeyShiftUp)
+	if m.editingID != path[2].ID {
+		t.Errorf("After Shift+Up, editingID = %d, want %d", m.editingID, path[2].ID)
+	}
+	if m.input.Value() != "now make it left-to-right" {
+		t.Errorf("After Shift+Up, input = %q, want last user message", m.input.Value())
+	}
+
+	press(tea.KeyShiftUp)
+	press(tea.KeyShiftUp) // No older user message
+	if m.editingID != path[0].ID {
+		t.Errorf("After Shift+Up x3, editingID = %d, want %d", m.editingID, path[0].ID)
+	}
+
+	press(tea.KeyShiftDown)
+	press(tea.KeyShiftDown)
+	if m.isEditing() {
+		t.Error("After Shift+Down past newest, should not be editing")
+	}
+	if m.input.Value() != "draft" {
+		t.Errorf("After cancelling, input = %q, want draft restored", m.input.Value())
+	}
+}
+
+func TestUpdate_EditCancelWithEsc(t *testing.T) {
+	m := newConversationModel()
+	m = m.editPrev()
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+
+	if m.isEditing() {
+		t.Error("After Esc, should not be editing")
+	}
+	if cmd != nil {
+		t.Error("Esc while editing should not quit")
+	}
+}
+
+func TestUpdate_EditResend(t *testing.T) {
+	m := newConversationModel()
+	original := m.messages.Path()
+	m = m.editPrev().editPrev()
+	m.input.SetValue("draw it as a sequence diagram")
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+
+	if m.isEditing() {
+		t.Error("After resend, should not be editing")
+	}
+
+	messages := m.messages.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("After resend, active branch length = %d, want 1", len(messages))
+	}
+	if messages[0].Content != "draw it as a sequence diagram" {
+		t.Errorf("Message content = %q, want edited prompt", messages[0].Content)
+	}
+	if m.currentDiagram != "" {
+		t.Errorf("currentDiagram = %q, want none on the new branch", m.currentDiagram)
+	}
+
+	// The original conversation is kept as a sibling branch
+	if index, count := m.messages.Siblings(m.messages.Last().ID); index != 1 || count != 2 {
+		t.Errorf("Siblings() = %d, %d, want 1, 2", index, count)
+	}
+	if !m.messages.Activate(original[3].ID) || m.messages.Len() != 4 {
+		t.Error("Original branch should still be reachable")
+	}
+}
+
+func TestLatestDiagram(t *testing.T) {
+	m := newConversationModel()
+	messages := m.messages.Messages()
+
+	if got := latestDiagram(messages); got != "graph LR\n    A --> B" {
+		t.Errorf("latestDiagram() = %q, want last agent diagram", got)
+	}
+	if got := latestDiagram(messages[:2]); got != "graph TD\n    A --> B" {
+		t.Errorf("latestDiagram(prefix) = %q, want first agent diagram", got)
+	}
+	if got := latestDiagram(nil); got != "" {
+		t.Errorf("latestDiagram(nil) = %q, want empty", got)
+	}
+}
+
+func TestUpdate_EditKeptAttachmentsCountTowardsLimit(t *testing.T) {
+	t.Chdir(t.TempDir())
+	big := strings.Repeat("x", attach.MaxFileSize)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		if err := os.WriteFile(name, []byte(big), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewModel()
+	sent := chat.NewMessage(chat.RoleUser, "compare these")
+	sent.Attachments = []chat.Attachment{{Path: "old.txt", Content: big}}
+	m.messages.Append(sent)
+	m.messages.Append(chat.NewMessage(chat.RoleAgent, "done"))
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyShiftUp})
+	m.input.SetValue("compare these with @a.txt and @b.txt")
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.isEditing() || m.messages.Len() != 2 {
+		t.Error("a resend over the total size limit should be refused")
+	}
+}
+
+func TestUpdate_EditKeepsAttachments(t *testing.T) {
+	m := NewModel()
+	m.width = 100
+	sent := chat.NewMessage(chat.RoleUser, "diagram this schema")
+	sent.Attachments = []chat.Attachment{{Path: "schema.sql", Content: "CREATE TABLE users (id int);"}}
+	m.messages.Append(sent)
+	m.messages.Append(chat.NewMessage(chat.RoleAgent, "here it is"))
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyShiftUp})
+	if preview := m.renderAttachmentPreview(); !strings.Contains(preview, "schema.sql") || !strings.Contains(preview, "as sent") {
+		t.Errorf("Preview while editing = %q, want the original attachment", preview)
+	}
+
+	m.input.SetValue("diagram this schema left-to-right")
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+
+	resent := m.messages.Last().Message
+	if resent.Content != "diagram this schema left-to-right" {
+		t.Fatalf("Last message = %q, want the edited one", resent.Content)
+	}
+	if len(resent.Attachments) != 1 || resent.Attachments[0] != sent.Attachments[0] {
+		t.Errorf("Resent attachments = %+v, want the original's", resent.Attachments)
+	}
+	if m.editAttachments != nil {
+		t.Error("editAttachments should be cleared after resending")
+	}
+}
+
+func TestUpdate_EditEndedBySlashCommand(t *testing.T) {
+	m := NewModel()
+	sent := chat.NewMessage(chat.RoleUser, "diagram this schema")
+	sent.Attachments = []chat.Attachment{{Path: "schema.sql", Content: "CREATE TABLE users (id int);"}}
+	m.messages.Append(sent)
+	m.messages.Append(chat.NewMessage(chat.RoleAgent, "here it is"))
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyShiftUp})
+	m, _ = send(m, "/theme")
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.isEditing() || m.editDraft != "" || m.editAttachments != nil {
+		t.Fatalf("A slash command should end the edit, got editingID %d, draft %q, attachments %v",
+			m.editingID, m.editDraft, m.editAttachments)
+	}
+	if m.input.Value() != "" {
+		t.Errorf("Input = %q, want it cleared after the command", m.input.Value())
+	}
+
+	m, _ = send(m, "something else")
+	if got := m.messages.LastWithRole(chat.RoleUser).Message; got.Content != "something else" || len(got.Attachments) != 0 {
+		t.Errorf("Next message = %q with %v, want no attachments carried over", got.Content, got.Attachments)
+	}
+}
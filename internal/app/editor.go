@@ -0,0 +1,110 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/diagram"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// runEditor suspends the TUI while the editor runs; tests replace it
+var runEditor = tea.ExecProcess
+
+// DiagramEditedMsg reports that the external editor exited
+type DiagramEditedMsg struct {
+	Path     string // Temp file holding the diagram
+	Original string // Source before editing
+	Err      error
+}
+
+// editorCommand returns the user's editor, from $VISUAL or $EDITOR,
+// falling back to vi. The variable may hold arguments, e.g. "code --wait".
+func editorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"vi"}
+}
+
+// handleEditCommand runs /edit: the current diagram is written to a temp
+// file and opened in the user's editor
+func (m Model) handleEditCommand() tea.Cmd {
+	if m.currentDiagram == "" {
+		logger.Component("editor").Info("No diagram to edit yet")
+		return nil
+	}
+
+	f, err := os.CreateTemp("", "hauk-*.mmd")
+	if err != nil {
+		logger.Component("editor").Errorf("Failed to create temp file: %v", err)
+		return nil
+	}
+	path := f.Name()
+	_, err = f.WriteString(m.currentDiagram + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		logger.Component("editor").Errorf("Failed to write temp file: %v", err)
+		return nil
+	}
+
+	editor := editorCommand()
+	logger.Component("editor").Infof("Opening diagram in %s", editor[0])
+
+	original := m.currentDiagram
+	cmd := exec.Command(editor[0], append(editor[1:], path)...)
+	return runEditor(cmd, func(err error) tea.Msg {
+		return DiagramEditedMsg{Path: path, Original: original, Err: err}
+	})
+}
+
+// loadEditedDiagram reads back the edited temp file and records the result
+// as a new diagram in the conversation, so the agent sees the edit
+func (m Model) loadEditedDiagram(msg DiagramEditedMsg) Model {
+	defer os.Remove(msg.Path)
+
+	if msg.Err != nil {
+		logger.Component("editor").Errorf("Editor failed: %v", msg.Err)
+		return m
+	}
+
+	data, err := os.ReadFile(msg.Path)
+	if err != nil {
+		logger.Component("editor").Errorf("Failed to read edited diagram: %v", err)
+		return m
+	}
+	return m.recordEditedDiagram(strings.TrimRight(string(data), "\r\n"), msg.Original)
+}
+
+// editedDiagramTitle introduces hand-edited diagrams in the conversation
+const editedDiagramTitle = "Edited diagram"
+
+// recordEditedDiagram adds a hand-edited diagram to the conversation as the
+// current one, logging any problems in it. Empty or unchanged source is
+// ignored.
+func (m Model) recordEditedDiagram(source, original string) Model {
+	if strings.TrimSpace(source) == "" {
+		logger.Component("editor").Warn("Edited diagram is empty, keeping the original")
+		return m
+	}
+	if source == original {
+		logger.Component("editor").Info("Diagram unchanged")
+		return m
+	}
+
+	for _, d := range diagram.Validate(source) {
+		logger.Component("editor").Warnf("Edited diagram: %s", d)
+	}
+
+	return m.loadGeneratedDiagram(DiagramGeneratedMsg{
+		Title:  editedDiagramTitle,
+		Source: source,
+	})
+}
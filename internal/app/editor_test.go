@@ -0,0 +1,105 @@
+package app
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// stubEditor runs the editor synchronously instead of suspending the TUI
+func stubEditor(t *testing.T, editor string) {
+	t.Helper()
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+	original := runEditor
+	runEditor = func(c *exec.Cmd, fn tea.ExecCallback) tea.Cmd {
+		err := c.Run()
+		return func() tea.Msg { return fn(err) }
+	}
+	t.Cleanup(func() { runEditor = original })
+}
+
+func TestEditCommand(t *testing.T) {
+	tests := []struct {
+		name    string
+		editor  string
+		want    string // Current diagram afterwards
+		newMsgs int
+	}{
+		{
+			name:    "edit is recorded",
+			editor:  "sed -i s/B/C/",
+			want:    "graph TD\n    A --> C",
+			newMsgs: 1,
+		},
+		{
+			name:   "unchanged",
+			editor: "true",
+			want:   "graph TD\n    A --> B",
+		},
+		{
+			name:   "emptied file keeps the original",
+			editor: "sed -i d",
+			want:   "graph TD\n    A --> B",
+		},
+		{
+			name:   "editor fails",
+			editor: "false",
+			want:   "graph TD\n    A --> B",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubEditor(t, tt.editor)
+			m := NewModel()
+			m = m.loadGeneratedDiagram(DiagramGeneratedMsg{Title: "Start", Source: "graph TD\n    A --> B"})
+			before := m.messages.Len()
+
+			m, cmd := send(m, "/edit")
+			if cmd == nil {
+				t.Fatal("no editor command")
+			}
+			msg := cmd().(DiagramEditedMsg)
+			updated, _ := m.Update(msg)
+			m = updated.(Model)
+
+			if m.currentDiagram != tt.want {
+				t.Errorf("diagram = %q, want %q", m.currentDiagram, tt.want)
+			}
+			if got := m.messages.Len() - before; got != tt.newMsgs {
+				t.Errorf("%d messages added, want %d", got, tt.newMsgs)
+			}
+			if _, err := os.Stat(msg.Path); !os.IsNotExist(err) {
+				t.Errorf("temp file %s not removed", msg.Path)
+			}
+		})
+	}
+}
+
+func TestEditCommand_NoDiagram(t *testing.T) {
+	stubEditor(t, "true")
+	if _, cmd := send(NewModel(), "/edit"); cmd != nil {
+		t.Error("expected no command without a diagram")
+	}
+}
+
+func TestEditorCommand(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "code --wait")
+	if got := strings.Join(editorCommand(), " "); got != "code --wait" {
+		t.Errorf("editorCommand() = %q", got)
+	}
+	t.Setenv("VISUAL", "nvim")
+	if got := strings.Join(editorCommand(), " "); got != "nvim" {
+		t.Errorf("editorCommand() = %q, want $VISUAL first", got)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+	if got := strings.Join(editorCommand(), " "); got != "vi" {
+		t.Errorf("editorCommand() = %q, want vi fallback", got)
+	}
+}
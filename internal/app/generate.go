@@ -0,0 +1,36 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// DiagramGeneratedMsg carries a diagram built from local sources, like code
+// or config files, rather than by the agent
+type DiagramGeneratedMsg struct {
+	Title  string // What was generated, e.g. "Package graph of ./internal"
+	Source string // Mermaid source
+	Err    error
+}
+
+// loadGeneratedDiagram makes a generated diagram current and adds it to the
+// conversation so the agent can refine it
+func (m Model) loadGeneratedDiagram(msg DiagramGeneratedMsg) Model {
+	if msg.Err != nil {
+		logger.Component("generate").Errorf("Failed to generate %s: %v", msg.Title, msg.Err)
+		return m
+	}
+
+	content := fmt.Sprintf("%s:\n\n```mermaid\n%s\n```", msg.Title, msg.Source)
+	generated := chat.NewMessage(chat.RoleSystem, content)
+	generated.Diagram = msg.Source
+
+	m.messages.Append(generated)
+	m.currentDiagram = msg.Source
+	m.chatViewport.GotoBottom()
+
+	logger.Component("generate").Infof("Loaded %s", msg.Title)
+	return m
+}
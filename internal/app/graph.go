@@ -0,0 +1,100 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/gograph"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// graphUsage lists the /graph diagram kinds
+const graphUsage = "Usage: /graph packages [dir] | types [dir] | func <name> [dir]"
+
+// handleGraphCommand runs /graph <kind> [args], generating a diagram from code
+func (m Model) handleGraphCommand(args []string) tea.Cmd {
+	if len(args) == 0 {
+		logger.Component("generate").Warn(graphUsage)
+		return nil
+	}
+
+	switch args[0] {
+	case "packages":
+		dir := argOr(args, 1, ".")
+		logger.Component("generate").Infof("Scanning Go packages under %s", dir)
+		return graphPackages(dir)
+
+	case "types":
+		dir := argOr(args, 1, ".")
+		logger.Component("generate").Infof("Type-checking the Go package in %s", dir)
+		return graphTypes(dir)
+
+	case "func":
+		if len(args) < 2 {
+			logger.Component("generate").Warn(graphUsage)
+			return nil
+		}
+		dir := argOr(args, 2, ".")
+		logger.Component("generate").Infof("Looking for %s under %s", args[1], dir)
+		return graphFunc(dir, args[1])
+	}
+
+	logger.Component("generate").Warnf("Unknown graph kind %q. %s", args[0], graphUsage)
+	return nil
+}
+
+// argOr returns args[i], or def when there are fewer arguments
+func argOr(args []string, i int, def string) string {
+	if len(args) > i {
+		return args[i]
+	}
+	return def
+}
+
+// graphPackages builds the import graph of the Go packages under dir off the update loop
+func graphPackages(dir string) tea.Cmd {
+	return func() tea.Msg {
+		g, err := gograph.Packages(dir)
+		if err != nil {
+			return DiagramGeneratedMsg{Title: "package graph of " + dir, Err: err}
+		}
+		for _, err := range g.Skipped {
+			logger.Component("generate").Warnf("%v", err)
+		}
+		return DiagramGeneratedMsg{
+			Title:  fmt.Sprintf("Package graph of %s (%d packages)", dir, len(g.Packages)),
+			Source: g.Mermaid(),
+		}
+	}
+}
+
+// graphTypes builds a class diagram of the Go package in dir off the update loop
+func graphTypes(dir string) tea.Cmd {
+	return func() tea.Msg {
+		g, err := gograph.Types(dir)
+		if err != nil {
+			return DiagramGeneratedMsg{Title: "type diagram of " + dir, Err: err}
+		}
+		return DiagramGeneratedMsg{
+			Title:  fmt.Sprintf("Types of package %s in %s (%d types)", g.Package, dir, len(g.Types)),
+			Source: g.Mermaid(),
+		}
+	}
+}
+
+// graphFunc builds a flowchart of one function's control flow off the update loop
+func graphFunc(dir, name string) tea.Cmd {
+	return func() tea.Msg {
+		g, err := gograph.FuncFlow(dir, name)
+		if err != nil {
+			return DiagramGeneratedMsg{Title: "control flow of " + name, Err: err}
+		}
+		for _, err := range g.Skipped {
+			logger.Component("generate").Warnf("%v", err)
+		}
+		return DiagramGeneratedMsg{
+			Title:  fmt.Sprintf("Control flow of %s (%s)", g.Name, g.Position),
+			Source: g.Mermaid(),
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mnesler/hauk-tui/internal/chat"
+)
+
+func TestUpdate_GraphPackages(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod":          "module example.com/demo\n",
+		"main.go":         "package main\n\nimport \"example.com/demo/store\"\n",
+		"store/store.go":  "package store\n",
+		"store/store2.go": "package store\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewModel()
+	m, cmd := send(m, "/graph packages "+dir)
+	if cmd == nil {
+		t.Fatal("/graph packages should produce a command")
+	}
+
+	msg, ok := cmd().(DiagramGeneratedMsg)
+	if !ok || msg.Err != nil {
+		t.Fatalf("command produced %#v, want a generated diagram", msg)
+	}
+
+	newModel, _ := m.Update(msg)
+	m = newModel.(Model)
+
+	if !strings.Contains(m.currentDiagram, "node --> store") {
+		t.Errorf("currentDiagram = %q, want the package graph", m.currentDiagram)
+	}
+	last := m.messages.Last()
+	if last == nil || last.Message.Role != chat.RoleSystem || last.Message.Diagram != m.currentDiagram {
+		t.Fatalf("Last message = %+v, want the generated diagram", last)
+	}
+	if !strings.Contains(last.Message.Content, "```mermaid") {
+		t.Error("Generated message should carry the mermaid source for the agent")
+	}
+	if !strings.Contains(m.renderMessage(last, false), "Hauk:") {
+		t.Error("Generated messages should be labelled as coming from hauk")
+	}
+}
+
+func TestUpdate_GraphErrors(t *testing.T) {
+	for _, input := range []string{"/graph", "/graph bogus"} {
+		if _, cmd := send(NewModel(), input); cmd != nil {
+			t.Errorf("%s should only log usage", input)
+		}
+	}
+
+	m := NewModel()
+	newModel, _ := m.Update(DiagramGeneratedMsg{Title: "package graph", Err: errors.New("no go.mod")})
+	if newModel.(Model).messages.Len() != 0 {
+		t.Error("A failed generation should not add a message")
+	}
+}
+
+func TestUpdate_GraphTypes(t *testing.T) {
+	dir := t.TempDir()
+	source := "package store\n\ntype Store interface{ Get(id int) string }\n\ntype memory struct{ items map[int]string }\n\nfunc (m *memory) Get(id int) string { return m.items[id] }\n"
+	if err := os.WriteFile(filepath.Join(dir, "store.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, cmd := send(NewModel(), "/graph types "+dir)
+	if cmd == nil {
+		t.Fatal("/graph types should produce a command")
+	}
+	newModel, _ := m.Update(cmd())
+	m = newModel.(Model)
+
+	if !strings.HasPrefix(m.currentDiagram, "classDiagram") || !strings.Contains(m.currentDiagram, "Store <|.. memory") {
+		t.Errorf("currentDiagram = %q, want a class diagram with the implementation", m.currentDiagram)
+	}
+}
+
+func TestUpdate_GraphFunc(t *testing.T) {
+	dir := t.TempDir()
+	source := "package store\n\nfunc Get(id int) string {\n\tif id < 0 {\n\t\treturn \"\"\n\t}\n\treturn \"item\"\n}\n"
+	if err := os.WriteFile(filepath.Join(dir, "store.go"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, cmd := send(NewModel(), "/graph func"); cmd != nil {
+		t.Error("/graph func without a name should only log usage")
+	}
+
+	m, cmd := send(NewModel(), "/graph func store.Get "+dir)
+	if cmd == nil {
+		t.Fatal("/graph func should produce a command")
+	}
+	newModel, _ := m.Update(cmd())
+	m = newModel.(Model)
+
+	if !strings.Contains(m.currentDiagram, `{"id < 0"}`) {
+		t.Errorf("currentDiagram = %q, want the control flow of Get", m.currentDiagram)
+	}
+	if last := m.messages.Last(); last == nil || !strings.Contains(last.Message.Content, "store.Get") {
+		t.Errorf("Last message = %+v, want it titled with the function", last)
+	}
+}
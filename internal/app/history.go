@@ -0,0 +1,184 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/logger"
+	"github.com/mnesler/hauk-tui/internal/ui"
+)
+
+// recordPrompt adds submitted input to the prompt history and persists it
+func (m Model) recordPrompt(content string) Model {
+	m.history.Add(content)
+	m.historyIndex = m.history.Len()
+	m.historyDraft = ""
+
+	if err := m.history.Save(); err != nil {
+		logger.Component("history").Warnf("Failed to save history: %v", err)
+	}
+
+	return m
+}
+
+// recallsHistory reports whether Up and Down should recall prompts rather
+// than scroll the chat: the composer must be focused and either empty or
+// already showing a recalled prompt
+func (m Model) recallsHistory() bool {
+	return m.input.Focused() && (m.input.Value() == "" || m.historyIndex < m.history.Len())
+}
+
+// historyPrev recalls the previous (older) prompt into the composer
+func (m Model) historyPrev() Model {
+	if m.historyIndex <= 0 {
+		return m
+	}
+
+	// Keep whatever was being typed so Down can restore it
+	if m.historyIndex >= m.history.Len() {
+		m.historyDraft = m.input.Value()
+	}
+
+	m.historyIndex--
+	m.input.SetValue(m.history.Get(m.historyIndex))
+	m.input.CursorEnd()
+	return m
+}
+
+// historyNext recalls the next (newer) prompt, or the draft once past the newest
+func (m Model) historyNext() Model {
+	if m.historyIndex >= m.history.Len() {
+		return m
+	}
+
+	m.historyIndex++
+	if m.historyIndex == m.history.Len() {
+		m.input.SetValue(m.historyDraft)
+	} else {
+		m.input.SetValue(m.history.Get(m.historyIndex))
+	}
+	m.input.CursorEnd()
+	return m
+}
+
+// openHistorySearch starts a reverse-i-search over the prompt history
+func (m Model) openHistorySearch() Model {
+	m.showHistorySearch = true
+	m.historyQuery = ""
+	m.historyMatch = -1
+	m.historySessionOnly = false
+	m.input.Blur()
+	logger.Component("history").Debug("Reverse search opened")
+	return m
+}
+
+// closeHistorySearch hides the search overlay and returns focus to the composer
+func (m Model) closeHistorySearch() Model {
+	m.showHistorySearch = false
+	m.input.Focus()
+	return m
+}
+
+// updateHistorySearch handles input when the reverse search overlay is active
+func (m Model) updateHistorySearch(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC, tea.KeyCtrlG:
+		// Cancel without touching the composer
+		return m.closeHistorySearch(), nil
+
+	case tea.KeyEnter:
+		// Accept the match into the composer for editing
+		if m.historyMatch >= 0 {
+			m.input.SetValue(m.history.Get(m.historyMatch))
+			m.input.CursorEnd()
+			m.historyIndex = m.historyMatch
+		}
+		return m.closeHistorySearch(), nil
+
+	case tea.KeyCtrlR:
+		// Jump to the next older match
+		before := m.history.Len()
+		if m.historyMatch >= 0 {
+			before = m.historyMatch
+		}
+		if match := m.searchHistory(before); match >= 0 {
+			m.historyMatch = match
+		}
+		return m, nil
+
+	case tea.KeyTab:
+		// Switch between all prompts and this session's
+		m.historySessionOnly = !m.historySessionOnly
+		m.historyMatch = m.searchHistory(m.history.Len())
+		return m, nil
+
+	case tea.KeyBackspace:
+		if len(m.historyQuery) > 0 {
+			runes := []rune(m.historyQuery)
+			m.historyQuery = string(runes[:len(runes)-1])
+		}
+		m.historyMatch = m.searchHistory(m.history.Len())
+		return m, nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.historyQuery += string(msg.Runes)
+		m.historyMatch = m.searchHistory(m.history.Len())
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// searchHistory finds the newest match for the query before index before,
+// within this session's prompts when the search is limited to them
+func (m Model) searchHistory(before int) int {
+	match := m.history.Search(m.historyQuery, before)
+	if m.historySessionOnly && match < m.history.SessionStart() {
+		return -1
+	}
+	return match
+}
+
+// renderHistorySearch renders the reverse search overlay
+func (m Model) renderHistorySearch() string {
+	modalWidth := m.width * 2 / 3
+	if modalWidth < 40 {
+		modalWidth = 40
+	}
+
+	scope := "reverse-i-search"
+	if m.historySessionOnly {
+		scope = "session reverse-i-search"
+	}
+	prompt := ui.GetTextSecondaryStyle().
+		Render(fmt.Sprintf("(%s)`%s':", scope, m.historyQuery))
+
+	var match string
+	switch {
+	case m.historyMatch >= 0:
+		match = m.history.Get(m.historyMatch)
+	case m.historyQuery != "":
+		match = ui.GetTextMutedStyle(ui.ActiveTheme.ChatBg).Render("no match")
+	}
+
+	instructions := ui.GetTextMutedStyle(ui.ActiveTheme.ChatBg).
+		Render("Ctrl+R: older match • Tab: this session/all • Enter: use • Esc: cancel")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		prompt+" "+match,
+		"",
+		instructions,
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Background(ui.ActiveTheme.ChatBg).
+		Foreground(ui.ActiveTheme.TextPrimary).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ActiveTheme.AccentUser).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	return modalStyle.Render(content)
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/history"
+)
+
+// newHistoryModel returns a model with a fresh in-memory prompt history
+func newHistoryModel(prompts ...string) Model {
+	m := NewModel()
+	m.history = history.New()
+	for _, p := range prompts {
+		m.history.Add(p)
+	}
+	m.historyIndex = m.history.Len()
+	return m
+}
+
+func TestUpdate_HistoryRecall(t *testing.T) {
+	m := newHistoryModel("first", "second")
+
+	press := func(k tea.KeyType) {
+		newModel, _ := m.Update(tea.KeyMsg{Type: k})
+		m = newModel.(Model)
+	}
+
+	press(tea.KeyUp)
+	if m.input.Value() != "second" {
+		t.Errorf("After Up, input = %q, want %q", m.input.Value(), "second")
+	}
+
+	press(tea.KeyUp)
+	press(tea.KeyUp) // Already at the oldest entry
+	if m.input.Value() != "first" {
+		t.Errorf("After Up x3, input = %q, want %q", m.input.Value(), "first")
+	}
+
+	press(tea.KeyDown)
+	press(tea.KeyDown)
+	if m.input.Value() != "" {
+		t.Errorf("After Down past newest, input = %q, want it empty again", m.input.Value())
+	}
+}
+
+func TestUpdate_HistoryRecallOnlyFromEmptyComposer(t *testing.T) {
+	m := newHistoryModel("first")
+	m.input.SetValue("draft")
+
+	// With a draft in the composer, Up and Down scroll the chat
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyUp})
+	if m.input.Value() != "draft" || m.historyIndex != m.history.Len() {
+		t.Errorf("Up with a draft recalled %q, want the draft kept", m.input.Value())
+	}
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyDown})
+	if m.input.Value() != "draft" {
+		t.Errorf("Down with a draft changed it to %q", m.input.Value())
+	}
+}
+
+func TestUpdate_SendRecordsHistory(t *testing.T) {
+	m := newHistoryModel()
+	m.input.SetValue("now make it left-to-right")
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+
+	if m.history.Len() != 1 || m.history.Get(0) != "now make it left-to-right" {
+		t.Errorf("History entries = %q, want the sent prompt", m.history.Entries())
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m = newModel.(Model)
+	if m.input.Value() != "now make it left-to-right" {
+		t.Errorf("After Up, input = %q, want sent prompt", m.input.Value())
+	}
+}
+
+func TestUpdate_HistorySearch(t *testing.T) {
+	m := newHistoryModel("draw a flowchart", "now make it left-to-right", "add a cache")
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = newModel.(Model)
+	if !m.showHistorySearch {
+		t.Fatal("After Ctrl+R, showHistorySearch should be true")
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("left")})
+	m = newModel.(Model)
+	if m.historyMatch != 1 {
+		t.Errorf("historyMatch = %d, want 1", m.historyMatch)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if m.showHistorySearch {
+		t.Error("After Enter, showHistorySearch should be false")
+	}
+	if m.input.Value() != "now make it left-to-right" {
+		t.Errorf("After Enter, input = %q, want matched prompt", m.input.Value())
+	}
+	if !m.input.Focused() {
+		t.Error("After Enter, input should be focused")
+	}
+}
+
+func TestUpdate_HistorySearch_Cancel(t *testing.T) {
+	m := newHistoryModel("draw a flowchart")
+	m.input.SetValue("typing")
+	m = m.openHistorySearch()
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("draw")})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(Model)
+
+	if m.showHistorySearch {
+		t.Error("After Esc, showHistorySearch should be false")
+	}
+	if m.input.Value() != "typing" {
+		t.Errorf("After Esc, input = %q, want %q", m.input.Value(), "typing")
+	}
+}
+
+func TestUpdate_HistorySearchKeepsResponses(t *testing.T) {
+	m := newHistoryModel("draw a flowchart")
+	m = m.openHistorySearch()
+
+	newModel, _ := m.Update(AgentResponseMsg{Content: "late answer"})
+	m = newModel.(Model)
+	newModel, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 50})
+	m = newModel.(Model)
+
+	if m.messages.Len() != 1 || m.messages.Last().Message.Content != "late answer" {
+		t.Error("Responses arriving during history search should still be added")
+	}
+	if m.width != 100 {
+		t.Errorf("width = %d after a resize during history search, want 100", m.width)
+	}
+	if !m.showHistorySearch {
+		t.Error("History search should stay open")
+	}
+}
+
+func TestUpdate_HistorySearchSessionOnly(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	previous := history.New()
+	previous.Add("make it left-to-right")
+	if err := previous.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	m := NewModel()
+	m.history, _ = history.Load()
+	m.history.Add("make it bigger")
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyCtrlR})
+	m, _ = press(m, keys("left")...)
+	if m.historyMatch != 0 {
+		t.Fatalf("historyMatch = %d, want the previous session's prompt", m.historyMatch)
+	}
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	if !m.historySessionOnly || m.historyMatch != -1 {
+		t.Errorf("After Tab, session only = %v, historyMatch = %d, want true, -1", m.historySessionOnly, m.historyMatch)
+	}
+	if !strings.Contains(m.renderHistorySearch(), "session reverse-i-search") {
+		t.Error("The overlay should say the search is limited to the session")
+	}
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	if m.historySessionOnly || m.historyMatch != 0 {
+		t.Errorf("After a second Tab, session only = %v, historyMatch = %d, want false, 0", m.historySessionOnly, m.historyMatch)
+	}
+}
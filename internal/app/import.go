@@ -0,0 +1,145 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/importer"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// importUsage lists the /import sources
+const importUsage = "Usage: /import sql <file> | git [branch...] | openapi <file> [operation...] | k8s <dir> | compose <file>"
+
+// handleImportCommand runs /import <kind> [args], generating a diagram from
+// a schema, manifest or repository
+func (m Model) handleImportCommand(args []string) tea.Cmd {
+	if len(args) == 0 {
+		logger.Component("generate").Warn(importUsage)
+		return nil
+	}
+
+	switch args[0] {
+	case "sql":
+		if len(args) < 2 {
+			logger.Component("generate").Warn(importUsage)
+			return nil
+		}
+		logger.Component("generate").Infof("Reading SQL schema from %s", args[1])
+		return importSQL(args[1])
+
+	case "openapi":
+		if len(args) < 2 {
+			logger.Component("generate").Warn(importUsage)
+			return nil
+		}
+		logger.Component("generate").Infof("Reading OpenAPI document %s", args[1])
+		return importOpenAPI(args[1], args[2:])
+
+	case "k8s":
+		if len(args) < 2 {
+			logger.Component("generate").Warn(importUsage)
+			return nil
+		}
+		logger.Component("generate").Infof("Reading Kubernetes manifests under %s", args[1])
+		return importK8s(args[1])
+
+	case "compose":
+		if len(args) < 2 {
+			logger.Component("generate").Warn(importUsage)
+			return nil
+		}
+		logger.Component("generate").Infof("Reading compose file %s", args[1])
+		return importCompose(args[1])
+
+	case "git":
+		logger.Component("generate").Info("Reading git history")
+		return importGit(".", args[1:])
+	}
+
+	logger.Component("generate").Warnf("Unknown import kind %q. %s", args[0], importUsage)
+	return nil
+}
+
+// importSQL builds an ER diagram from a DDL file off the update loop
+func importSQL(path string) tea.Cmd {
+	return func() tea.Msg {
+		s, err := importer.LoadSQL(path)
+		if err != nil {
+			return DiagramGeneratedMsg{Title: "ER diagram of " + path, Err: err}
+		}
+		return DiagramGeneratedMsg{
+			Title:  fmt.Sprintf("ER diagram of %s (%d tables, %d foreign keys)", path, len(s.Tables), len(s.ForeignKeys)),
+			Source: s.Mermaid(),
+		}
+	}
+}
+
+// importGit builds a gitGraph of the repository in dir off the update loop
+func importGit(dir string, branches []string) tea.Cmd {
+	return func() tea.Msg {
+		h, err := importer.GitLog(dir, branches)
+		if err != nil {
+			return DiagramGeneratedMsg{Title: "git history", Err: err}
+		}
+		return DiagramGeneratedMsg{
+			Title:  fmt.Sprintf("Git history (%d commits on %d branches)", len(h.Commits), len(h.Branches)),
+			Source: h.Mermaid(),
+		}
+	}
+}
+
+// importOpenAPI builds sequence diagrams of the selected operations, or an
+// endpoint overview, from an OpenAPI document off the update loop
+func importOpenAPI(path string, operations []string) tea.Cmd {
+	return func() tea.Msg {
+		api, err := importer.LoadOpenAPI(path)
+		if err != nil {
+			return DiagramGeneratedMsg{Title: "API diagram of " + path, Err: err}
+		}
+		source, err := api.Diagram(operations)
+		if err != nil {
+			return DiagramGeneratedMsg{Title: "API diagram of " + path, Err: err}
+		}
+
+		title := fmt.Sprintf("Endpoints of %s (%d operations)", api.Title, len(api.Operations))
+		if len(operations) > 0 {
+			title = fmt.Sprintf("Sequence of %s in %s", strings.Join(operations, ", "), api.Title)
+		}
+		return DiagramGeneratedMsg{Title: title, Source: source}
+	}
+}
+
+// importK8s builds a topology of the Kubernetes manifests under path off
+// the update loop
+func importK8s(path string) tea.Cmd {
+	return func() tea.Msg {
+		t, err := importer.LoadManifests(path)
+		if err != nil {
+			return DiagramGeneratedMsg{Title: "topology of " + path, Err: err}
+		}
+		for _, skipped := range t.Skipped {
+			logger.Component("generate").Warnf("Skipped %s: not valid YAML", skipped)
+		}
+		return DiagramGeneratedMsg{
+			Title:  fmt.Sprintf("Kubernetes topology of %s (%d resources)", path, len(t.Resources)),
+			Source: t.Mermaid(),
+		}
+	}
+}
+
+// importCompose builds a service topology from a docker-compose file off
+// the update loop
+func importCompose(path string) tea.Cmd {
+	return func() tea.Msg {
+		c, err := importer.LoadCompose(path)
+		if err != nil {
+			return DiagramGeneratedMsg{Title: "service topology of " + path, Err: err}
+		}
+		return DiagramGeneratedMsg{
+			Title:  fmt.Sprintf("Service topology of %s (%d services)", path, len(c.Services)),
+			Source: c.Mermaid(),
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUpdate_ImportSQL(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.sql")
+	ddl := "CREATE TABLE users (id int PRIMARY KEY);\nCREATE TABLE posts (id int PRIMARY KEY, author int NOT NULL REFERENCES users(id));\n"
+	if err := os.WriteFile(path, []byte(ddl), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, cmd := send(NewModel(), "/import sql "+path)
+	if cmd == nil {
+		t.Fatal("/import sql should produce a command")
+	}
+	newModel, _ := m.Update(cmd())
+	m = newModel.(Model)
+
+	if !strings.HasPrefix(m.currentDiagram, "erDiagram") || !strings.Contains(m.currentDiagram, `users ||--o{ posts : "author"`) {
+		t.Errorf("currentDiagram = %q, want the ER diagram", m.currentDiagram)
+	}
+	if last := m.messages.Last(); last == nil || !strings.Contains(last.Message.Content, "2 tables") {
+		t.Errorf("Last message = %+v, want the import summary", last)
+	}
+}
+
+func TestUpdate_ImportOpenAPI(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api.yaml")
+	doc := "openapi: 3.0.0\ninfo: {title: Ping}\npaths:\n  /ping:\n    get:\n      operationId: ping\n      responses:\n        \"200\": {description: pong}\n"
+	if err := os.WriteFile(path, []byte(doc), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"/import openapi " + path, `op_GET_ping["GET /ping"]`},
+		{"/import openapi " + path + " ping", "Client->>API: GET /ping"},
+	}
+	for _, tt := range tests {
+		m, cmd := send(NewModel(), tt.input)
+		if cmd == nil {
+			t.Fatalf("%s should produce a command", tt.input)
+		}
+		newModel, _ := m.Update(cmd())
+		if got := newModel.(Model).currentDiagram; !strings.Contains(got, tt.want) {
+			t.Errorf("%s: currentDiagram = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestUpdate_ImportK8s(t *testing.T) {
+	dir := t.TempDir()
+	manifests := "kind: Service\nmetadata: {name: web}\nspec:\n  selector: {app: web}\n  ports: [{port: 80}]\n---\n" +
+		"kind: Deployment\nmetadata: {name: web}\nspec:\n  template:\n    metadata: {labels: {app: web}}\n"
+	if err := os.WriteFile(filepath.Join(dir, "web.yaml"), []byte(manifests), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, cmd := send(NewModel(), "/import k8s "+dir)
+	if cmd == nil {
+		t.Fatal("/import k8s should produce a command")
+	}
+	newModel, _ := m.Update(cmd())
+	m = newModel.(Model)
+
+	if !strings.Contains(m.currentDiagram, `Service_default_web -->|"80"| Deployment_default_web`) {
+		t.Errorf("currentDiagram = %q, want the service routing to the deployment", m.currentDiagram)
+	}
+}
+
+func TestUpdate_ImportCompose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "compose.yml")
+	compose := "services:\n  web:\n    image: nginx\n    ports: [\"8080:80\"]\n    depends_on: [db]\n  db:\n    image: postgres\n"
+	if err := os.WriteFile(path, []byte(compose), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, cmd := send(NewModel(), "/import compose "+path)
+	if cmd == nil {
+		t.Fatal("/import compose should produce a command")
+	}
+	newModel, _ := m.Update(cmd())
+	m = newModel.(Model)
+
+	for _, want := range []string{`host -->|"8080→80"| svc_web`, `svc_web -->|"depends on"| svc_db`} {
+		if !strings.Contains(m.currentDiagram, want) {
+			t.Errorf("currentDiagram = %q, want %q", m.currentDiagram, want)
+		}
+	}
+}
+
+func TestUpdate_ImportErrors(t *testing.T) {
+	for _, input := range []string{"/import", "/import sql", "/import openapi", "/import k8s", "/import compose", "/import bogus file"} {
+		if _, cmd := send(NewModel(), input); cmd != nil {
+			t.Errorf("%s should only log usage", input)
+		}
+	}
+
+	_, cmd := send(NewModel(), "/import sql "+filepath.Join(t.TempDir(), "missing.sql"))
+	if msg := cmd().(DiagramGeneratedMsg); msg.Err == nil {
+		t.Error("Importing a missing file should report an error")
+	}
+
+	t.Chdir(t.TempDir())
+	_, cmd = send(NewModel(), "/import git")
+	if cmd == nil {
+		t.Fatal("/import git should produce a command")
+	}
+	if msg := cmd().(DiagramGeneratedMsg); msg.Err == nil {
+		t.Error("Importing git history outside a repository should report an error")
+	}
+}
@@ -1,13 +1,22 @@
 package app
 
 import (
+	"time"
+
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/attach"
 	"github.com/mnesler/hauk-tui/internal/chat"
 	"github.com/mnesler/hauk-tui/internal/config"
+	"github.com/mnesler/hauk-tui/internal/diagram"
+	"github.com/mnesler/hauk-tui/internal/docs"
+	"github.com/mnesler/hauk-tui/internal/editor"
+	"github.com/mnesler/hauk-tui/internal/history"
+	"github.com/mnesler/hauk-tui/internal/logger"
+	"github.com/mnesler/hauk-tui/internal/templates"
 	"github.com/mnesler/hauk-tui/internal/ui"
 )
 
@@ -30,7 +39,7 @@ type Model struct {
 	themeList    list.Model
 
 	// State
-	messages       []chat.Message
+	messages       *chat.Tree
 	currentDiagram string
 	width          int
 	height         int
@@ -41,6 +50,80 @@ type Model struct {
 	previewTheme      string
 	savedTheme        string
 
+	// Prompt history state
+	history            *history.History
+	historyIndex       int    // Position while recalling with Up/Down; history.Len() when not recalling
+	historyDraft       string // Composer text saved when recall starts
+	showHistorySearch  bool
+	historyQuery       string
+	historyMatch       int  // Index of the current search match, or -1
+	historySessionOnly bool // Search only prompts sent in this session
+
+	// Edit state
+	editingID       int               // ID of the user message node being edited, or -1
+	editDraft       string            // Composer text saved when editing starts
+	editAttachments []chat.Attachment // Files the edited message was sent with
+
+	// Last message ID when each retry was requested, by retried message ID;
+	// cleared when the conversation is replaced
+	retryTips map[int]int
+
+	// Branch navigator and session state
+	showBranches bool
+	branchCursor int    // Row selected in the branch navigator
+	sessionName  string // Name the conversation was last saved or loaded as
+
+	// Markdown diagrams offered by hauk docs and /docs
+	docsRoot   string       // Where /docs scans; set by hauk docs or /docs <dir>
+	docBlocks  []docs.Block // Blocks listed in the docs picker
+	showDocs   bool
+	docsCursor int
+	docBlock   *docs.Block // Block /docs save writes the current diagram to
+	docsNodeID int         // Message the block was loaded into the conversation as
+
+	// Diagram pane, shown in place of the logs, and the file hauk watch follows
+	showDiagram  bool
+	watchPath    string
+	watchModTime time.Time
+	watchErr     error // Last failure to read the watched file, shown in the pane
+
+	// Built-in source editor in the diagram pane, nil when closed
+	sourceEditor         *editor.Buffer
+	editorOriginal       string // Diagram the editor was opened on
+	editorTop            int    // First source line shown
+	editorLeft           int    // First source column shown
+	editorConfirmDiscard bool   // Esc was pressed once with unapplied edits
+
+	// Node picker over the current diagram's elements
+	picking           bool
+	pickElements      []diagram.Element
+	pickCursor        int
+	pickShowedDiagram bool // Diagram pane state to restore afterwards
+
+	// Template picker for /new
+	showTemplates  bool
+	templateList   []templates.Template
+	templateCursor int
+
+	// Files staged with /file for the next message
+	attachments []string
+
+	// Files the composer would attach and their checks, worked out in
+	// Update for the preview
+	attachPaths    []string
+	attachStats    map[string]attach.Info
+	attachStatsFor string // Composer text and staged files they're for
+
+	// Inline diagram previews under agent messages
+	showThumbnails   bool
+	thumbnailToggled map[int]bool            // Messages whose preview is flipped from showThumbnails
+	thumbnails       map[thumbnailKey]string // Rendered previews; cleared on resize and theme change
+
+	// Message selection mode
+	selecting     bool
+	selectedID    int  // ID of the selected message node
+	pendingDelete bool // First d pressed; a second one deletes
+
 	// Layout calculations
 	chatWidth    int
 	diagramWidth int
@@ -54,6 +137,12 @@ func NewModel() Model {
 		cfg = config.DefaultConfig()
 	}
 
+	// Load prompt history
+	hist, err := history.Load()
+	if err != nil {
+		logger.Component("history").Warnf("Failed to load history: %v", err)
+	}
+
 	// Initialize input
 	input := textinput.New()
 	input.Placeholder = "Type a message or paste code..."
@@ -87,15 +176,23 @@ func NewModel() Model {
 		logViewport:       logVp,
 		input:             input,
 		themeList:         themeList,
-		messages:          make([]chat.Message, 0),
+		messages:          chat.NewTree(),
 		config:            cfg,
 		showThemeSelector: false,
 		previewTheme:      cfg.Theme,
 		savedTheme:        cfg.Theme,
+		history:           hist,
+		historyIndex:      hist.Len(),
+		historyMatch:      -1,
+		editingID:         -1,
+		showThumbnails:    true,
+		thumbnailToggled:  make(map[int]bool),
+		thumbnails:        make(map[thumbnailKey]string),
+		retryTips:         make(map[int]int),
 	}
 }
 
 // Init initializes the application
 func (m Model) Init() tea.Cmd {
-	return textinput.Blink
+	return tea.Batch(textinput.Blink, m.watchCmd())
 }
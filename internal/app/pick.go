@@ -0,0 +1,213 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/diagram"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// pickHelp lists the keys of the node picker
+const pickHelp = "←/→: cycle • Enter: ask about it • Esc: cancel"
+
+// startPicking enters the node picker on the current diagram's elements
+func (m Model) startPicking() Model {
+	if m.currentDiagram == "" {
+		logger.Component("pick").Info("No diagram to pick from yet")
+		return m
+	}
+	elements := diagram.Elements(m.currentDiagram)
+	if len(elements) == 0 {
+		logger.Component("pick").Info("Nothing to pick: the node picker works on flowcharts and sequence diagrams")
+		return m
+	}
+
+	m.picking = true
+	m.pickElements = elements
+	m.pickCursor = 0
+	m.pickShowedDiagram = m.showDiagram
+	m.showDiagram = true
+	m.input.Blur()
+	return m
+}
+
+// stopPicking leaves the node picker
+func (m Model) stopPicking() Model {
+	m.picking = false
+	m.pickElements = nil
+	m.showDiagram = m.pickShowedDiagram
+	m.input.Focus()
+	return m
+}
+
+// updatePicking handles keys in the node picker
+func (m Model) updatePicking(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		return m.stopPicking(), nil
+
+	case "right", "down", "tab", "l", "j", "n":
+		m.pickCursor = (m.pickCursor + 1) % len(m.pickElements)
+
+	case "left", "up", "shift+tab", "h", "k", "p":
+		m.pickCursor = (m.pickCursor + len(m.pickElements) - 1) % len(m.pickElements)
+
+	case "enter":
+		// Put the reference ahead of anything already typed
+		ref := "about " + m.pickElements[m.pickCursor].Reference() + ": "
+		m = m.stopPicking()
+		m.input.SetValue(ref + m.input.Value())
+		m.input.CursorEnd()
+	}
+
+	return m, nil
+}
+
+// renderPickStatus describes the selected element under the drawing
+func (m Model) renderPickStatus(width int) string {
+	e := m.pickElements[m.pickCursor]
+	status := fmt.Sprintf("%d/%d %s", m.pickCursor+1, len(m.pickElements), e.Reference())
+	return truncate(status, width)
+}
+
+// pickedOccurrence counts the elements before the selected one that draw
+// the same label, so repeated edge and message labels mark the right one
+func (m Model) pickedOccurrence() int {
+	e := m.pickElements[m.pickCursor]
+	n := 0
+	for _, other := range m.pickElements[:m.pickCursor] {
+		if other.Kind == e.Kind && other.Label == e.Label {
+			n++
+		}
+	}
+	return n
+}
+
+// box is a node or participant drawn in the rendering, in rune columns
+type box struct {
+	top, left, bottom, right int
+	text                     string // Text inside, lines joined by spaces
+}
+
+// findBoxes finds the boxes in a rendering
+func findBoxes(grid [][]rune) []box {
+	var boxes []box
+	for y, row := range grid {
+		for x, r := range row {
+			if r != '┌' {
+				continue
+			}
+			right := x + 1
+			for right < len(row) && strings.ContainsRune("─┬┴┼", row[right]) {
+				right++
+			}
+			if right == len(row) || row[right] != '┐' {
+				continue
+			}
+			bottom := y + 1
+			for bottom < len(grid) && x < len(grid[bottom]) && strings.ContainsRune("│├┤┼", grid[bottom][x]) {
+				bottom++
+			}
+			if bottom == len(grid) || x >= len(grid[bottom]) || grid[bottom][x] != '└' {
+				continue
+			}
+
+			var text []string
+			for inner := y + 1; inner < bottom; inner++ {
+				if right <= len(grid[inner]) {
+					text = append(text, strings.Fields(string(grid[inner][x+1:right]))...)
+				}
+			}
+			boxes = append(boxes, box{top: y, left: x, bottom: bottom, right: right, text: strings.Join(text, " ")})
+		}
+	}
+	return boxes
+}
+
+// markPicked styles the selected element in plain text with mark and the
+// rest with base: a node's or participant's box, or an edge's or message's
+// end boxes and its nth label outside any box
+func markPicked(text string, e diagram.Element, nth int, base, mark lipgloss.Style) string {
+	lines := strings.Split(text, "\n")
+	grid := make([][]rune, len(lines))
+	marked := make([][]bool, len(lines))
+	inBox := make([][]bool, len(lines))
+	for y, line := range lines {
+		grid[y] = []rune(line)
+		marked[y] = make([]bool, len(grid[y]))
+		inBox[y] = make([]bool, len(grid[y]))
+	}
+
+	boxLabels := []string{e.Label}
+	if e.Kind == diagram.ElementEdge || e.Kind == diagram.ElementMessage {
+		boxLabels = e.Ends[:]
+	}
+	for _, b := range findBoxes(grid) {
+		picked := false
+		for _, label := range boxLabels {
+			if label != "" && b.text == strings.Join(strings.Fields(label), " ") {
+				picked = true
+			}
+		}
+		for y := b.top; y <= b.bottom; y++ {
+			for x := b.left; x <= b.right && x < len(grid[y]); x++ {
+				inBox[y][x] = true
+				marked[y][x] = picked
+			}
+		}
+	}
+
+	if (e.Kind == diagram.ElementEdge || e.Kind == diagram.ElementMessage) && e.Label != "" {
+		markLabel(grid, inBox, marked, []rune(e.Label), nth)
+	}
+
+	for y := range lines {
+		lines[y] = renderMarked(grid[y], marked[y], base, mark)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// markLabel marks the nth occurrence of label that stands on its own
+// outside the boxes
+func markLabel(grid [][]rune, inBox, marked [][]bool, label []rune, nth int) {
+	isWord := func(row []rune, x int) bool {
+		return x >= 0 && x < len(row) && (unicode.IsLetter(row[x]) || unicode.IsDigit(row[x]))
+	}
+	for y, row := range grid {
+		for x := 0; x+len(label) <= len(row); x++ {
+			if inBox[y][x] || string(row[x:x+len(label)]) != string(label) ||
+				isWord(row, x-1) || isWord(row, x+len(label)) {
+				continue
+			}
+			if nth > 0 {
+				nth--
+				continue
+			}
+			for i := range label {
+				marked[y][x+i] = true
+			}
+			return
+		}
+	}
+}
+
+// renderMarked styles runs of a line with mark where marked, else base
+func renderMarked(line []rune, marked []bool, base, mark lipgloss.Style) string {
+	var b strings.Builder
+	start := 0
+	for i := 1; i <= len(line); i++ {
+		if i == len(line) || marked[i] != marked[start] {
+			style := base
+			if marked[start] {
+				style = mark
+			}
+			b.WriteString(style.Render(string(line[start:i])))
+			start = i
+		}
+	}
+	return b.String()
+}
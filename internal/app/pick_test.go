@@ -0,0 +1,125 @@
+package app
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/diagram"
+)
+
+func TestPicking(t *testing.T) {
+	m := NewModel()
+	m = m.loadGeneratedDiagram(DiagramGeneratedMsg{
+		Title:  "Flow",
+		Source: "graph TD\n    A[Start] --> B{Is it working?}\n    B -->|Yes| C[Great!]",
+	})
+	m.input.SetValue("split this step")
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyCtrlN})
+	if !m.picking || len(m.pickElements) != 5 {
+		t.Fatalf("picker not started: picking=%v elements=%d", m.picking, len(m.pickElements))
+	}
+
+	// Typing cycles instead of reaching the composer
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyRight}, tea.KeyMsg{Type: tea.KeyRight}, tea.KeyMsg{Type: tea.KeyLeft})
+	m, _ = press(m, keys("j")...)
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.picking {
+		t.Fatal("picker still open after Enter")
+	}
+	want := "about node C[Great!]: split this step"
+	if got := m.input.Value(); got != want {
+		t.Errorf("composer = %q, want %q", got, want)
+	}
+
+	// Left from the first element wraps to the last
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyCtrlN}, tea.KeyMsg{Type: tea.KeyLeft})
+	if got := m.pickElements[m.pickCursor].Reference(); got != "edge B -->|Yes| C" {
+		t.Errorf("wrapped to %q", got)
+	}
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.picking || m.input.Value() != want {
+		t.Errorf("Esc should leave the composer alone: %q", m.input.Value())
+	}
+}
+
+func TestPicking_NothingToPick(t *testing.T) {
+	m, _ := press(NewModel(), tea.KeyMsg{Type: tea.KeyCtrlN})
+	if m.picking {
+		t.Error("picker started without a diagram")
+	}
+
+	m = m.loadGeneratedDiagram(DiagramGeneratedMsg{Title: "ER", Source: "erDiagram\n    A ||--o{ B : has"})
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyCtrlN})
+	if m.picking {
+		t.Error("picker started on a diagram without nodes")
+	}
+}
+
+func TestPicking_RestoresDiagramPane(t *testing.T) {
+	m := NewModel()
+	m = m.loadGeneratedDiagram(DiagramGeneratedMsg{Title: "Flow", Source: "graph TD\n    A --> B"})
+	m.showDiagram = false
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyCtrlN})
+	if !m.showDiagram {
+		t.Fatal("picking should show the diagram pane")
+	}
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.showDiagram {
+		t.Error("the hidden diagram pane should be hidden again after picking")
+	}
+}
+
+func TestMarkPicked(t *testing.T) {
+	rendering := strings.Join([]string{
+		"┌───┐     ┌────┐",
+		"│ A │     │ AB │",
+		"└─┬─┘     └─┬──┘",
+		"  │ A       │ A",
+		"  ▼         ▼",
+		"┌───┐     ┌───┐",
+		"│ C │     │ D │",
+		"└───┘     └───┘",
+	}, "\n")
+	base := lipgloss.NewStyle()
+	mark := lipgloss.NewStyle().Transform(func(s string) string {
+		return strings.Map(func(r rune) rune {
+			if r == ' ' {
+				return '#'
+			}
+			return unicode.ToLower(r)
+		}, s)
+	})
+
+	// Only node A's box is marked, not the A in AB or on the edges
+	got := markPicked(rendering, diagram.Element{Kind: diagram.ElementNode, Label: "A"}, 0, base, mark)
+	want := strings.Join([]string{
+		"┌───┐     ┌────┐",
+		"│#a#│     │ AB │",
+		"└─┬─┘     └─┬──┘",
+		"  │ A       │ A",
+	}, "\n")
+	if !strings.HasPrefix(got, want) {
+		t.Errorf("node marked as\n%s\nwant\n%s", got, want)
+	}
+
+	// An edge marks its ends and its own label, the second A here
+	got = markPicked(rendering, diagram.Element{Kind: diagram.ElementEdge, Label: "A", Ends: [2]string{"AB", "D"}}, 1, base, mark)
+	want = strings.Join([]string{
+		"┌───┐     ┌────┐",
+		"│ A │     │#ab#│",
+		"└─┬─┘     └─┬──┘",
+		"  │ A       │ a",
+		"  ▼         ▼",
+		"┌───┐     ┌───┐",
+		"│ C │     │#d#│",
+		"└───┘     └───┘",
+	}, "\n")
+	if got != want {
+		t.Errorf("edge marked as\n%s\nwant\n%s", got, want)
+	}
+}
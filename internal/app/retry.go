@@ -0,0 +1,72 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// retryLastResponse asks the provider for another answer to the conversation
+// that preceded the last agent message
+func (m Model) retryLastResponse() (Model, tea.Cmd) {
+	node := m.messages.LastWithRole(chat.RoleAgent)
+	if node == nil {
+		logger.Component("chat").Warn("Nothing to retry: no agent response yet")
+		return m, nil
+	}
+	return m.retryResponse(node)
+}
+
+// retryResponse asks the provider for another answer to the conversation
+// that preceded the agent message in node
+func (m Model) retryResponse(node *chat.Node) (Model, tea.Cmd) {
+	path := m.messages.Messages()
+	prefix := path[:pathIndex(m.messages.Path(), node.ID)]
+
+	// Remember where the conversation was, to tell if it moved on before the answer arrives
+	m.retryTips[node.ID] = m.messages.Last().ID
+
+	logger.Component("chat").Infof("Regenerating agent response %d", node.ID)
+	return m, m.simulateAgentResponse(prefix, node.ID)
+}
+
+// addAlternative adds a regenerated response as a new branch next to the
+// agent message with the given ID. It's only shown if the conversation is
+// where it was when the retry was requested; when it has moved on, the
+// answer waits in the branch navigator instead.
+func (m Model) addAlternative(id int, msg chat.Message) Model {
+	tip, ok := m.retryTips[id]
+	if !ok || m.messages.Find(tip) == nil {
+		// Requested in a conversation that has since been loaded over
+		logger.Component("chat").Infof("Dropped regenerated response: message %d is no longer in this conversation", id)
+		return m
+	}
+
+	if last := m.messages.Last(); last != nil && last.ID == tip {
+		m.messages.Branch(id, msg)
+		m.currentDiagram = latestDiagram(m.messages.Messages())
+		return m
+	}
+
+	if m.messages.Sibling(id, msg) == nil {
+		logger.Component("chat").Infof("Dropped regenerated response: message %d was deleted", id)
+		return m
+	}
+	logger.Component("chat").Infof("Regenerated response %d added as an alternative; open it with Ctrl+T", id)
+	return m
+}
+
+// cycleAlternative shows the previous (-1) or next (+1) alternative of the last agent message
+func (m Model) cycleAlternative(delta int) Model {
+	node := m.messages.LastWithRole(chat.RoleAgent)
+	if node == nil {
+		return m
+	}
+
+	if selected := m.messages.SelectSibling(node.ID, delta); selected != nil {
+		m.currentDiagram = latestDiagram(m.messages.Messages())
+		index, count := m.messages.Siblings(selected.ID)
+		logger.Component("chat").Debugf("Showing alternative %d/%d", index+1, count)
+	}
+	return m
+}
@@ -0,0 +1,98 @@
+package app
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/chat"
+)
+
+func TestUpdate_RetryCommand(t *testing.T) {
+	m := newConversationModel()
+	m.input.SetValue("/retry")
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(Model)
+	if cmd == nil {
+		t.Fatal("/retry should request a new response")
+	}
+
+	resp, ok := cmd().(AgentResponseMsg)
+	if !ok || resp.RetryOf != m.messages.Last().ID {
+		t.Fatalf("/retry command produced %#v, want retry AgentResponseMsg", resp)
+	}
+
+	newModel, _ = m.Update(AgentResponseMsg{Content: "another answer", Diagram: "graph TD\n    X --> Y", RetryOf: m.messages.Last().ID})
+	m = newModel.(Model)
+
+	if m.messages.Len() != 4 {
+		t.Errorf("After retry, messages length = %d, want 4", m.messages.Len())
+	}
+	last := m.messages.Last()
+	if last.Message.Content != "another answer" {
+		t.Errorf("Last message = %q, want the retry", last.Message.Content)
+	}
+	if index, count := m.messages.Siblings(last.ID); index != 1 || count != 2 {
+		t.Errorf("Siblings() = %d, %d, want 1, 2", index, count)
+	}
+	if m.currentDiagram != "graph TD\n    X --> Y" {
+		t.Errorf("currentDiagram = %q, want retried diagram", m.currentDiagram)
+	}
+}
+
+func TestUpdate_RetryAfterConversationMovedOn(t *testing.T) {
+	m := newConversationModel()
+	retried := m.messages.Last().ID
+	m, retry := send(m, "/retry")
+	m, _ = send(m, "add a cache")
+
+	// The retry's answer arrives after the new message was sent
+	resp, ok := retry().(AgentResponseMsg)
+	if !ok || resp.RetryOf != retried {
+		t.Fatalf("/retry produced %#v, want a retry of message %d", resp, retried)
+	}
+	newModel, _ := m.Update(resp)
+	m = newModel.(Model)
+
+	if last := m.messages.Last(); last.Message.Content != "add a cache" {
+		t.Errorf("Last message = %q, want the newest exchange to stay active", last.Message.Content)
+	}
+	if m.currentDiagram != "graph LR\n    A --> B" {
+		t.Errorf("currentDiagram = %q, want it unchanged", m.currentDiagram)
+	}
+	if _, count := m.messages.Siblings(retried); count != 2 {
+		t.Errorf("Siblings() count = %d, want the alternative kept", count)
+	}
+}
+
+func TestUpdate_RetryNothing(t *testing.T) {
+	m := NewModel()
+
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	m = newModel.(Model)
+
+	if cmd != nil {
+		t.Error("Retry without an agent response should not request anything")
+	}
+}
+
+func TestUpdate_CycleAlternative(t *testing.T) {
+	m := newConversationModel()
+	m, _ = m.retryResponse(m.messages.Last())
+	m = m.addAlternative(m.messages.Last().ID, chat.Message{Role: chat.RoleAgent, Content: "another answer", Diagram: "graph TD\n    X --> Y"})
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyShiftLeft})
+	m = newModel.(Model)
+	if got := m.messages.Last().Message.Content; got != "second answer" {
+		t.Errorf("After Shift+Left, content = %q, want original answer", got)
+	}
+	if m.currentDiagram != "graph LR\n    A --> B" {
+		t.Errorf("After Shift+Left, currentDiagram = %q, want original diagram", m.currentDiagram)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyShiftRight})
+	m = newModel.(Model)
+	if got := m.messages.Last().Message.Content; got != "another answer" {
+		t.Errorf("After Shift+Right, content = %q, want the alternative", got)
+	}
+}
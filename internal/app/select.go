@@ -0,0 +1,164 @@
+package app
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// selectionHelp lists the actions available in selection mode
+const selectionHelp = "j/k: move • y: copy • e: edit • r: retry • v: show diagram • dd: delete • Esc: back to composer"
+
+// startSelection moves focus from the composer to the newest message
+func (m Model) startSelection() Model {
+	last := m.messages.Last()
+	if last == nil {
+		logger.Component("chat").Info("No messages to select yet")
+		return m
+	}
+
+	m.selecting = true
+	m.selectedID = last.ID
+	m.pendingDelete = false
+	m.input.Blur()
+	return m.scrollToSelection()
+}
+
+// stopSelection returns focus to the composer
+func (m Model) stopSelection() Model {
+	m.selecting = false
+	m.pendingDelete = false
+	m.input.Focus()
+	return m
+}
+
+// updateSelection handles key input while a message is selected
+func (m Model) updateSelection(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	path := m.messages.Path()
+	if len(path) == 0 {
+		return m.stopSelection(), nil
+	}
+	index := pathIndex(path, m.selectedID)
+	if index == len(path) {
+		index = len(path) - 1
+	}
+	node := path[index]
+
+	// Any key other than a second d cancels a pending delete
+	key := msg.String()
+	confirmDelete := m.pendingDelete && key == "d"
+	m.pendingDelete = false
+
+	switch key {
+	case "ctrl+c":
+		logger.Component("app").Info("User requested exit")
+		return m, tea.Quit
+
+	case "esc", "tab", "i", "q":
+		return m.stopSelection(), nil
+
+	case "up", "k":
+		if index > 0 {
+			index--
+		}
+
+	case "down", "j":
+		if index < len(path)-1 {
+			index++
+		}
+
+	case "home", "g":
+		index = 0
+
+	case "end", "G":
+		index = len(path) - 1
+
+	case "y":
+		return m, copyText("selected message", node.Message.Content)
+
+	case "e":
+		if node.Message.Role != chat.RoleUser {
+			logger.Component("chat").Warn("Only your own messages can be edited; use r to regenerate an agent response")
+			return m, nil
+		}
+		m = m.stopSelection()
+		return m.startEdit(node), nil
+
+	case "r":
+		if node.Message.Role != chat.RoleAgent {
+			logger.Component("chat").Warn("Only agent responses can be regenerated; use e to edit your message")
+			return m, nil
+		}
+		m = m.stopSelection()
+		return m.retryResponse(node)
+
+	case "v", "enter":
+		return m.showSelectedDiagram(node), nil
+
+	case "d":
+		if !confirmDelete {
+			m.pendingDelete = true
+			return m, nil
+		}
+		return m.deleteSelected(node), nil
+
+	default:
+		return m, nil
+	}
+
+	m.selectedID = path[index].ID
+	return m.scrollToSelection(), nil
+}
+
+// showSelectedDiagram makes the selected message's diagram current and
+// toggles its inline preview
+func (m Model) showSelectedDiagram(node *chat.Node) Model {
+	if !node.Message.HasDiagram() {
+		logger.Component("chat").Info("Selected message has no diagram")
+		return m
+	}
+
+	m.currentDiagram = node.Message.Diagram
+	m.thumbnailToggled[node.ID] = !m.thumbnailToggled[node.ID]
+	return m.scrollToSelection()
+}
+
+// deleteSelected removes the selected message and every reply after it,
+// selecting the message before it
+func (m Model) deleteSelected(node *chat.Node) Model {
+	parent := node.Parent()
+	removed := m.messages.Remove(node.ID)
+	m.currentDiagram = latestDiagram(m.messages.Messages())
+	logger.Component("chat").Infof("Deleted message %d (%d message(s) removed)", node.ID, removed)
+
+	// Select whatever now follows the parent, or the parent itself
+	switch {
+	case parent != nil && parent.ActiveChild() != nil:
+		m.selectedID = parent.ActiveChild().ID
+	case parent != nil && parent != m.messages.Root():
+		m.selectedID = parent.ID
+	default:
+		return m.stopSelection()
+	}
+	return m.scrollToSelection()
+}
+
+// scrollToSelection scrolls the chat viewport so the selected message is in view
+func (m Model) scrollToSelection() Model {
+	content, spans := m.renderChatContent()
+	m.chatViewport.SetContent(content)
+
+	for _, span := range spans {
+		if span.id != m.selectedID {
+			continue
+		}
+		switch {
+		case span.start < m.chatViewport.YOffset || span.end-span.start > m.chatViewport.Height:
+			// Show the top of the message when it is above the view or taller than it
+			m.chatViewport.SetYOffset(span.start)
+		case span.end > m.chatViewport.YOffset+m.chatViewport.Height:
+			m.chatViewport.SetYOffset(span.end - m.chatViewport.Height)
+		}
+	}
+	return m
+}
@@ -0,0 +1,198 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// keys returns key messages for a string of rune keys
+func keys(s string) []tea.KeyMsg {
+	var msgs []tea.KeyMsg
+	for _, r := range s {
+		msgs = append(msgs, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return msgs
+}
+
+// press feeds key messages to the model, returning it and the last command
+func press(m Model, msgs ...tea.KeyMsg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	for _, msg := range msgs {
+		var newModel tea.Model
+		newModel, cmd = m.Update(msg)
+		m = newModel.(Model)
+	}
+	return m, cmd
+}
+
+func TestUpdate_SelectionNavigate(t *testing.T) {
+	m := newConversationModel()
+	path := m.messages.Path()
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyTab})
+	if !m.selecting || m.selectedID != path[3].ID {
+		t.Fatalf("Tab should select the newest message, got selecting=%t id=%d", m.selecting, m.selectedID)
+	}
+	if m.input.Focused() {
+		t.Error("Composer should lose focus in selection mode")
+	}
+
+	tests := []struct {
+		keys string
+		want int // Index into path
+	}{
+		{keys: "k", want: 2},
+		{keys: "kk", want: 0},
+		{keys: "k", want: 0}, // Stays on the oldest
+		{keys: "j", want: 1},
+		{keys: "G", want: 3},
+		{keys: "g", want: 0},
+	}
+	for _, tt := range tests {
+		m, _ = press(m, keys(tt.keys)...)
+		if m.selectedID != path[tt.want].ID {
+			t.Errorf("After %q, selected %d, want %d", tt.keys, m.selectedID, path[tt.want].ID)
+		}
+	}
+
+	// Typing doesn't reach the composer while selecting
+	if m.input.Value() != "" {
+		t.Errorf("Composer value = %q, want empty", m.input.Value())
+	}
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.selecting || !m.input.Focused() {
+		t.Error("Esc should return focus to the composer")
+	}
+}
+
+func TestUpdate_SelectionEmpty(t *testing.T) {
+	m, _ := press(NewModel(), tea.KeyMsg{Type: tea.KeyTab})
+	if m.selecting {
+		t.Error("Selection mode needs at least one message")
+	}
+}
+
+func TestUpdate_SelectionCopy(t *testing.T) {
+	copied := stubClipboard(t, nil)
+	m := newConversationModel()
+
+	m, cmd := press(m, append([]tea.KeyMsg{{Type: tea.KeyTab}}, keys("ky")...)...)
+	if cmd == nil {
+		t.Fatal("y should produce a copy command")
+	}
+	cmd()
+
+	if *copied != "now make it left-to-right" {
+		t.Errorf("copied %q, want the selected message", *copied)
+	}
+	if !m.selecting {
+		t.Error("Copying should stay in selection mode")
+	}
+}
+
+func TestUpdate_SelectionEdit(t *testing.T) {
+	m := newConversationModel()
+	path := m.messages.Path()
+
+	// Agent messages can't be edited
+	m, _ = press(m, append([]tea.KeyMsg{{Type: tea.KeyTab}}, keys("e")...)...)
+	if m.isEditing() || !m.selecting {
+		t.Fatal("e on an agent message should do nothing")
+	}
+
+	m, _ = press(m, keys("ke")...)
+	if m.selecting {
+		t.Error("Editing should return focus to the composer")
+	}
+	if m.editingID != path[2].ID || m.input.Value() != "now make it left-to-right" {
+		t.Errorf("editingID = %d, composer = %q, want message %d loaded", m.editingID, m.input.Value(), path[2].ID)
+	}
+}
+
+func TestUpdate_SelectionRetry(t *testing.T) {
+	m := newConversationModel()
+	path := m.messages.Path()
+
+	// Regenerate the first agent answer, not the last one
+	m, cmd := press(m, append([]tea.KeyMsg{{Type: tea.KeyTab}}, keys("kkr")...)...)
+	if cmd == nil {
+		t.Fatal("r on an agent message should request a new response")
+	}
+	resp, ok := cmd().(AgentResponseMsg)
+	if !ok || resp.RetryOf != path[1].ID {
+		t.Fatalf("r produced %#v, want a retry of message %d", resp, path[1].ID)
+	}
+
+	newModel, _ := m.Update(resp)
+	m = newModel.(Model)
+
+	if m.messages.Len() != 2 {
+		t.Errorf("Len() = %d, want the conversation to end at the new answer", m.messages.Len())
+	}
+	if _, count := m.messages.Siblings(m.messages.Last().ID); count != 2 {
+		t.Errorf("Siblings count = %d, want 2", count)
+	}
+}
+
+func TestUpdate_SelectionShowDiagram(t *testing.T) {
+	m := newConversationModel()
+	path := m.messages.Path()
+
+	m, _ = press(m, append([]tea.KeyMsg{{Type: tea.KeyTab}}, keys("kkv")...)...)
+	if m.currentDiagram != path[1].Message.Diagram {
+		t.Errorf("currentDiagram = %q, want the selected message's diagram", m.currentDiagram)
+	}
+	if !strings.Contains(m.renderMessage(path[1], false), "diagram hidden") {
+		t.Error("v should collapse the selected message's preview")
+	}
+	if strings.Contains(m.renderMessage(path[3], false), "diagram hidden") {
+		t.Error("Other previews should be unaffected")
+	}
+
+	m, _ = press(m, keys("v")...)
+	if strings.Contains(m.renderMessage(path[1], false), "diagram hidden") {
+		t.Error("Pressing v again should expand the preview")
+	}
+}
+
+func TestUpdate_SelectionDelete(t *testing.T) {
+	m := newConversationModel()
+	path := m.messages.Path()
+
+	// A single d only arms the delete; another key cancels it
+	m, _ = press(m, append([]tea.KeyMsg{{Type: tea.KeyTab}}, keys("kdj")...)...)
+	if m.messages.Len() != 4 || m.pendingDelete {
+		t.Fatal("d followed by another key should not delete")
+	}
+
+	m, _ = press(m, keys("kdd")...)
+	if m.messages.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2 after deleting the second user message", m.messages.Len())
+	}
+	if m.selectedID != path[1].ID {
+		t.Errorf("selectedID = %d, want the previous message %d", m.selectedID, path[1].ID)
+	}
+	if m.currentDiagram != path[1].Message.Diagram {
+		t.Errorf("currentDiagram = %q, want the remaining diagram", m.currentDiagram)
+	}
+
+	m, _ = press(m, keys("ggdd")...)
+	if m.messages.Len() != 0 || m.selecting {
+		t.Error("Deleting the first message should clear the conversation and leave selection mode")
+	}
+}
+
+func TestUpdate_SelectionKeepsResponses(t *testing.T) {
+	m := newConversationModel()
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyTab})
+
+	newModel, _ := m.Update(AgentResponseMsg{Content: "late answer"})
+	m = newModel.(Model)
+
+	if m.messages.Last().Message.Content != "late answer" {
+		t.Error("Responses arriving in selection mode should still be added")
+	}
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mnesler/hauk-tui/internal/logger"
+	"github.com/mnesler/hauk-tui/internal/session"
+)
+
+// handleSessionCommand runs /session save [name], /session load <name> or /session list
+func (m Model) handleSessionCommand(args []string) Model {
+	if len(args) == 0 {
+		logger.Component("session").Warn("Usage: /session save [name] | load <name> | list")
+		return m
+	}
+
+	switch args[0] {
+	case "save":
+		name := ""
+		if len(args) > 1 {
+			name = args[1]
+		}
+		return m.saveSession(name)
+
+	case "load":
+		if len(args) < 2 {
+			logger.Component("session").Warn("Usage: /session load <name>")
+			return m
+		}
+		return m.loadSession(args[1])
+
+	case "list":
+		return m.listSessions()
+	}
+
+	logger.Component("session").Warnf("Unknown session action %q", args[0])
+	return m
+}
+
+// saveSession writes the conversation tree and current diagram to disk
+func (m Model) saveSession(name string) Model {
+	if name == "" {
+		name = m.sessionName
+	}
+	if name == "" {
+		name = session.DefaultName(time.Now())
+	}
+
+	s := &session.Session{
+		Name:         name,
+		Diagram:      m.currentDiagram,
+		Conversation: m.messages,
+	}
+	if err := session.Save(s); err != nil {
+		logger.Component("session").Errorf("Failed to save session: %v", err)
+		return m
+	}
+
+	m.sessionName = name
+	logger.Component("session").Infof("Saved session %q", name)
+	return m
+}
+
+// loadSession replaces the conversation with a saved one
+func (m Model) loadSession(name string) Model {
+	s, err := session.Load(name)
+	if err != nil {
+		logger.Component("session").Errorf("Failed to load session: %v", err)
+		return m
+	}
+
+	// Message IDs restart in the loaded tree, so nothing may refer to the old one
+	m.messages = s.Conversation
+	m.currentDiagram = s.Diagram
+	m.docBlock = nil
+	m.docsNodeID = 0
+	m.sessionName = s.Name
+	m.editingID = -1
+	m.editDraft = ""
+	m.editAttachments = nil
+	m.retryTips = make(map[int]int)
+	m.thumbnailToggled = make(map[int]bool)
+	m.thumbnails = make(map[thumbnailKey]string)
+	m = m.stopSelection()
+	m.chatViewport.GotoBottom()
+	logger.Component("session").Infof("Loaded session %q (%d messages)", s.Name, m.messages.Len())
+	return m
+}
+
+// listSessions logs the names of saved sessions
+func (m Model) listSessions() Model {
+	names, err := session.List()
+	if err != nil {
+		logger.Component("session").Errorf("Failed to list sessions: %v", err)
+		return m
+	}
+
+	if len(names) == 0 {
+		logger.Component("session").Info("No saved sessions")
+		return m
+	}
+	logger.Component("session").Infof("Saved sessions: %s", strings.Join(names, ", "))
+	return m
+}
@@ -0,0 +1,228 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mnesler/hauk-tui/internal/diagram"
+	"github.com/mnesler/hauk-tui/internal/editor"
+	"github.com/mnesler/hauk-tui/internal/highlight"
+	"github.com/mnesler/hauk-tui/internal/logger"
+	"github.com/mnesler/hauk-tui/internal/ui"
+)
+
+// sourceEditorHelp lists the keys of the built-in editor
+const sourceEditorHelp = "Ctrl+S: apply • Esc: discard"
+
+// sourceEditorConfirm asks before throwing away edits
+const sourceEditorConfirm = "Discard your edits? Esc: discard • Ctrl+S: apply • any other key: keep editing"
+
+// gutterWidth is the width of the line numbers beside the source
+const gutterWidth = 5
+
+// openSourceEditor opens the current diagram's source for editing in the
+// diagram pane
+func (m Model) openSourceEditor() Model {
+	if m.currentDiagram == "" {
+		logger.Component("editor").Info("No diagram to edit yet")
+		return m
+	}
+
+	m.sourceEditor = editor.New(m.currentDiagram)
+	m.editorOriginal = m.currentDiagram
+	m.editorTop, m.editorLeft = 0, 0
+	m.editorConfirmDiscard = false
+	m.showDiagram = true
+	m.input.Blur()
+	logger.Component("editor").Info("Editing diagram source in the diagram pane")
+	return m
+}
+
+// closeSourceEditor leaves the editor, recording the edited source as a new
+// diagram when apply is set
+func (m Model) closeSourceEditor(apply bool) Model {
+	source := m.sourceEditor.Text()
+	changed := m.sourceEditor.Changed()
+	m.sourceEditor = nil
+	m.editorConfirmDiscard = false
+	m.input.Focus()
+
+	if !apply {
+		if changed {
+			logger.Component("editor").Info("Discarded diagram edits")
+		}
+		return m
+	}
+	return m.recordEditedDiagram(strings.TrimRight(source, "\n"), m.editorOriginal)
+}
+
+// updateSourceEditor handles keys while the built-in editor is open
+func (m Model) updateSourceEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	b := m.sourceEditor
+	height, _ := m.sourceEditorSize()
+
+	// A second Esc confirms throwing edits away, anything else keeps editing
+	confirmDiscard := m.editorConfirmDiscard
+	m.editorConfirmDiscard = false
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		logger.Component("app").Info("User requested exit")
+		return m, tea.Quit
+	case tea.KeyCtrlS:
+		return m.closeSourceEditor(true), nil
+	case tea.KeyEsc:
+		if b.Changed() && !confirmDiscard {
+			m.editorConfirmDiscard = true
+			return m, nil
+		}
+		return m.closeSourceEditor(false), nil
+
+	case tea.KeyUp:
+		b.Up(1)
+	case tea.KeyDown:
+		b.Down(1)
+	case tea.KeyPgUp:
+		b.Up(height)
+	case tea.KeyPgDown:
+		b.Down(height)
+	case tea.KeyLeft:
+		b.Left()
+	case tea.KeyRight:
+		b.Right()
+	case tea.KeyHome, tea.KeyCtrlA:
+		b.Home()
+	case tea.KeyEnd, tea.KeyCtrlE:
+		b.End()
+
+	case tea.KeyEnter:
+		b.Newline()
+	case tea.KeyTab:
+		b.Tab()
+	case tea.KeyBackspace:
+		b.Backspace()
+	case tea.KeyDelete:
+		b.Delete()
+	case tea.KeySpace:
+		b.Insert(" ")
+	case tea.KeyRunes:
+		// Pasted text may span lines; auto-indent replaces their indentation
+		for i, part := range strings.Split(string(msg.Runes), "\n") {
+			if i > 0 {
+				b.Newline()
+				part = strings.TrimLeft(part, " \t")
+			}
+			b.Insert(strings.TrimRight(part, "\r"))
+		}
+	}
+
+	return m.scrollSourceEditor(), nil
+}
+
+// sourceEditorSize returns how many source lines and columns of text the
+// editor shows; the live preview gets the rest of the pane
+func (m Model) sourceEditorSize() (height, width int) {
+	height = (m.height - 8) / 2
+	if height < 3 {
+		height = 3
+	}
+	width = m.diagramWidth - 6 - gutterWidth
+	if width < 10 {
+		width = 10
+	}
+	return height, width
+}
+
+// scrollSourceEditor keeps the cursor inside the visible source
+func (m Model) scrollSourceEditor() Model {
+	height, width := m.sourceEditorSize()
+	row, col := m.sourceEditor.Cursor()
+
+	if row < m.editorTop {
+		m.editorTop = row
+	} else if row >= m.editorTop+height {
+		m.editorTop = row - height + 1
+	}
+	if col < m.editorLeft {
+		m.editorLeft = col
+	} else if col >= m.editorLeft+width {
+		m.editorLeft = col - width + 1
+	}
+	return m
+}
+
+// renderSourceEditor renders the editor: numbered, highlighted source with
+// problem lines marked, and the diagram re-rendered from it below
+func (m Model) renderSourceEditor() string {
+	bg := ui.ActiveTheme.DiagramBg
+	height, width := m.sourceEditorSize()
+	palette := highlight.ThemePalette(ui.ActiveTheme, bg)
+	muted := ui.GetTextMutedStyle(bg)
+	problem := lipgloss.NewStyle().Foreground(ui.ActiveTheme.AccentCode).Background(bg)
+	cursor := lipgloss.NewStyle().Reverse(true)
+
+	source := m.sourceEditor.Text()
+	diags := diagram.Validate(source)
+	problemLines := make(map[int]bool)
+	for _, d := range diags {
+		problemLines[d.Line] = true
+	}
+
+	help := muted.Render(sourceEditorHelp)
+	if m.editorConfirmDiscard {
+		help = problem.Render(sourceEditorConfirm)
+	}
+	content := []string{
+		ui.GetHeaderStyle(bg).Render("Editing Diagram"),
+		help,
+		"",
+	}
+
+	lines := m.sourceEditor.Lines()
+	row, col := m.sourceEditor.Cursor()
+	for i := m.editorTop; i < m.editorTop+height && i < len(lines); i++ {
+		gutter := muted.Render(fmt.Sprintf("%3d  ", i+1))
+		if problemLines[i+1] {
+			gutter = problem.Render(fmt.Sprintf("%3d! ", i+1))
+		}
+
+		// Highlight the whole line so tokens cut at the scroll edge keep
+		// their colour, then clip it to the visible columns
+		text := []rune(lines[i])
+		highlighted := highlight.Highlight("mermaid", []string{lines[i]}, palette)[0]
+		right := m.editorLeft + width
+		if i != row {
+			content = append(content, gutter+ansi.Cut(highlighted, m.editorLeft, right))
+			continue
+		}
+
+		under := " "
+		if col < len(text) {
+			under = string(text[col])
+		}
+		content = append(content, gutter+ansi.Cut(highlighted, m.editorLeft, col)+
+			cursor.Render(under)+ansi.Cut(highlighted, col+1, right))
+	}
+
+	// Live preview with the room that's left
+	previewWidth := width + gutterWidth
+	content = append(content, "", muted.Render(strings.Repeat("─", previewWidth)))
+	previewLines := m.height - 12 - height - len(diags)
+	if previewLines < 3 {
+		previewLines = 3
+	}
+	if ascii, err := diagram.Thumbnail(source, previewWidth, previewLines); err != nil {
+		content = append(content, problem.Render(truncate(err.Error(), previewWidth)))
+	} else {
+		content = append(content, ui.GetTextSecondaryStyle().Background(bg).Render(ascii))
+	}
+	for _, d := range diags {
+		content = append(content, problem.Render(truncate(d.String(), previewWidth)))
+	}
+
+	return ui.GetDiagramPanelStyle(m.diagramWidth, m.height-3).
+		Render(lipgloss.JoinVertical(lipgloss.Left, content...))
+}
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func newSourceEditorModel(t *testing.T) Model {
+	t.Helper()
+	m := NewModel()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	m = m.loadGeneratedDiagram(DiagramGeneratedMsg{Title: "Start", Source: "graph TD\n    A --> B"})
+	m, _ = send(m, "/edit inline")
+	if m.sourceEditor == nil {
+		t.Fatal("/edit inline didn't open the editor")
+	}
+	return m
+}
+
+func TestSourceEditor_Apply(t *testing.T) {
+	m := newSourceEditorModel(t)
+	before := m.messages.Len()
+
+	// Go to the end of the last line and add a subgraph
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyEnd}, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = press(m, keys("subgraph s")...)
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = press(m, keys("B --> C")...)
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	m, _ = press(m, keys("end")...)
+
+	view := m.View()
+	for _, want := range []string{"Editing Diagram", "  5  ", "subgraph"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyCtrlS})
+	want := "graph TD\n    A --> B\n    subgraph s\n        B --> C\n    end"
+	if m.sourceEditor != nil || m.currentDiagram != want {
+		t.Fatalf("diagram after Ctrl+S =\n%q\nwant\n%q", m.currentDiagram, want)
+	}
+	if m.messages.Len() != before+1 {
+		t.Errorf("edit not recorded in the conversation")
+	}
+}
+
+func TestSourceEditor_Discard(t *testing.T) {
+	m := newSourceEditorModel(t)
+	before := m.messages.Len()
+
+	m, _ = press(m, keys("x")...)
+
+	// The first Esc asks, any other key keeps editing
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.sourceEditor == nil || !strings.Contains(m.View(), "Discard your edits?") {
+		t.Fatal("Esc with edits should ask before discarding")
+	}
+	m, _ = press(m, keys("y")...)
+	if m.sourceEditor == nil || m.editorConfirmDiscard {
+		t.Fatal("another key should keep editing")
+	}
+
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEsc}, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.sourceEditor != nil || m.currentDiagram != "graph TD\n    A --> B" || m.messages.Len() != before {
+		t.Errorf("Esc Esc should close without changes: diagram=%q", m.currentDiagram)
+	}
+}
+
+func TestSourceEditor_EscWithoutEdits(t *testing.T) {
+	m := newSourceEditorModel(t)
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.sourceEditor != nil {
+		t.Error("Esc without edits should close right away")
+	}
+
+	// Ctrl+X opens it again from the composer
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyCtrlX})
+	if m.sourceEditor == nil {
+		t.Error("Ctrl+X should open the editor")
+	}
+}
+
+func TestSourceEditor_HighlightsClippedTokens(t *testing.T) {
+	m := newSourceEditorModel(t)
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyEnd})
+	m, _ = press(m, keys(" --> Cee")...)
+
+	// Scrolled so the line starts in the middle of "-->"
+	m.editorLeft = 13
+	full := m.renderSourceEditor()
+	if !strings.Contains(ansi.Strip(full), "  2  -> Cee") {
+		t.Fatalf("line not clipped at the scroll edge:\n%s", ansi.Strip(full))
+	}
+}
+
+func TestSourceEditor_MarksProblems(t *testing.T) {
+	m := newSourceEditorModel(t)
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyEnd})
+	m, _ = press(m, keys(" -->")...)
+
+	view := m.View()
+	for _, want := range []string{"2! ", "line 2: edge has no target node"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+}
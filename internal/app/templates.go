@@ -0,0 +1,153 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/logger"
+	"github.com/mnesler/hauk-tui/internal/templates"
+	"github.com/mnesler/hauk-tui/internal/ui"
+)
+
+// loadTemplates reads the template library; tests replace it
+var loadTemplates = templates.Load
+
+// maxTemplatePreviewLines is how much of the selected template the picker shows
+const maxTemplatePreviewLines = 12
+
+// handleNewCommand runs /new, which opens the template picker, and
+// /new <name>, which starts from that template directly
+func (m Model) handleNewCommand(args []string) Model {
+	list, err := loadTemplates()
+	if err != nil {
+		logger.Component("templates").Warnf("Failed to load templates, using the built-in ones: %v", err)
+		list = templates.Builtin()
+	}
+	if len(list) == 0 {
+		dir, _ := templates.Dir()
+		logger.Component("templates").Infof("No templates found. Add .mmd files to %s", dir)
+		return m
+	}
+
+	if len(args) > 0 {
+		t, ok := templates.Find(list, args[0])
+		if !ok {
+			logger.Component("templates").Warnf("No template named %q. Run /new to list them", args[0])
+			return m
+		}
+		return m.useTemplate(t)
+	}
+
+	m.templateList = list
+	m.templateCursor = 0
+	m.showTemplates = true
+	m.input.Blur()
+	return m
+}
+
+// updateTemplatePicker handles keys while the template picker is open
+func (m Model) updateTemplatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.showTemplates = false
+		m.input.Focus()
+
+	case "up", "k":
+		if m.templateCursor > 0 {
+			m.templateCursor--
+		}
+
+	case "down", "j":
+		if m.templateCursor < len(m.templateList)-1 {
+			m.templateCursor++
+		}
+
+	case "enter":
+		m.showTemplates = false
+		m.input.Focus()
+		return m.useTemplate(m.templateList[m.templateCursor]), nil
+	}
+
+	return m, nil
+}
+
+// useTemplate makes a template the current diagram. It's added to the
+// conversation, so the agent gets it as context for the next message.
+func (m Model) useTemplate(t templates.Template) Model {
+	title := "New diagram from template " + t.Name
+	if t.Description != "" {
+		title += " (" + t.Description + ")"
+	}
+	return m.loadGeneratedDiagram(DiagramGeneratedMsg{Title: title, Source: t.Source})
+}
+
+// renderTemplatePicker lists the templates with a preview of the selected one
+func (m Model) renderTemplatePicker() string {
+	modalWidth := m.width * 2 / 3
+	if modalWidth < 50 {
+		modalWidth = 50
+	}
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.AccentUser).
+		Background(ui.ActiveTheme.UserMsgBg).
+		Bold(true)
+	normalStyle := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.TextPrimary)
+	mutedStyle := ui.GetTextMutedStyle(ui.ActiveTheme.ChatBg)
+
+	lines := make([]string, 0, len(m.templateList))
+	for i, t := range m.templateList {
+		style := normalStyle
+		if i == m.templateCursor {
+			style = selectedStyle
+		}
+		line := style.Render(t.Name)
+		if t.Description != "" {
+			// At least an ellipsis of the description fits after long names
+			width := modalWidth - 6 - len([]rune(t.Name))
+			line += mutedStyle.Render(truncate(" - "+t.Description, max(width, 1)))
+		}
+		lines = append(lines, line)
+	}
+
+	// Preview the start of the selected template
+	preview := strings.Split(m.templateList[m.templateCursor].Source, "\n")
+	if len(preview) > maxTemplatePreviewLines {
+		preview = append(preview[:maxTemplatePreviewLines], "…")
+	}
+	for i, line := range preview {
+		preview[i] = truncate(line, modalWidth-6)
+	}
+
+	title := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.TextPrimary).
+		Bold(true).
+		Render(fmt.Sprintf("New Diagram (%d templates)", len(m.templateList)))
+
+	instructions := mutedStyle.
+		Render("↑/↓: navigate • Enter: start from template • Esc: close")
+
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		strings.Join(lines, "\n"),
+		"",
+		mutedStyle.Render(strings.Join(preview, "\n")),
+		"",
+		instructions,
+	)
+
+	modalStyle := lipgloss.NewStyle().
+		Background(ui.ActiveTheme.ChatBg).
+		Foreground(ui.ActiveTheme.TextPrimary).
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ui.ActiveTheme.AccentUser).
+		Padding(1, 2).
+		Width(modalWidth)
+
+	return modalStyle.Render(content)
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/templates"
+)
+
+// stubTemplates loads templates from a temp dir, or fails with err
+func stubTemplates(t *testing.T, err error) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "templates")
+	original := loadTemplates
+	loadTemplates = func() ([]templates.Template, error) {
+		if err != nil {
+			return nil, err
+		}
+		return templates.LoadDir(dir)
+	}
+	t.Cleanup(func() { loadTemplates = original })
+}
+
+func TestNewCommand_Picker(t *testing.T) {
+	stubTemplates(t, nil)
+
+	m, _ := send(NewModel(), "/new")
+	if !m.showTemplates || len(m.templateList) != 4 {
+		t.Fatalf("picker not opened: show=%v templates=%d", m.showTemplates, len(m.templateList))
+	}
+
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(Model)
+	if view := m.View(); !strings.Contains(view, "New Diagram (4 templates)") || !strings.Contains(view, "C4Context") {
+		t.Error("picker view missing the title or the preview of the first template")
+	}
+
+	m, _ = press(m, keys("jj")...)
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.showTemplates {
+		t.Fatal("picker still open after Enter")
+	}
+	if !strings.HasPrefix(m.currentDiagram, "sequenceDiagram") {
+		t.Errorf("current diagram = %q, want the request lifecycle", m.currentDiagram)
+	}
+	msgs := m.messages.Messages()
+	if last := msgs[len(msgs)-1]; !strings.Contains(last.Content, "template request-lifecycle") || last.Diagram == "" {
+		t.Errorf("template not added to the conversation: %q", last.Content)
+	}
+}
+
+func TestNewCommand_ByName(t *testing.T) {
+	stubTemplates(t, nil)
+
+	m, _ := send(NewModel(), "/new state-machine")
+	if m.showTemplates || !strings.HasPrefix(m.currentDiagram, "stateDiagram-v2") {
+		t.Errorf("template not loaded directly: %q", m.currentDiagram)
+	}
+
+	m, _ = send(NewModel(), "/new missing")
+	if m.currentDiagram != "" || m.showTemplates {
+		t.Error("unknown template should change nothing")
+	}
+}
+
+func TestNewCommand_FallsBackToBuiltins(t *testing.T) {
+	stubTemplates(t, errors.New("no home directory"))
+
+	m, _ := send(NewModel(), "/new er-skeleton")
+	if !strings.HasPrefix(m.currentDiagram, "erDiagram") {
+		t.Errorf("built-in template not used: %q", m.currentDiagram)
+	}
+}
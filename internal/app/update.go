@@ -27,11 +27,20 @@ type (
 	AgentResponseMsg struct {
 		Content string
 		Diagram string
+		RetryOf int // ID of the agent message this answer regenerates, 0 for a new reply
 	}
 )
 
 // Update handles all messages and updates the model
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	updated, cmd := m.update(msg)
+
+	// Check files the composer would attach here rather than on every frame
+	return updated.(Model).refreshAttachmentStats(), cmd
+}
+
+// update handles one message
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	// If theme selector is active, handle its input first
@@ -39,13 +48,125 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateThemeSelector(msg)
 	}
 
+	// The branch navigator takes key input only, so a resize or response
+	// that arrives while it's open still reaches the layout
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.showBranches {
+		return m.updateBranchNavigator(keyMsg)
+	}
+
+	// So does reverse history search
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.showHistorySearch {
+		return m.updateHistorySearch(keyMsg)
+	}
+
+	// And the docs picker
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.showDocs {
+		return m.updateDocsPicker(keyMsg)
+	}
+
+	// So does the template picker
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.showTemplates {
+		return m.updateTemplatePicker(keyMsg)
+	}
+
+	// The built-in source editor takes key input the same way
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.sourceEditor != nil {
+		return m.updateSourceEditor(keyMsg)
+	}
+
+	// So does the node picker
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.picking {
+		return m.updatePicking(keyMsg)
+	}
+
+	// In selection mode keys act on the selected message; everything else
+	// (responses, resizes, mouse scrolling) is handled as usual
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.selecting {
+		return m.updateSelection(keyMsg)
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.Type {
 		case tea.KeyCtrlC, tea.KeyEsc:
+			// Esc backs out of editing an earlier message before quitting
+			if msg.Type == tea.KeyEsc && m.isEditing() {
+				return m.cancelEdit(), nil
+			}
 			logger.Component("app").Info("User requested exit")
 			return m, tea.Quit
 
+		case tea.KeyUp:
+			// Recall older prompt, otherwise scroll the chat
+			if m.recallsHistory() {
+				return m.historyPrev(), nil
+			}
+
+		case tea.KeyDown:
+			// Recall newer prompt, otherwise scroll the chat
+			if m.recallsHistory() {
+				return m.historyNext(), nil
+			}
+
+		case tea.KeyShiftUp:
+			// Pick an earlier user message to edit and resend
+			return m.editPrev(), nil
+
+		case tea.KeyShiftDown:
+			// Move the edit selection to a newer user message
+			return m.editNext(), nil
+
+		case tea.KeyShiftLeft:
+			// Show the previous alternative of the last agent response
+			return m.cycleAlternative(-1), nil
+
+		case tea.KeyShiftRight:
+			// Show the next alternative of the last agent response
+			return m.cycleAlternative(1), nil
+
+		case tea.KeyCtrlG:
+			// Regenerate the last agent response
+			return m.retryLastResponse()
+
+		case tea.KeyCtrlO:
+			// Show or hide inline diagram previews
+			m.showThumbnails = !m.showThumbnails
+			m.thumbnailToggled = make(map[int]bool)
+			return m, nil
+
+		case tea.KeyTab:
+			// Complete an @path being typed, otherwise move focus from the
+			// composer to the chat messages
+			if completed, ok := m.completePath(); ok {
+				return completed, nil
+			}
+			return m.startSelection(), nil
+
+		case tea.KeyCtrlD:
+			// Swap the logs for the rendered current diagram and back
+			m.showDiagram = !m.showDiagram
+			return m, nil
+
+		case tea.KeyCtrlN:
+			// Pick a node or edge of the current diagram to ask about
+			return m.startPicking(), nil
+
+		case tea.KeyCtrlX:
+			// Edit the current diagram's source in the diagram pane
+			return m.openSourceEditor(), nil
+
+		case tea.KeyCtrlY:
+			// Copy the current diagram's mermaid source
+			return m, m.copyDiagram()
+
+		case tea.KeyCtrlT:
+			// Browse the conversation tree
+			return m.showBranchNavigator(), nil
+
+		case tea.KeyCtrlR:
+			// Reverse search through prompt history
+			return m.openHistorySearch(), nil
+
 		case tea.KeyEnter:
 			// Check if Alt is pressed
 			if msg.Alt {
@@ -55,32 +176,59 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Enter: send message
 				content := m.input.Value()
 				if content != "" {
+					// Remember the prompt for Up/Down recall and Ctrl+R search
+					m = m.recordPrompt(content)
+
 					// Check if it's a slash command
-					cmdType, _ := command.ParseCommand(content)
-					if cmdType == command.CommandTheme {
-						// Clear input and show theme selector
+					cmdType, args := command.ParseCommand(content)
+					if cmdType != command.CommandNone {
+						m = m.cancelEdit()
 						m.input.SetValue("")
-						m = m.showThemeSelectorModal()
-						logger.Component("command").Info("Theme selector opened")
+						return m.handleCommand(cmdType, args)
+					}
+
+					// Load attached files; a failure keeps the prompt so it can be fixed
+					userMsg, err := m.newUserMessage(content)
+					if err != nil {
+						logger.Component("attach").Errorf("Message not sent: %v", err)
 						return m, nil
-					} else if cmdType == command.CommandNone {
-						// Add user message
-						m.messages = append(m.messages, chat.NewMessage(chat.RoleUser, content))
-						m.input.SetValue("")
+					}
+					m.attachments = nil
 
-						// Auto-scroll chat viewport to bottom
-						m.chatViewport.GotoBottom()
+					// Add user message, replacing the edited one if editing
+					if m.isEditing() {
+						m = m.resendEdited(userMsg)
+					} else {
+						m.messages.Append(userMsg)
+					}
+					m.input.SetValue("")
 
-						// Log the event
-						logger.Component("chat").Infof("User sent message: %d chars", len(content))
+					// Auto-scroll chat viewport to bottom
+					m.chatViewport.GotoBottom()
 
-						// Simulate agent response (will be replaced with real LLM call)
-						cmds = append(cmds, m.simulateAgentResponse())
-					}
+					// Log the event
+					logger.Component("chat").Infof("User sent message: %d chars, %d attachment(s)", len(content), len(userMsg.Attachments))
+
+					// Simulate agent response (will be replaced with real LLM call)
+					cmds = append(cmds, m.simulateAgentResponse(m.messages.Messages(), 0))
 				}
 			}
 		}
 
+	case CopiedMsg:
+		logCopied(msg)
+
+	case DiagramGeneratedMsg:
+		m = m.loadGeneratedDiagram(msg)
+
+	case DiagramEditedMsg:
+		m = m.loadEditedDiagram(msg)
+
+	case watchedFileMsg:
+		var cmd tea.Cmd
+		m, cmd = m.updateWatchedFile(msg)
+		cmds = append(cmds, cmd)
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
@@ -100,6 +248,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Update theme list size
 		m.themeList.SetSize(40, 12)
 
+		// Previews were laid out for the old width
+		m.thumbnails = make(map[thumbnailKey]string)
+
 		// Log resize event
 		logger.Component("ui").Infof("Window resized to %dx%d", m.width, m.height)
 
@@ -107,11 +258,17 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Add agent message
 		agentMsg := chat.NewMessage(chat.RoleAgent, msg.Content)
 		agentMsg.Diagram = msg.Diagram
-		m.messages = append(m.messages, agentMsg)
 
-		// Update current diagram if provided
-		if msg.Diagram != "" {
-			m.currentDiagram = msg.Diagram
+		if msg.RetryOf != 0 {
+			// Keep earlier answers navigable as sibling branches
+			m = m.addAlternative(msg.RetryOf, agentMsg)
+		} else {
+			m.messages.Append(agentMsg)
+
+			// Update current diagram if provided
+			if msg.Diagram != "" {
+				m.currentDiagram = msg.Diagram
+			}
 		}
 
 		// Auto-scroll chat viewport to bottom
@@ -139,13 +296,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
-// simulateAgentResponse simulates an agent response (placeholder)
-func (m Model) simulateAgentResponse() tea.Cmd {
+// simulateAgentResponse simulates an agent response to the conversation so far (placeholder)
+func (m Model) simulateAgentResponse(conversation []chat.Message, retryOf int) tea.Cmd {
+	promptChars := len(systemPrompt)
+	for _, msg := range conversation {
+		promptChars += len(msg.PromptContent())
+	}
+	logger.Component("agent").Debugf("Requesting response for %d messages, %d chars (retry of %d)", len(conversation), promptChars, retryOf)
+
 	return func() tea.Msg {
 		// This will be replaced with real LLM integration
 		return AgentResponseMsg{
 			Content: "I'll create a flowchart for you. Here's a simple example:\n\n```mermaid\ngraph TD\n    A[Start] --> B{Is it working?}\n    B -->|Yes| C[Great!]\n    B -->|No| D[Debug]\n    D --> B\n```",
 			Diagram: "graph TD\n    A[Start] --> B{Is it working?}\n    B -->|Yes| C[Great!]\n    B -->|No| D[Debug]\n    D --> B",
+			RetryOf: retryOf,
 		}
 	}
 }
@@ -201,6 +365,13 @@ func formatThemeName(name string) string {
 	return name // Fallback to original name
 }
 
+// setTheme makes name the active theme, dropping previews styled with the old one
+func (m Model) setTheme(name string) Model {
+	ui.SetActiveTheme(name)
+	m.thumbnails = make(map[thumbnailKey]string)
+	return m
+}
+
 // updateThemeSelector handles input when theme selector is active
 func (m Model) updateThemeSelector(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -208,7 +379,7 @@ func (m Model) updateThemeSelector(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.Type {
 		case tea.KeyEsc:
 			// Cancel and revert to saved theme
-			ui.SetActiveTheme(m.savedTheme)
+			m = m.setTheme(m.savedTheme)
 			m.showThemeSelector = false
 			m.input.Focus()
 			m.input.SetValue("")
@@ -219,7 +390,7 @@ func (m Model) updateThemeSelector(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Apply selected theme
 			if item, ok := m.themeList.SelectedItem().(themeItem); ok {
 				m.config.Theme = item.name
-				ui.SetActiveTheme(item.name)
+				m = m.setTheme(item.name)
 
 				// Save config (silently ignore errors for now)
 				//nolint:errcheck // Config save errors are non-critical
@@ -239,7 +410,7 @@ func (m Model) updateThemeSelector(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Apply theme preview
 			if item, ok := m.themeList.SelectedItem().(themeItem); ok {
-				ui.SetActiveTheme(item.name)
+				m = m.setTheme(item.name)
 				logger.Component("theme").Debugf("Preview theme: %s", item.displayName)
 			}
 			return m, cmd
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"os"
 	"testing"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -8,6 +9,20 @@ import (
 	"github.com/mnesler/hauk-tui/internal/ui"
 )
 
+// TestMain points HOME at a temp directory so tests never touch the real
+// config or prompt history
+func TestMain(m *testing.M) {
+	home, err := os.MkdirTemp("", "hauk-app-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("HOME", home)
+
+	code := m.Run()
+	os.RemoveAll(home)
+	os.Exit(code)
+}
+
 func TestNewModel(t *testing.T) {
 	m := NewModel()
 
@@ -102,17 +117,18 @@ func TestUpdate_RegularMessage(t *testing.T) {
 	}
 
 	// Should add message to history
-	if len(m.messages) != 1 {
-		t.Errorf("After regular message, messages length = %d, want 1", len(m.messages))
+	messages := m.messages.Messages()
+	if len(messages) != 1 {
+		t.Errorf("After regular message, messages length = %d, want 1", len(messages))
 	}
 
-	if len(m.messages) > 0 {
-		if m.messages[0].Role != chat.RoleUser {
-			t.Errorf("Message role = %v, want %v", m.messages[0].Role, chat.RoleUser)
+	if len(messages) > 0 {
+		if messages[0].Role != chat.RoleUser {
+			t.Errorf("Message role = %v, want %v", messages[0].Role, chat.RoleUser)
 		}
 
-		if m.messages[0].Content != "hello world" {
-			t.Errorf("Message content = %q, want %q", m.messages[0].Content, "hello world")
+		if messages[0].Content != "hello world" {
+			t.Errorf("Message content = %q, want %q", messages[0].Content, "hello world")
 		}
 	}
 
@@ -270,8 +286,8 @@ func TestUpdate_EmptyInput(t *testing.T) {
 	m = newModel.(Model)
 
 	// Should not add message
-	if len(m.messages) != 0 {
-		t.Errorf("After empty input, messages length = %d, want 0", len(m.messages))
+	if m.messages.Len() != 0 {
+		t.Errorf("After empty input, messages length = %d, want 0", m.messages.Len())
 	}
 
 	// Should not show theme selector
@@ -296,8 +312,8 @@ func TestUpdate_AltEnter(t *testing.T) {
 
 	// Should add newline to input (or space, depending on textinput implementation)
 	// The key point is it should NOT send the message
-	if len(m.messages) != 0 {
-		t.Errorf("After Alt+Enter, messages length = %d, want 0 (message should not be sent)", len(m.messages))
+	if m.messages.Len() != 0 {
+		t.Errorf("After Alt+Enter, messages length = %d, want 0 (message should not be sent)", m.messages.Len())
 	}
 
 	// Input value should be modified (either with newline or space added)
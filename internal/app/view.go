@@ -6,10 +6,15 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/diagram"
 	"github.com/mnesler/hauk-tui/internal/logger"
+	"github.com/mnesler/hauk-tui/internal/markdown"
 	"github.com/mnesler/hauk-tui/internal/ui"
 )
 
+// maxThumbnailLines is the tallest inline diagram preview shown in the chat
+const maxThumbnailLines = 24
+
 // View renders the UI
 func (m Model) View() string {
 	if m.width == 0 || m.height == 0 {
@@ -34,6 +39,50 @@ func (m Model) View() string {
 		return overlay
 	}
 
+	// Branch navigator is rendered the same way
+	if m.showBranches {
+		return lipgloss.Place(
+			m.width,
+			m.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			m.renderBranchNavigator(),
+		)
+	}
+
+	// Reverse history search is rendered the same way
+	if m.showHistorySearch {
+		return lipgloss.Place(
+			m.width,
+			m.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			m.renderHistorySearch(),
+		)
+	}
+
+	// Template picker is rendered the same way
+	if m.showTemplates {
+		return lipgloss.Place(
+			m.width,
+			m.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			m.renderTemplatePicker(),
+		)
+	}
+
+	// Docs picker is rendered the same way
+	if m.showDocs {
+		return lipgloss.Place(
+			m.width,
+			m.height,
+			lipgloss.Center,
+			lipgloss.Center,
+			m.renderDocsPicker(),
+		)
+	}
+
 	return m.renderMainView()
 }
 
@@ -42,8 +91,11 @@ func (m Model) renderMainView() string {
 	// Render chat panel (left 50%)
 	chatPanel := m.renderChatPanel()
 
-	// Render log panel (right 50%)
+	// Render the log panel (right 50%), or the diagram in its place
 	logPanel := m.renderLogPanel()
+	if m.showDiagram {
+		logPanel = m.renderDiagramPanel()
+	}
 
 	// Join panels horizontally
 	content := lipgloss.JoinHorizontal(
@@ -63,9 +115,32 @@ func (m Model) renderMainView() string {
 	)
 }
 
+// messageSpan is the range of chat content lines a message occupies
+type messageSpan struct {
+	id         int
+	start, end int // First line and one past the last
+}
+
 // renderChatPanel renders the left panel with chat messages
 func (m Model) renderChatPanel() string {
+	chatContent, _ := m.renderChatContent()
+
+	// Set viewport content
+	m.chatViewport.SetContent(chatContent)
+
+	// Render viewport view
+	viewportView := m.chatViewport.View()
+
+	// Apply panel styling
+	return ui.GetChatPanelStyle(m.chatWidth, m.height-3).
+		Render(viewportView)
+}
+
+// renderChatContent renders the chat header and messages, returning where
+// each message landed so the viewport can scroll to it
+func (m Model) renderChatContent() (string, []messageSpan) {
 	var messages []string
+	var spans []messageSpan
 
 	// Header
 	header := ui.GetHeaderStyle(ui.ActiveTheme.ChatBg).
@@ -73,7 +148,7 @@ func (m Model) renderChatPanel() string {
 	messages = append(messages, header)
 
 	// Check if there are any messages
-	if len(m.messages) == 0 {
+	if m.messages.Len() == 0 {
 		// Show welcome message
 		welcome := ui.GetTextMutedStyle(ui.ActiveTheme.ChatBg).
 			Padding(2).
@@ -81,34 +156,37 @@ func (m Model) renderChatPanel() string {
 		messages = append(messages, welcome)
 	} else {
 		// Render messages
-		for _, msg := range m.messages {
-			rendered := m.renderMessage(msg)
+		line := lipgloss.Height(header)
+		for _, node := range m.messages.Path() {
+			rendered := m.renderMessage(node, node.ID == m.editingID)
 			messages = append(messages, rendered)
+
+			height := lipgloss.Height(rendered)
+			spans = append(spans, messageSpan{id: node.ID, start: line, end: line + height})
+			line += height
 		}
 	}
 
 	// Join all messages
-	chatContent := lipgloss.JoinVertical(
+	return lipgloss.JoinVertical(
 		lipgloss.Left,
 		messages...,
-	)
-
-	// Set viewport content
-	m.chatViewport.SetContent(chatContent)
-
-	// Render viewport view
-	viewportView := m.chatViewport.View()
-
-	// Apply panel styling
-	return ui.GetChatPanelStyle(m.chatWidth, m.height-3).
-		Render(viewportView)
+	), spans
 }
 
-// renderMessage renders a single chat message
-func (m Model) renderMessage(msg chat.Message) string {
+// renderMessage renders a single chat message, marking it if it is being edited
+func (m Model) renderMessage(node *chat.Node, editing bool) string {
+	msg := node.Message
+
 	// Format timestamp
 	timestamp := msg.Timestamp.Format("15:04")
 
+	// Show which branch is displayed when edits or retries created siblings
+	var branch string
+	if index, count := m.messages.Siblings(node.ID); count > 1 {
+		branch = fmt.Sprintf(" (%d/%d)", index+1, count)
+	}
+
 	// Choose style based on role
 	var style lipgloss.Style
 	var prefix string
@@ -116,17 +194,100 @@ func (m Model) renderMessage(msg chat.Message) string {
 	switch msg.Role {
 	case chat.RoleUser:
 		style = ui.GetUserMsgStyle(m.chatWidth - 4)
-		prefix = fmt.Sprintf("[%s] You:", timestamp)
+		prefix = fmt.Sprintf("[%s] You%s:", timestamp, branch)
 	case chat.RoleAgent:
 		style = ui.GetAgentMsgStyle(m.chatWidth - 4)
-		prefix = fmt.Sprintf("[%s] Agent:", timestamp)
+		prefix = fmt.Sprintf("[%s] Agent%s:", timestamp, branch)
+	case chat.RoleSystem:
+		style = ui.GetAgentMsgStyle(m.chatWidth - 4)
+		prefix = fmt.Sprintf("[%s] Hauk%s:", timestamp, branch)
+	}
+
+	// Mark the message being edited; sending branches off next to it
+	if editing {
+		prefix = fmt.Sprintf("[%s] You (editing):", timestamp)
+		style = ui.GetUserMsgStyle(m.chatWidth-5).
+			Border(lipgloss.ThickBorder(), false, false, false, true).
+			BorderForeground(ui.ActiveTheme.AccentUser).
+			BorderBackground(ui.ActiveTheme.ChatBg)
+	}
+
+	// Highlight the message selected in selection mode
+	if m.selecting && node.ID == m.selectedID {
+		style = style.
+			Width(style.GetWidth()-1).
+			Border(lipgloss.ThickBorder(), false, false, false, true).
+			BorderForeground(ui.ActiveTheme.AccentAgent).
+			BorderBackground(ui.ActiveTheme.ChatBg)
+		if m.pendingDelete {
+			prefix += " (press d again to delete)"
+		}
+	}
+
+	// Render content as markdown inside the bubble's padding
+	width := style.GetWidth() - style.GetHorizontalPadding()
+	renderer := markdown.NewRenderer(width)
+
+	// With the thumbnail shown, the mermaid source would just repeat it
+	showThumbnail := msg.HasDiagram() && m.showThumbnails != m.thumbnailToggled[node.ID]
+	if showThumbnail {
+		renderer.Code = func(lang string, lines []string) string {
+			if lang == "mermaid" {
+				return ""
+			}
+			return markdown.RenderCode(lang, lines)
+		}
 	}
 
-	// Render content
-	content := fmt.Sprintf("%s\n%s", prefix, msg.Content)
+	content := fmt.Sprintf("%s\n%s", prefix, renderer.Render(msg.Content))
+	if len(msg.Attachments) > 0 {
+		paths := make([]string, len(msg.Attachments))
+		for i, a := range msg.Attachments {
+			paths[i] = a.Path
+		}
+		content += "\n" + ui.GetTextMutedStyle(ui.ActiveTheme.ChatBg).Render("📎 "+strings.Join(paths, ", "))
+	}
+	if msg.HasDiagram() {
+		content += "\n\n" + m.renderThumbnail(node.ID, msg.Diagram, width, showThumbnail)
+	}
 	return style.Render(content)
 }
 
+// thumbnailKey identifies a rendered preview by message and layout width
+type thumbnailKey struct {
+	id    int
+	width int
+}
+
+// renderThumbnail renders a compact diagram preview, or a collapsed marker.
+// Previews are cached, since laying out a diagram is too slow for every frame.
+func (m Model) renderThumbnail(id int, source string, width int, expanded bool) string {
+	muted := ui.GetTextMutedStyle(ui.ActiveTheme.ChatBg)
+	if !expanded {
+		return muted.Render("▸ diagram hidden (Ctrl+O to show)")
+	}
+
+	key := thumbnailKey{id: id, width: width}
+	if rendered, ok := m.thumbnails[key]; ok {
+		return rendered
+	}
+
+	var rendered string
+	if thumbnail, err := diagram.Thumbnail(source, width, maxThumbnailLines); err != nil {
+		rendered = muted.Render(fmt.Sprintf("▾ diagram preview unavailable: %v", err))
+	} else {
+		rendered = lipgloss.JoinVertical(
+			lipgloss.Left,
+			muted.Render("▾ diagram (Ctrl+O to hide)"),
+			ui.GetTextSecondaryStyle().
+				Background(ui.ActiveTheme.ChatBg).
+				Render(thumbnail),
+		)
+	}
+	m.thumbnails[key] = rendered
+	return rendered
+}
+
 // renderLogPanel renders the right panel with application logs
 func (m Model) renderLogPanel() string {
 	var content []string
@@ -160,55 +321,95 @@ func (m Model) renderLogPanel() string {
 		Render(viewportView)
 }
 
-// renderDiagramPanel renders the right panel with ASCII diagram
-// NOTE: This function is currently disabled in favor of the log panel
-// Keep this code for potential future use
-/*
+// renderDiagramPanel renders the right panel with the current diagram
+// drawn as ASCII, followed by any problems found in its source
 func (m Model) renderDiagramPanel() string {
+	if m.sourceEditor != nil {
+		return m.renderSourceEditor()
+	}
+
 	var content []string
 
-	// Header
-	header := ui.GetHeaderStyle(ui.ActiveTheme.DiagramBg).
-		Render("Diagram Preview")
-	content = append(content, header)
+	// Header names the watched file, if any
+	title := "Diagram Preview"
+	if m.watchPath != "" {
+		title = "Watching " + m.watchPath
+	}
+	width := m.diagramWidth - 6
+	content = append(content, ui.GetHeaderStyle(ui.ActiveTheme.DiagramBg).Render(truncate(title, width-4)))
+	muted := ui.GetTextMutedStyle(ui.ActiveTheme.DiagramBg)
+	problem := lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.AccentCode).
+		Background(ui.ActiveTheme.DiagramBg)
+
+	if m.watchErr != nil {
+		content = append(content, problem.Render(truncate(m.watchErr.Error(), width)))
+	}
+
+	if m.currentDiagram == "" {
+		content = append(content, muted.
+			Padding(2).
+			Render("No diagram yet. Chat with the agent to generate one!"))
+		return ui.GetDiagramPanelStyle(m.diagramWidth, m.height-3).
+			Render(lipgloss.JoinVertical(lipgloss.Left, content...))
+	}
 
-	// Diagram or placeholder
-	var diagramContent string
-	if m.currentDiagram != "" {
-		// Render mermaid to ASCII
-		ascii, err := diagram.Render(m.currentDiagram)
-		if err != nil {
-			diagramContent = fmt.Sprintf("Error rendering diagram: %v", err)
+	// Diagnostics go under the drawing, which gets the rest of the height
+	diags := diagram.Validate(m.currentDiagram)
+	maxLines := m.height - 10 - len(diags)
+	if maxLines < 3 {
+		maxLines = 3
+	}
+
+	ascii, err := diagram.Thumbnail(m.currentDiagram, width, maxLines)
+	if err != nil {
+		content = append(content, "", problem.Render(truncate(err.Error(), width)))
+	} else {
+		base := ui.GetTextSecondaryStyle().Background(ui.ActiveTheme.DiagramBg)
+		if m.picking {
+			mark := base.Reverse(true).Bold(true)
+			ascii = markPicked(ascii, m.pickElements[m.pickCursor], m.pickedOccurrence(), base, mark)
 		} else {
-			diagramContent = ascii
+			ascii = base.Render(ascii)
 		}
-	} else {
-		diagramContent = "No diagram yet. Chat with the agent to generate one!"
+		content = append(content, "", ascii)
 	}
 
-	// Style the diagram content
-	diagramStyled := ui.GetTextSecondaryStyle().
-		Padding(1, 2).
-		Render(diagramContent)
-
-	content = append(content, diagramStyled)
+	if m.picking {
+		content = append(content, "",
+			problem.Render(m.renderPickStatus(width)),
+			muted.Render(pickHelp))
+	}
 
-	// Join content
-	panelContent := lipgloss.JoinVertical(
-		lipgloss.Left,
-		content...,
-	)
+	if len(diags) > 0 {
+		content = append(content, "", muted.Render(fmt.Sprintf("%d problem(s)", len(diags))))
+		for _, d := range diags {
+			content = append(content, problem.Render(truncate(d.String(), width)))
+		}
+	}
 
-	// Apply panel styling
 	return ui.GetDiagramPanelStyle(m.diagramWidth, m.height-3).
-		Render(panelContent)
+		Render(lipgloss.JoinVertical(lipgloss.Left, content...))
 }
-*/
 
 // renderInputBar renders the input bar at the bottom
 func (m Model) renderInputBar() string {
+	// The composer is idle while a message is selected; show the actions instead
+	if m.selecting {
+		return ui.GetInputStyle(m.width).
+			Render(ui.GetTextMutedStyle(ui.ActiveTheme.InputBg).Render(selectionHelp))
+	}
+
 	inputView := m.input.View()
 
+	// Files about to be attached take the place of the top padding so the
+	// bar keeps its height
+	if preview := m.renderAttachmentPreview(); preview != "" {
+		return ui.GetInputStyle(m.width).
+			PaddingTop(0).
+			Render(preview + "\n> " + inputView)
+	}
+
 	return ui.GetInputStyle(m.width).
 		Render("> " + inputView)
 }
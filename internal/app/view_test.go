@@ -0,0 +1,74 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/chat"
+)
+
+// newDiagramMessageModel returns a sized model whose last message carries a diagram
+func newDiagramMessageModel() (Model, *chat.Node) {
+	m := NewModel()
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = newModel.(Model)
+
+	msg := chat.NewMessage(chat.RoleAgent, "Here you go:\n\n```mermaid\ngraph LR\n    Alpha --> Beta\n```")
+	msg.Diagram = "graph LR\n    Alpha --> Beta"
+	node := m.messages.Append(msg)
+	return m, node
+}
+
+func TestRenderMessage_Thumbnail(t *testing.T) {
+	m, node := newDiagramMessageModel()
+
+	out := m.renderMessage(node, false)
+	if !strings.Contains(out, "┌") || !strings.Contains(out, "Alpha") {
+		t.Errorf("renderMessage() should include the rendered diagram, got:\n%s", out)
+	}
+	if strings.Contains(out, "-->") {
+		t.Error("renderMessage() should hide the mermaid source while the thumbnail is shown")
+	}
+}
+
+func TestUpdate_ToggleThumbnails(t *testing.T) {
+	m, node := newDiagramMessageModel()
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = newModel.(Model)
+	if m.showThumbnails {
+		t.Fatal("After Ctrl+O, showThumbnails should be false")
+	}
+
+	out := m.renderMessage(node, false)
+	if strings.Contains(out, "┌") {
+		t.Error("Collapsed message should not include the rendered diagram")
+	}
+	if !strings.Contains(out, "Alpha --> Beta") || !strings.Contains(out, "diagram hidden") {
+		t.Errorf("Collapsed message should show the source and a marker, got:\n%s", out)
+	}
+}
+
+func TestRenderMessage_ThumbnailCached(t *testing.T) {
+	m, node := newDiagramMessageModel()
+	m.renderMessage(node, false)
+
+	// A cached preview is reused rather than laid out again
+	node.Message.Diagram = "graph LR\n    Gamma --> Delta"
+	if out := m.renderMessage(node, false); !strings.Contains(out, "Alpha") {
+		t.Errorf("renderMessage() should reuse the cached preview, got:\n%s", out)
+	}
+
+	newModel, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 40})
+	m = newModel.(Model)
+	if out := m.renderMessage(node, false); !strings.Contains(out, "Gamma") {
+		t.Errorf("After a resize the preview should be laid out again, got:\n%s", out)
+	}
+
+	node.Message.Diagram = "graph LR\n    Alpha --> Beta"
+	m = m.setTheme(m.config.Theme)
+	if out := m.renderMessage(node, false); !strings.Contains(out, "Alpha") {
+		t.Errorf("After a theme change the preview should be laid out again, got:\n%s", out)
+	}
+}
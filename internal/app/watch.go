@@ -0,0 +1,118 @@
+package app
+
+import (
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mnesler/hauk-tui/internal/diagram"
+	"github.com/mnesler/hauk-tui/internal/logger"
+)
+
+// watchInterval is how often hauk watch checks the file for changes
+const watchInterval = 300 * time.Millisecond
+
+// watchedFileMsg reports a check of the watched file
+type watchedFileMsg struct {
+	Path    string
+	Source  string
+	ModTime time.Time
+	Changed bool // Source is new content; otherwise nothing changed
+	Err     error
+}
+
+// OpenWatch starts in watch mode: the mermaid file at path is shown in the
+// diagram pane and reloaded whenever it's saved
+func (m Model) OpenWatch(path string) (Model, error) {
+	msg := checkWatchedFile(path, time.Time{})
+	if msg.Err != nil {
+		return m, msg.Err
+	}
+
+	m.watchPath = path
+	m.showDiagram = true
+	m = m.applyWatchedFile(msg)
+	return m, nil
+}
+
+// watchCmd polls the watched file, or does nothing when not watching
+func (m Model) watchCmd() tea.Cmd {
+	if m.watchPath == "" {
+		return nil
+	}
+	path, since := m.watchPath, m.watchModTime
+	return tea.Tick(watchInterval, func(time.Time) tea.Msg {
+		return checkWatchedFile(path, since)
+	})
+}
+
+// checkWatchedFile reads path if its modification time isn't since
+func checkWatchedFile(path string, since time.Time) watchedFileMsg {
+	msg := watchedFileMsg{Path: path}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		msg.Err = err
+		return msg
+	}
+	if info.ModTime().Equal(since) {
+		return msg
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		msg.Err = err
+		return msg
+	}
+	msg.Source = string(data)
+	msg.ModTime = info.ModTime()
+	msg.Changed = true
+	return msg
+}
+
+// updateWatchedFile applies a check of the watched file and schedules the
+// next one
+func (m Model) updateWatchedFile(msg watchedFileMsg) (Model, tea.Cmd) {
+	// A check started before the file stopped being watched
+	if msg.Path != m.watchPath {
+		return m, nil
+	}
+	return m.applyWatchedFile(msg), m.watchCmd()
+}
+
+// applyWatchedFile shows new content of the watched file. Read errors, e.g.
+// while an editor replaces the file, are shown until the next good read.
+func (m Model) applyWatchedFile(msg watchedFileMsg) Model {
+	if msg.Err != nil {
+		if m.watchErr == nil {
+			logger.Component("watch").Warnf("Failed to read %s: %v", msg.Path, msg.Err)
+		}
+		m.watchErr = msg.Err
+		return m
+	}
+	m.watchErr = nil
+	if !msg.Changed {
+		return m
+	}
+
+	m.watchModTime = msg.ModTime
+	m.currentDiagram = strings.TrimRight(msg.Source, "\r\n")
+
+	// Keep picking among the reloaded diagram's elements
+	if m.picking {
+		m.pickElements = diagram.Elements(m.currentDiagram)
+		if len(m.pickElements) == 0 {
+			m = m.stopPicking()
+		} else if m.pickCursor >= len(m.pickElements) {
+			m.pickCursor = len(m.pickElements) - 1
+		}
+	}
+
+	if diags := diagram.Validate(m.currentDiagram); len(diags) > 0 {
+		logger.Component("watch").Warnf("Reloaded %s: %d problem(s), first %s", msg.Path, len(diags), diags[0])
+	} else {
+		logger.Component("watch").Infof("Reloaded %s", msg.Path)
+	}
+	return m
+}
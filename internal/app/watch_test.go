@@ -0,0 +1,90 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestOpenWatch_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flow.mmd")
+	if err := os.WriteFile(path, []byte("graph TD\n    A --> B\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewModel().OpenWatch(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.showDiagram || m.currentDiagram != "graph TD\n    A --> B" {
+		t.Fatalf("file not shown: show=%v diagram=%q", m.showDiagram, m.currentDiagram)
+	}
+
+	// Nothing changed yet
+	msg := checkWatchedFile(path, m.watchModTime)
+	if msg.Changed || msg.Err != nil {
+		t.Fatalf("unchanged file reported %+v", msg)
+	}
+
+	// A save with a broken edge is reloaded and diagnosed
+	if err := os.WriteFile(path, []byte("graph TD\n    A --> B\n    B -->\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	m, cmd := m.updateWatchedFile(checkWatchedFile(path, m.watchModTime))
+	if cmd == nil {
+		t.Error("next check not scheduled")
+	}
+	if !strings.HasSuffix(m.currentDiagram, "B -->") {
+		t.Errorf("diagram not reloaded: %q", m.currentDiagram)
+	}
+
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	view := updated.(Model).View()
+	for _, want := range []string{"Watching ", "line 3: edge has no target node"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view missing %q", want)
+		}
+	}
+
+	// A failed read keeps the last diagram and shows the error
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = m.updateWatchedFile(checkWatchedFile(path, m.watchModTime))
+	if m.watchErr == nil || !strings.HasSuffix(m.currentDiagram, "B -->") {
+		t.Errorf("read error not kept apart: err=%v diagram=%q", m.watchErr, m.currentDiagram)
+	}
+}
+
+func TestOpenWatch_MissingFile(t *testing.T) {
+	if _, err := NewModel().OpenWatch(filepath.Join(t.TempDir(), "missing.mmd")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestUpdateWatchedFile_IgnoresOtherPaths(t *testing.T) {
+	m := NewModel()
+	m, cmd := m.updateWatchedFile(watchedFileMsg{Path: "old.mmd", Source: "graph TD", Changed: true})
+	if cmd != nil || m.currentDiagram != "" {
+		t.Errorf("check of a file no longer watched was applied: diagram=%q", m.currentDiagram)
+	}
+}
+
+func TestUpdate_CtrlDTogglesDiagramPane(t *testing.T) {
+	m, _ := press(NewModel(), tea.KeyMsg{Type: tea.KeyCtrlD})
+	if !m.showDiagram {
+		t.Fatal("Ctrl+D should show the diagram pane")
+	}
+	m, _ = press(m, tea.KeyMsg{Type: tea.KeyCtrlD})
+	if m.showDiagram {
+		t.Error("Ctrl+D should switch back to the logs")
+	}
+}
@@ -0,0 +1,174 @@
+package attach
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mnesler/hauk-tui/internal/chat"
+)
+
+// Size limits keep prompts within what providers accept
+const (
+	MaxFileSize  = 100 << 10 // Largest single file that can be attached
+	MaxTotalSize = 256 << 10 // Largest combined attachment size per message
+)
+
+// mentionPattern matches @path at the start of the text or after whitespace
+var mentionPattern = regexp.MustCompile(`(?:^|\s)@([^\s@]+)`)
+
+// Info describes a file that would be attached, without reading it
+type Info struct {
+	Path string
+	Size int64
+	Err  error // Why the file can't be attached, if it can't
+}
+
+// Stat checks whether path can be attached
+func Stat(path string) Info {
+	info := Info{Path: path}
+
+	fi, err := os.Stat(expand(path))
+	switch {
+	case err != nil:
+		info.Err = err
+	case fi.IsDir():
+		info.Err = fmt.Errorf("%s is a directory", path)
+	case fi.Size() > MaxFileSize:
+		info.Err = fmt.Errorf("%s is %s, over the %s limit", path, FormatSize(fi.Size()), FormatSize(MaxFileSize))
+	default:
+		info.Size = fi.Size()
+	}
+	return info
+}
+
+// Load reads path for attaching to a message
+func Load(path string) (chat.Attachment, error) {
+	if info := Stat(path); info.Err != nil {
+		return chat.Attachment{}, info.Err
+	}
+
+	data, err := os.ReadFile(expand(path))
+	if err != nil {
+		return chat.Attachment{}, err
+	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return chat.Attachment{}, fmt.Errorf("%s looks like a binary file", path)
+	}
+
+	return chat.Attachment{Path: path, Content: string(data)}, nil
+}
+
+// LoadAll reads every path, enforcing the combined size limit together
+// with the attachments already kept
+func LoadAll(paths []string, kept ...chat.Attachment) ([]chat.Attachment, error) {
+	total := 0
+	for _, a := range kept {
+		total += len(a.Content)
+	}
+
+	var attachments []chat.Attachment
+	for _, path := range paths {
+		a, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		total += len(a.Content)
+		if total > MaxTotalSize {
+			return nil, fmt.Errorf("attachments total over the %s limit", FormatSize(MaxTotalSize))
+		}
+		attachments = append(attachments, a)
+	}
+	return attachments, nil
+}
+
+// Mentions returns the @paths in text that name existing files, in order
+// and without duplicates. Other @words, like handles, are ignored.
+func Mentions(text string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		// Allow trailing punctuation after the path: "look at @main.go."
+		path := strings.TrimRight(match[1], ".,;:!?)\"'")
+		if seen[path] {
+			continue
+		}
+		if fi, err := os.Stat(expand(path)); err != nil || fi.IsDir() {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// Complete returns paths that extend prefix, with directories suffixed by a
+// slash. Hidden entries are only offered once the prefix names them.
+func Complete(prefix string) []string {
+	dir, base := filepath.Split(prefix)
+
+	entries, err := os.ReadDir(expand(dirOrDot(dir)))
+	if err != nil {
+		return nil
+	}
+
+	var matches []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasPrefix(name, base) || strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		if e.IsDir() {
+			name += "/"
+		}
+		matches = append(matches, dir+name)
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// CommonPrefix returns the longest prefix shared by all of values
+func CommonPrefix(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	prefix := values[0]
+	for _, v := range values[1:] {
+		for !strings.HasPrefix(v, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+// FormatSize renders a byte count for display
+func FormatSize(n int64) string {
+	if n < 1<<10 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+}
+
+// expand resolves a leading ~ to the home directory
+func expand(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// dirOrDot returns dir, or the current directory if it is empty
+func dirOrDot(dir string) string {
+	if dir == "" {
+		return "."
+	}
+	return dir
+}
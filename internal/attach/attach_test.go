@@ -0,0 +1,143 @@
+package attach
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mnesler/hauk-tui/internal/chat"
+)
+
+// writeFiles creates files relative to the current directory
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFiles(t, map[string]string{
+		"main.go":    "package main\n",
+		"image.png":  "\x89PNG\x00\x00",
+		"big.txt":    strings.Repeat("x", MaxFileSize+1),
+		"dir/a.yaml": "a: 1\n",
+	})
+
+	a, err := Load("main.go")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if a.Path != "main.go" || a.Content != "package main\n" {
+		t.Errorf("Load() = %+v", a)
+	}
+
+	for _, path := range []string{"missing.go", "dir", "image.png", "big.txt"} {
+		if _, err := Load(path); err == nil {
+			t.Errorf("Load(%q) should fail", path)
+		}
+	}
+}
+
+func TestLoadAll_TotalLimit(t *testing.T) {
+	t.Chdir(t.TempDir())
+	files := make(map[string]string)
+	var paths []string
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		files[name] = strings.Repeat("x", MaxFileSize)
+		paths = append(paths, name)
+	}
+	writeFiles(t, files)
+
+	if _, err := LoadAll(paths[:2]); err != nil {
+		t.Errorf("LoadAll() of two files error = %v", err)
+	}
+	if _, err := LoadAll(paths); err == nil {
+		t.Error("LoadAll() over the total limit should fail")
+	}
+
+	// Attachments kept from an edited message count towards the limit
+	kept := chat.Attachment{Path: "old.txt", Content: strings.Repeat("x", MaxFileSize)}
+	if _, err := LoadAll(paths[:2], kept); err == nil {
+		t.Error("LoadAll() over the total limit with kept attachments should fail")
+	}
+}
+
+func TestMentions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFiles(t, map[string]string{
+		"internal/app/update.go": "package app\n",
+		"schema.sql":             "create table t (id int);\n",
+	})
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "diagram the flow in @internal/app/update.go", want: []string{"internal/app/update.go"}},
+		{text: "@schema.sql and @internal/app/update.go.", want: []string{"schema.sql", "internal/app/update.go"}},
+		{text: "@schema.sql twice @schema.sql", want: []string{"schema.sql"}},
+		{text: "ask @someone or mail me@schema.sql", want: nil},
+		{text: "directories like @internal are skipped", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := Mentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Mentions(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeFiles(t, map[string]string{
+		"internal/app/update.go": "",
+		"internal/app/view.go":   "",
+		"internal/chat/tree.go":  "",
+		".hidden":                "",
+		"go.mod":                 "",
+	})
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{prefix: "in", want: []string{"internal/"}},
+		{prefix: "internal/", want: []string{"internal/app/", "internal/chat/"}},
+		{prefix: "internal/app/u", want: []string{"internal/app/update.go"}},
+		{prefix: "", want: []string{"go.mod", "internal/"}},
+		{prefix: ".h", want: []string{".hidden"}},
+		{prefix: "nope/", want: nil},
+	}
+
+	for _, tt := range tests {
+		if got := Complete(tt.prefix); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Complete(%q) = %v, want %v", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   string
+	}{
+		{values: nil, want: ""},
+		{values: []string{"internal/app/"}, want: "internal/app/"},
+		{values: []string{"internal/app/", "internal/attach/"}, want: "internal/a"},
+		{values: []string{"a", "b"}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := CommonPrefix(tt.values); got != tt.want {
+			t.Errorf("CommonPrefix(%v) = %q, want %q", tt.values, got, tt.want)
+		}
+	}
+}
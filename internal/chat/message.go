@@ -1,6 +1,11 @@
 package chat
 
-import "time"
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
 
 // Role represents who sent a message
 type Role string
@@ -8,14 +13,25 @@ type Role string
 const (
 	RoleUser  Role = "user"
 	RoleAgent Role = "agent"
+
+	// RoleSystem marks messages hauk adds itself, like generated diagrams
+	RoleSystem Role = "system"
 )
 
 // Message represents a single chat message
 type Message struct {
-	Role      Role
-	Content   string
-	Timestamp time.Time
-	Diagram   string // Optional extracted mermaid code
+	Role      Role      `yaml:"role"`
+	Content   string    `yaml:"content"`
+	Timestamp time.Time `yaml:"timestamp"`
+	Diagram   string    `yaml:"diagram,omitempty"` // Optional extracted mermaid code
+
+	Attachments []Attachment `yaml:"attachments,omitempty"` // Files sent along with the message
+}
+
+// Attachment is a local file whose contents are sent with a message
+type Attachment struct {
+	Path    string `yaml:"path"`
+	Content string `yaml:"content"`
 }
 
 // NewMessage creates a new message
@@ -31,3 +47,32 @@ func NewMessage(role Role, content string) Message {
 func (m Message) HasDiagram() bool {
 	return m.Diagram != ""
 }
+
+// PromptContent returns the text sent to the provider: the message followed
+// by the contents of any attached files
+func (m Message) PromptContent() string {
+	if len(m.Attachments) == 0 {
+		return m.Content
+	}
+
+	var b strings.Builder
+	b.WriteString(m.Content)
+	for _, a := range m.Attachments {
+		fmt.Fprintf(&b, "\n\nFile: %s\n````%s\n%s\n````", a.Path, a.Lang(), strings.TrimRight(a.Content, "\n"))
+	}
+	return b.String()
+}
+
+// Lang returns the fence language for the attachment, based on its extension
+func (a Attachment) Lang() string {
+	ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(a.Path)), ".")
+	switch ext {
+	case "mmd":
+		return "mermaid"
+	case "yml":
+		return "yaml"
+	case "md":
+		return "markdown"
+	}
+	return ext
+}
@@ -0,0 +1,54 @@
+package chat
+
+import "testing"
+
+func TestNewMessage(t *testing.T) {
+	msg := NewMessage(RoleUser, "hello")
+
+	if msg.Role != RoleUser {
+		t.Errorf("Role = %v, want %v", msg.Role, RoleUser)
+	}
+	if msg.Content != "hello" {
+		t.Errorf("Content = %q, want %q", msg.Content, "hello")
+	}
+	if msg.Timestamp.IsZero() {
+		t.Error("Timestamp should be set")
+	}
+	if msg.HasDiagram() {
+		t.Error("HasDiagram() = true, want false")
+	}
+}
+
+func TestMessage_PromptContent(t *testing.T) {
+	msg := NewMessage(RoleUser, "diagram this")
+	if msg.PromptContent() != "diagram this" {
+		t.Errorf("PromptContent() without attachments = %q", msg.PromptContent())
+	}
+
+	msg.Attachments = []Attachment{
+		{Path: "internal/app/update.go", Content: "package app\n"},
+		{Path: "schema.yml", Content: "a: 1"},
+	}
+	want := "diagram this\n\nFile: internal/app/update.go\n````go\npackage app\n````" +
+		"\n\nFile: schema.yml\n````yaml\na: 1\n````"
+	if got := msg.PromptContent(); got != want {
+		t.Errorf("PromptContent() = %q, want %q", got, want)
+	}
+}
+
+func TestAttachment_Lang(t *testing.T) {
+	tests := map[string]string{
+		"main.go":         "go",
+		"flow.mmd":        "mermaid",
+		"deploy.YML":      "yaml",
+		"README.md":       "markdown",
+		"Makefile":        "",
+		"dir.d/config":    "",
+		"schema/init.sql": "sql",
+	}
+	for path, want := range tests {
+		if got := (Attachment{Path: path}).Lang(); got != want {
+			t.Errorf("Lang(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,289 @@
+package chat
+
+import "gopkg.in/yaml.v3"
+
+// Node is a message in the conversation tree
+type Node struct {
+	ID       int     `yaml:"id"`
+	Message  Message `yaml:"message"`
+	Children []*Node `yaml:"children,omitempty"`
+	Active   int     `yaml:"active"` // Index of the child on the active branch
+
+	parent *Node
+}
+
+// Parent returns the node this one replies to, or nil for the root
+func (n *Node) Parent() *Node {
+	return n.parent
+}
+
+// ActiveChild returns the child on the active branch, or nil for a leaf
+func (n *Node) ActiveChild() *Node {
+	if n.Active < 0 || n.Active >= len(n.Children) {
+		return nil
+	}
+	return n.Children[n.Active]
+}
+
+// Tree is a conversation where edits and regenerations add branches
+// instead of overwriting history. The active branch is the conversation
+// currently shown and sent to the agent.
+type Tree struct {
+	root   *Node
+	nextID int
+}
+
+// treeFile is the on-disk representation of a tree
+type treeFile struct {
+	NextID int   `yaml:"next_id"`
+	Root   *Node `yaml:"root"`
+}
+
+// NewTree creates an empty conversation
+func NewTree() *Tree {
+	return &Tree{
+		root:   &Node{},
+		nextID: 1,
+	}
+}
+
+// Root returns the sentinel node all first messages hang off; it carries no message
+func (t *Tree) Root() *Node {
+	return t.root
+}
+
+// Path returns the nodes on the active branch, oldest first
+func (t *Tree) Path() []*Node {
+	var path []*Node
+	for n := t.root.ActiveChild(); n != nil; n = n.ActiveChild() {
+		path = append(path, n)
+	}
+	return path
+}
+
+// Messages returns the messages on the active branch, oldest first
+func (t *Tree) Messages() []Message {
+	path := t.Path()
+	messages := make([]Message, len(path))
+	for i, n := range path {
+		messages[i] = n.Message
+	}
+	return messages
+}
+
+// Len returns the number of messages on the active branch
+func (t *Tree) Len() int {
+	return len(t.Path())
+}
+
+// Last returns the newest node on the active branch, or nil if the conversation is empty
+func (t *Tree) Last() *Node {
+	path := t.Path()
+	if len(path) == 0 {
+		return nil
+	}
+	return path[len(path)-1]
+}
+
+// LastWithRole returns the newest node on the active branch with the given role, or nil
+func (t *Tree) LastWithRole(role Role) *Node {
+	path := t.Path()
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].Message.Role == role {
+			return path[i]
+		}
+	}
+	return nil
+}
+
+// Append adds msg to the end of the active branch
+func (t *Tree) Append(msg Message) *Node {
+	parent := t.root
+	if last := t.Last(); last != nil {
+		parent = last
+	}
+	return t.addChild(parent, msg)
+}
+
+// Branch adds msg as a new sibling of the node with the given ID and makes
+// it active, leaving the original branch intact. Returns nil if id is unknown.
+func (t *Tree) Branch(id int, msg Message) *Node {
+	n := t.Find(id)
+	if n == nil || n.parent == nil {
+		return nil
+	}
+	return t.addChild(n.parent, msg)
+}
+
+// Sibling adds msg as a new sibling of the node with the given ID without
+// changing the active branch. Returns nil if id is unknown.
+func (t *Tree) Sibling(id int, msg Message) *Node {
+	n := t.Find(id)
+	if n == nil || n.parent == nil {
+		return nil
+	}
+	active := n.parent.Active
+	sibling := t.addChild(n.parent, msg)
+	n.parent.Active = active
+	return sibling
+}
+
+// Find returns the node with the given ID, or nil
+func (t *Tree) Find(id int) *Node {
+	var found *Node
+	t.Walk(func(n *Node, _ int) bool {
+		if n.ID == id {
+			found = n
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// Walk visits every message node depth-first, passing its depth (0 for
+// first messages). Returning false from fn stops the walk.
+func (t *Tree) Walk(fn func(n *Node, depth int) bool) {
+	var walk func(n *Node, depth int) bool
+	walk = func(n *Node, depth int) bool {
+		for _, child := range n.Children {
+			if !fn(child, depth) || !walk(child, depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+	walk(t.root, 0)
+}
+
+// Siblings returns the position of the node among its siblings and how many there are
+func (t *Tree) Siblings(id int) (index, count int) {
+	n := t.Find(id)
+	if n == nil || n.parent == nil {
+		return 0, 1
+	}
+	for i, sibling := range n.parent.Children {
+		if sibling == n {
+			index = i
+		}
+	}
+	return index, len(n.parent.Children)
+}
+
+// SelectSibling switches the active branch to the sibling delta positions
+// away from the node with the given ID. Returns the newly active node, or
+// nil if there is no such sibling.
+func (t *Tree) SelectSibling(id, delta int) *Node {
+	n := t.Find(id)
+	if n == nil || n.parent == nil {
+		return nil
+	}
+
+	index, count := t.Siblings(id)
+	target := index + delta
+	if target < 0 || target >= count {
+		return nil
+	}
+
+	n.parent.Active = target
+	return n.parent.Children[target]
+}
+
+// Activate makes the branch leading to the node with the given ID active
+func (t *Tree) Activate(id int) bool {
+	n := t.Find(id)
+	if n == nil {
+		return false
+	}
+
+	for child := n; child.parent != nil; child = child.parent {
+		for i, sibling := range child.parent.Children {
+			if sibling == child {
+				child.parent.Active = i
+			}
+		}
+	}
+	return true
+}
+
+// Remove deletes the node with the given ID along with every reply below
+// it. The sibling before it, if any, becomes active in its place. Returns
+// the number of messages removed.
+func (t *Tree) Remove(id int) int {
+	n := t.Find(id)
+	if n == nil || n.parent == nil {
+		return 0
+	}
+
+	parent := n.parent
+	for i, sibling := range parent.Children {
+		if sibling != n {
+			continue
+		}
+		parent.Children = append(parent.Children[:i], parent.Children[i+1:]...)
+		if parent.Active > i || parent.Active == i && i > 0 {
+			parent.Active--
+		}
+		break
+	}
+	n.parent = nil
+
+	count := 0
+	var walk func(n *Node)
+	walk = func(n *Node) {
+		count++
+		for _, child := range n.Children {
+			walk(child)
+		}
+	}
+	walk(n)
+	return count
+}
+
+// addChild adds msg under parent and makes it the active child
+func (t *Tree) addChild(parent *Node, msg Message) *Node {
+	n := &Node{
+		ID:      t.nextID,
+		Message: msg,
+		parent:  parent,
+	}
+	t.nextID++
+
+	parent.Children = append(parent.Children, n)
+	parent.Active = len(parent.Children) - 1
+	return n
+}
+
+// MarshalYAML implements yaml.Marshaler
+func (t *Tree) MarshalYAML() (interface{}, error) {
+	return treeFile{NextID: t.nextID, Root: t.root}, nil
+}
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (t *Tree) UnmarshalYAML(value *yaml.Node) error {
+	var f treeFile
+	if err := value.Decode(&f); err != nil {
+		return err
+	}
+
+	if f.Root == nil {
+		f.Root = &Node{}
+	}
+	t.root = f.Root
+	t.nextID = f.NextID
+
+	// Restore parent links and make sure new IDs don't collide
+	var link func(n *Node)
+	link = func(n *Node) {
+		if n.ID >= t.nextID {
+			t.nextID = n.ID + 1
+		}
+		for _, child := range n.Children {
+			child.parent = n
+			link(child)
+		}
+	}
+	link(t.root)
+
+	return nil
+}
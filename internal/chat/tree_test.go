@@ -0,0 +1,192 @@
+package chat
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// newTestTree builds user → agent → user → agent on a single branch
+func newTestTree() (*Tree, []*Node) {
+	tree := NewTree()
+	nodes := []*Node{
+		tree.Append(NewMessage(RoleUser, "draw it")),
+		tree.Append(NewMessage(RoleAgent, "first answer")),
+		tree.Append(NewMessage(RoleUser, "make it left-to-right")),
+		tree.Append(NewMessage(RoleAgent, "second answer")),
+	}
+	return tree, nodes
+}
+
+func TestTree_Append(t *testing.T) {
+	tree, nodes := newTestTree()
+
+	if tree.Len() != 4 {
+		t.Fatalf("Len() = %d, want 4", tree.Len())
+	}
+	if tree.Last() != nodes[3] {
+		t.Error("Last() should be the newest node")
+	}
+	if nodes[1].Parent() != nodes[0] {
+		t.Error("Appended node should reply to the previous one")
+	}
+	if got := tree.LastWithRole(RoleUser); got != nodes[2] {
+		t.Errorf("LastWithRole(user) = %v, want node %d", got, nodes[2].ID)
+	}
+
+	empty := NewTree()
+	if empty.Last() != nil || empty.LastWithRole(RoleAgent) != nil || empty.Len() != 0 {
+		t.Error("Empty tree should have no nodes")
+	}
+}
+
+func TestTree_Branch(t *testing.T) {
+	tree, nodes := newTestTree()
+
+	edited := tree.Branch(nodes[2].ID, NewMessage(RoleUser, "make it top-down"))
+	if edited == nil {
+		t.Fatal("Branch() = nil")
+	}
+
+	// The active branch now ends at the edit
+	messages := tree.Messages()
+	if len(messages) != 3 || messages[2].Content != "make it top-down" {
+		t.Fatalf("Messages() after Branch = %+v", messages)
+	}
+
+	if index, count := tree.Siblings(edited.ID); index != 1 || count != 2 {
+		t.Errorf("Siblings() = %d, %d, want 1, 2", index, count)
+	}
+
+	// Switching back restores the original continuation
+	if tree.SelectSibling(edited.ID, -1) != nodes[2] {
+		t.Fatal("SelectSibling(-1) should return the original message")
+	}
+	if tree.Len() != 4 || tree.Last() != nodes[3] {
+		t.Error("Original branch should be intact after switching back")
+	}
+
+	if tree.SelectSibling(nodes[2].ID, -1) != nil {
+		t.Error("SelectSibling past the first sibling should return nil")
+	}
+	if tree.Branch(999, NewMessage(RoleUser, "x")) != nil {
+		t.Error("Branch() of unknown ID should return nil")
+	}
+}
+
+func TestTree_Sibling(t *testing.T) {
+	tree, nodes := newTestTree()
+
+	sibling := tree.Sibling(nodes[1].ID, NewMessage(RoleAgent, "another answer"))
+	if sibling == nil {
+		t.Fatal("Sibling() = nil")
+	}
+	if tree.Len() != 4 || tree.Last() != nodes[3] {
+		t.Error("Sibling() should leave the active branch alone")
+	}
+	if index, count := tree.Siblings(sibling.ID); index != 1 || count != 2 {
+		t.Errorf("Siblings() = %d, %d, want 1, 2", index, count)
+	}
+	if tree.Sibling(999, NewMessage(RoleAgent, "x")) != nil {
+		t.Error("Sibling() of unknown ID should return nil")
+	}
+}
+
+func TestTree_Activate(t *testing.T) {
+	tree, nodes := newTestTree()
+	edited := tree.Branch(nodes[0].ID, NewMessage(RoleUser, "draw something else"))
+
+	if tree.Len() != 1 || tree.Last() != edited {
+		t.Fatal("Branching the first message should start a new conversation path")
+	}
+
+	if !tree.Activate(nodes[3].ID) {
+		t.Fatal("Activate() = false")
+	}
+	if tree.Len() != 4 || tree.Last() != nodes[3] {
+		t.Error("Activate() should make the path to the node active")
+	}
+	if tree.Activate(999) {
+		t.Error("Activate() of unknown ID should return false")
+	}
+}
+
+func TestTree_Remove(t *testing.T) {
+	tree, nodes := newTestTree()
+	alternative := tree.Branch(nodes[1].ID, NewMessage(RoleAgent, "alternative"))
+
+	// Removing the active alternative falls back to the original answer
+	if removed := tree.Remove(alternative.ID); removed != 1 {
+		t.Errorf("Remove() = %d, want 1", removed)
+	}
+	if tree.Len() != 4 || tree.Last() != nodes[3] {
+		t.Errorf("After removing the alternative, Len() = %d, want the original branch", tree.Len())
+	}
+
+	// Removing a message drops every reply after it
+	if removed := tree.Remove(nodes[2].ID); removed != 2 {
+		t.Errorf("Remove() = %d, want 2", removed)
+	}
+	if tree.Len() != 2 || tree.Last() != nodes[1] {
+		t.Errorf("After removing a user message, Len() = %d, want 2", tree.Len())
+	}
+	if tree.Find(nodes[3].ID) != nil {
+		t.Error("Replies to a removed message should be gone")
+	}
+
+	if tree.Remove(999) != 0 {
+		t.Error("Remove() of unknown ID should remove nothing")
+	}
+
+	tree.Remove(nodes[0].ID)
+	if tree.Len() != 0 {
+		t.Errorf("Removing the first message should empty the conversation, Len() = %d", tree.Len())
+	}
+}
+
+func TestTree_Walk(t *testing.T) {
+	tree, nodes := newTestTree()
+	tree.Branch(nodes[1].ID, NewMessage(RoleAgent, "alternative"))
+
+	var depths []int
+	tree.Walk(func(n *Node, depth int) bool {
+		depths = append(depths, depth)
+		return true
+	})
+
+	want := []int{0, 1, 2, 3, 1}
+	if len(depths) != len(want) {
+		t.Fatalf("Walk() visited depths %v, want %v", depths, want)
+	}
+	for i := range want {
+		if depths[i] != want[i] {
+			t.Errorf("Walk() depths = %v, want %v", depths, want)
+			break
+		}
+	}
+}
+
+func TestTree_YAMLRoundTrip(t *testing.T) {
+	tree, nodes := newTestTree()
+	tree.Branch(nodes[3].ID, NewMessage(RoleAgent, "regenerated"))
+
+	data, err := yaml.Marshal(tree)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	loaded := NewTree()
+	if err := yaml.Unmarshal(data, loaded); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if loaded.Len() != 4 || loaded.Last().Message.Content != "regenerated" {
+		t.Fatalf("Loaded active branch = %+v", loaded.Messages())
+	}
+	if loaded.Last().Parent() == nil || loaded.Last().Parent().ID != nodes[2].ID {
+		t.Error("Parent links should be restored after loading")
+	}
+	if next := loaded.Append(NewMessage(RoleUser, "again")); next.ID <= loaded.Last().Parent().ID {
+		t.Errorf("Appended ID %d should not collide with loaded IDs", next.ID)
+	}
+}
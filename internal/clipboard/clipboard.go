@@ -0,0 +1,181 @@
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// MaxOSC52Size is the largest payload sent over OSC 52. Many terminals
+// silently drop longer sequences, so bigger copies use the native clipboard.
+const MaxOSC52Size = 74994
+
+// Method is the mechanism a copy went through
+type Method string
+
+const (
+	MethodOSC52  Method = "OSC 52"
+	MethodNative Method = "system clipboard"
+)
+
+// Mode picks which mechanisms a copy may use
+type Mode string
+
+const (
+	ModeAuto   Mode = "auto"   // System clipboard, then OSC 52
+	ModeOSC52  Mode = "osc52"  // OSC 52 only, e.g. over SSH
+	ModeNative Mode = "native" // System clipboard only
+)
+
+// ErrUnavailable is returned when no clipboard mechanism could be used
+var ErrUnavailable = errors.New("no clipboard available")
+
+// Preferred is the mode New uses; set from the config at startup
+var Preferred = ModeAuto
+
+// ParseMode reads a mode from the config, where empty means auto
+func ParseMode(s string) (Mode, error) {
+	switch mode := Mode(strings.ToLower(strings.TrimSpace(s))); mode {
+	case "":
+		return ModeAuto, nil
+	case ModeAuto, ModeOSC52, ModeNative:
+		return mode, nil
+	}
+	return ModeAuto, fmt.Errorf("unknown clipboard mode %q, use auto, osc52 or native", s)
+}
+
+// Terminal is the program's output. Bubble Tea draws each frame with a
+// single write, so serializing writes keeps OSC 52 sequences from landing
+// inside a frame.
+type Terminal struct {
+	*os.File
+	mu sync.Mutex
+}
+
+// Output wraps stdout; pass it to tea.WithOutput so copies share the
+// renderer's writer
+var Output = &Terminal{File: os.Stdout}
+
+// Write writes p in one piece
+func (t *Terminal) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.File.Write(p)
+}
+
+// WriteString writes s in one piece
+func (t *Terminal) WriteString(s string) (int, error) {
+	return t.Write([]byte(s))
+}
+
+// Copier puts text on the clipboard
+type Copier struct {
+	Mode     Mode                    // Which mechanisms to try, auto when empty
+	Terminal io.Writer               // Where OSC 52 sequences are written, nil to skip OSC 52
+	Getenv   func(string) string     // Environment lookup for tmux/screen detection
+	Native   func(text string) error // Native clipboard writer, nil if unsupported
+}
+
+// New returns a copier for the preferred mode that writes OSC 52 through
+// Output and uses the native clipboard when there is one
+func New() *Copier {
+	c := &Copier{Mode: Preferred, Getenv: os.Getenv}
+	if isTerminal(Output.File) {
+		c.Terminal = Output
+	}
+	if !clipboard.Unsupported {
+		c.Native = clipboard.WriteAll
+	}
+	return c
+}
+
+// Copy puts text on the clipboard with the default copier
+func Copy(text string) (Method, error) {
+	return New().Copy(text)
+}
+
+// Copy puts text on the clipboard and reports how. In auto mode the native
+// clipboard is tried first, since a terminal that ignores OSC 52 can't be
+// detected; OSC 52 covers SSH sessions and machines without one.
+func (c *Copier) Copy(text string) (Method, error) {
+	switch c.Mode {
+	case ModeOSC52:
+		return c.copyOSC52(text)
+	case ModeNative:
+		return c.copyNative(text)
+	}
+
+	method, nativeErr := c.copyNative(text)
+	if nativeErr == nil {
+		return method, nil
+	}
+	method, err := c.copyOSC52(text)
+	if err == nil {
+		return method, nil
+	}
+	if errors.Is(nativeErr, ErrUnavailable) {
+		return "", err
+	}
+	if errors.Is(err, ErrUnavailable) {
+		return "", nativeErr
+	}
+	return "", fmt.Errorf("%w; %w", nativeErr, err)
+}
+
+// copyNative writes to the system clipboard
+func (c *Copier) copyNative(text string) (Method, error) {
+	if c.Native == nil {
+		return "", ErrUnavailable
+	}
+	if err := c.Native(text); err != nil {
+		return "", fmt.Errorf("system clipboard: %w", err)
+	}
+	return MethodNative, nil
+}
+
+// copyOSC52 writes the OSC 52 sequence to the terminal
+func (c *Copier) copyOSC52(text string) (Method, error) {
+	if c.Terminal == nil {
+		return "", ErrUnavailable
+	}
+	if len(text) > MaxOSC52Size {
+		return "", fmt.Errorf("OSC 52: %d bytes is over the %d byte limit", len(text), MaxOSC52Size)
+	}
+	if _, err := c.sequence(text).WriteTo(c.Terminal); err != nil {
+		return "", fmt.Errorf("OSC 52: %w", err)
+	}
+	return MethodOSC52, nil
+}
+
+// sequence builds the OSC 52 sequence, wrapped for tmux or screen when
+// running inside one
+func (c *Copier) sequence(text string) osc52.Sequence {
+	seq := osc52.New(text)
+
+	getenv := c.Getenv
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+	switch {
+	case getenv("TMUX") != "":
+		seq = seq.Tmux()
+	case strings.HasPrefix(getenv("TERM"), "screen"):
+		seq = seq.Screen()
+	}
+	return seq
+}
+
+// isTerminal reports whether f is a character device
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
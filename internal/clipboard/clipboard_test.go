@@ -0,0 +1,192 @@
+package clipboard
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// env returns a Getenv func backed by a map
+func env(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+// failingWriter is a terminal that rejects writes
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("closed") }
+
+func TestCopyOSC52(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantPrefix string
+	}{
+		{name: "plain terminal", env: nil, wantPrefix: "\x1b]52;c;"},
+		{name: "inside tmux", env: map[string]string{"TMUX": "/tmp/tmux-1000/default,1,0"}, wantPrefix: "\x1bPtmux;\x1b\x1b]52;c;"},
+		{name: "inside screen", env: map[string]string{"TERM": "screen-256color"}, wantPrefix: "\x1bP\x1b]52;c;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			c := &Copier{Terminal: &out, Getenv: env(tt.env)}
+
+			method, err := c.Copy("graph TD\n  A --> B")
+			if err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+			if method != MethodOSC52 {
+				t.Errorf("method = %q, want %q", method, MethodOSC52)
+			}
+			if !strings.HasPrefix(out.String(), tt.wantPrefix) {
+				t.Errorf("sequence = %q, want prefix %q", out.String(), tt.wantPrefix)
+			}
+
+			encoded := base64.StdEncoding.EncodeToString([]byte("graph TD\n  A --> B"))
+			if !strings.Contains(out.String(), encoded) {
+				t.Errorf("sequence %q does not contain base64 payload %q", out.String(), encoded)
+			}
+		})
+	}
+}
+
+func TestCopyPrefersNative(t *testing.T) {
+	var copied string
+	native := func(text string) error {
+		copied = text
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		terminal bool
+		failing  bool
+		text     string
+	}{
+		{name: "no terminal", text: "hello"},
+		{name: "terminal write fails", terminal: true, failing: true, text: "hello"},
+		{name: "too large for OSC 52", terminal: true, text: strings.Repeat("x", MaxOSC52Size+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			copied = ""
+			c := &Copier{Getenv: env(nil), Native: native}
+			var out bytes.Buffer
+			if tt.terminal {
+				c.Terminal = &out
+			}
+			if tt.failing {
+				c.Terminal = failingWriter{}
+			}
+
+			method, err := c.Copy(tt.text)
+			if err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+			if method != MethodNative {
+				t.Errorf("method = %q, want %q", method, MethodNative)
+			}
+			if copied != tt.text {
+				t.Errorf("native clipboard got %d bytes, want %d", len(copied), len(tt.text))
+			}
+			if out.Len() != 0 {
+				t.Errorf("unexpected OSC 52 output %q", out.String())
+			}
+		})
+	}
+}
+
+func TestCopyErrors(t *testing.T) {
+	t.Run("nothing available", func(t *testing.T) {
+		c := &Copier{Getenv: env(nil)}
+		if _, err := c.Copy("hello"); !errors.Is(err, ErrUnavailable) {
+			t.Errorf("Copy() error = %v, want ErrUnavailable", err)
+		}
+	})
+
+	t.Run("native fails", func(t *testing.T) {
+		c := &Copier{Getenv: env(nil), Native: func(string) error { return errors.New("no xclip") }}
+		_, err := c.Copy("hello")
+		if err == nil || !strings.Contains(err.Error(), "no xclip") {
+			t.Errorf("Copy() error = %v, want native error", err)
+		}
+	})
+
+	t.Run("terminal fails without native", func(t *testing.T) {
+		c := &Copier{Terminal: failingWriter{}, Getenv: env(nil)}
+		_, err := c.Copy("hello")
+		if err == nil || !strings.Contains(err.Error(), "OSC 52") {
+			t.Errorf("Copy() error = %v, want OSC 52 error", err)
+		}
+	})
+}
+
+func TestCopyFallsBackToOSC52(t *testing.T) {
+	var out bytes.Buffer
+	c := &Copier{Terminal: &out, Getenv: env(nil), Native: func(string) error { return errors.New("no display") }}
+
+	method, err := c.Copy("hello")
+	if err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if method != MethodOSC52 || out.Len() == 0 {
+		t.Errorf("method = %q with %d bytes written, want OSC 52", method, out.Len())
+	}
+}
+
+func TestCopyModes(t *testing.T) {
+	var copied string
+	native := func(text string) error {
+		copied = text
+		return nil
+	}
+
+	t.Run("osc52 skips the system clipboard", func(t *testing.T) {
+		copied = ""
+		var out bytes.Buffer
+		c := &Copier{Mode: ModeOSC52, Terminal: &out, Getenv: env(nil), Native: native}
+		method, err := c.Copy("hello")
+		if err != nil || method != MethodOSC52 || copied != "" {
+			t.Errorf("Copy() = %q, %v with native copy %q, want OSC 52 only", method, err, copied)
+		}
+	})
+
+	t.Run("osc52 reports oversized text", func(t *testing.T) {
+		var out bytes.Buffer
+		c := &Copier{Mode: ModeOSC52, Terminal: &out, Getenv: env(nil), Native: native}
+		if _, err := c.Copy(strings.Repeat("x", MaxOSC52Size+1)); err == nil {
+			t.Error("Copy() error = nil, want the size limit")
+		}
+	})
+
+	t.Run("native skips OSC 52", func(t *testing.T) {
+		var out bytes.Buffer
+		c := &Copier{Mode: ModeNative, Terminal: &out, Getenv: env(nil)}
+		if _, err := c.Copy("hello"); !errors.Is(err, ErrUnavailable) || out.Len() != 0 {
+			t.Errorf("Copy() error = %v with %d bytes written, want ErrUnavailable and nothing written", err, out.Len())
+		}
+	})
+}
+
+func TestParseMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Mode
+		wantErr bool
+	}{
+		{in: "", want: ModeAuto},
+		{in: "OSC52", want: ModeOSC52},
+		{in: "native", want: ModeNative},
+		{in: "xclip", want: ModeAuto, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMode(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseMode(%q) = %q, %v, want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
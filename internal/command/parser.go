@@ -1,6 +1,9 @@
 package command
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+)
 
 // CommandType represents a slash command
 type CommandType int
@@ -8,6 +11,16 @@ type CommandType int
 const (
 	CommandNone CommandType = iota
 	CommandTheme
+	CommandRetry
+	CommandBranches
+	CommandSession
+	CommandCopy
+	CommandFile
+	CommandGraph
+	CommandImport
+	CommandDocs
+	CommandEdit
+	CommandNew
 	// Future commands can be added here
 )
 
@@ -21,7 +34,7 @@ func ParseCommand(input string) (CommandType, []string) {
 	}
 
 	// Split command and arguments
-	parts := strings.Fields(input)
+	parts := splitArgs(input)
 	if len(parts) == 0 {
 		return CommandNone, nil
 	}
@@ -34,7 +47,58 @@ func ParseCommand(input string) (CommandType, []string) {
 	switch cmd {
 	case "theme":
 		return CommandTheme, args
+	case "retry":
+		return CommandRetry, args
+	case "branches":
+		return CommandBranches, args
+	case "session":
+		return CommandSession, args
+	case "copy":
+		return CommandCopy, args
+	case "file":
+		return CommandFile, args
+	case "graph":
+		return CommandGraph, args
+	case "import":
+		return CommandImport, args
+	case "docs":
+		return CommandDocs, args
+	case "edit":
+		return CommandEdit, args
+	case "new":
+		return CommandNew, args
 	default:
 		return CommandNone, nil
 	}
 }
+
+// splitArgs splits input at whitespace, keeping text in double quotes
+// together, e.g. a path with spaces. An unclosed quote runs to the end.
+func splitArgs(input string) []string {
+	var (
+		parts  []string
+		part   strings.Builder
+		inPart bool
+		quoted bool
+	)
+	for _, r := range input {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inPart = true
+		case unicode.IsSpace(r) && !quoted:
+			if inPart {
+				parts = append(parts, part.String())
+				part.Reset()
+				inPart = false
+			}
+		default:
+			part.WriteRune(r)
+			inPart = true
+		}
+	}
+	if inPart {
+		parts = append(parts, part.String())
+	}
+	return parts
+}
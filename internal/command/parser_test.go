@@ -53,6 +53,66 @@ func TestParseCommand(t *testing.T) {
 			wantCmd:  CommandTheme,
 			wantArgs: []string{"dark", "nord"},
 		},
+		{
+			name:     "retry command",
+			input:    "/retry",
+			wantCmd:  CommandRetry,
+			wantArgs: nil,
+		},
+		{
+			name:     "branches command",
+			input:    "/branches",
+			wantCmd:  CommandBranches,
+			wantArgs: nil,
+		},
+		{
+			name:     "session command with args",
+			input:    "/session save demo",
+			wantCmd:  CommandSession,
+			wantArgs: []string{"save", "demo"},
+		},
+		{
+			name:     "copy command with target",
+			input:    "/copy message",
+			wantCmd:  CommandCopy,
+			wantArgs: []string{"message"},
+		},
+		{
+			name:     "file command with paths",
+			input:    "/file go.mod internal/app/update.go",
+			wantCmd:  CommandFile,
+			wantArgs: []string{"go.mod", "internal/app/update.go"},
+		},
+		{
+			name:     "graph command with kind and dir",
+			input:    "/graph packages ./internal",
+			wantCmd:  CommandGraph,
+			wantArgs: []string{"packages", "./internal"},
+		},
+		{
+			name:     "import command with kind and file",
+			input:    "/import sql schema.sql",
+			wantCmd:  CommandImport,
+			wantArgs: []string{"sql", "schema.sql"},
+		},
+		{
+			name:     "docs save command",
+			input:    "/docs save",
+			wantCmd:  CommandDocs,
+			wantArgs: []string{"save"},
+		},
+		{
+			name:     "edit command",
+			input:    "/edit",
+			wantCmd:  CommandEdit,
+			wantArgs: []string{},
+		},
+		{
+			name:     "new command with template",
+			input:    "/new c4-context",
+			wantCmd:  CommandNew,
+			wantArgs: []string{"c4-context"},
+		},
 		{
 			name:     "invalid command",
 			input:    "/invalid",
@@ -77,6 +137,24 @@ func TestParseCommand(t *testing.T) {
 			wantCmd:  CommandNone,
 			wantArgs: nil,
 		},
+		{
+			name:     "file command with a quoted path",
+			input:    `/file "My Docs/spec.md" notes.md`,
+			wantCmd:  CommandFile,
+			wantArgs: []string{"My Docs/spec.md", "notes.md"},
+		},
+		{
+			name:     "file command with an unclosed quote",
+			input:    `/file "My Docs/design notes.md`,
+			wantCmd:  CommandFile,
+			wantArgs: []string{"My Docs/design notes.md"},
+		},
+		{
+			name:     "file command keeps apostrophes",
+			input:    "/file Sam's.md",
+			wantCmd:  CommandFile,
+			wantArgs: []string{"Sam's.md"},
+		},
 		{
 			name:     "only whitespace",
 			input:    "   ",
@@ -10,7 +10,8 @@ import (
 
 // Config holds the application configuration
 type Config struct {
-	Theme string `yaml:"theme"`
+	Theme     string `yaml:"theme"`
+	Clipboard string `yaml:"clipboard,omitempty"` // auto (default), osc52 or native
 }
 
 // DefaultConfig returns a new Config with default values
@@ -20,13 +21,22 @@ func DefaultConfig() *Config {
 	}
 }
 
-// ConfigPath returns the path to the config file
-func ConfigPath() (string, error) {
+// Dir returns the directory holding the config file and other persisted state
+func Dir() (string, error) {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
-	return filepath.Join(home, ".config", "hauk", "config.yaml"), nil
+	return filepath.Join(home, ".config", "hauk"), nil
+}
+
+// ConfigPath returns the path to the config file
+func ConfigPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.yaml"), nil
 }
 
 // Load reads the config from disk, or returns default config if file doesn't exist
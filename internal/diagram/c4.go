@@ -0,0 +1,397 @@
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// C4Level is the zoom level of a C4 diagram
+type C4Level string
+
+const (
+	C4ContextLevel   C4Level = "C4Context"
+	C4ContainerLevel C4Level = "C4Container"
+	C4ComponentLevel C4Level = "C4Component"
+)
+
+// C4Element is a person, software system, container or component
+type C4Element struct {
+	Kind        string // Person, System, SystemDb, Container, ContainerQueue, Component...
+	ID          string
+	Name        string
+	Technology  string // Containers and components only
+	Description string
+	External    bool
+	Line        int // 1-based source line, 0 when built in code
+}
+
+// C4Boundary groups elements, e.g. the containers of one system
+type C4Boundary struct {
+	Kind       string // Boundary, Enterprise_Boundary, System_Boundary or Container_Boundary
+	ID         string
+	Name       string
+	Elements   []*C4Element
+	Boundaries []*C4Boundary
+	Line       int // 1-based source line, 0 when built in code
+}
+
+// C4Rel is a relationship between two elements
+type C4Rel struct {
+	From, To    string // Element IDs
+	Label       string
+	Technology  string
+	Bidirection bool
+}
+
+// C4Model is a C4 context, container or component diagram
+type C4Model struct {
+	Level C4Level
+	Title string
+	C4Boundary
+	Rels []C4Rel
+}
+
+// c4ElementArgs are the positional arguments of each element kind after
+// the ID and name: technology is only taken by containers and components
+var c4ElementArgs = map[string][]string{
+	"Person":         {"description"},
+	"System":         {"description"},
+	"SystemDb":       {"description"},
+	"SystemQueue":    {"description"},
+	"Container":      {"technology", "description"},
+	"ContainerDb":    {"technology", "description"},
+	"ContainerQueue": {"technology", "description"},
+	"Component":      {"technology", "description"},
+	"ComponentDb":    {"technology", "description"},
+	"ComponentQueue": {"technology", "description"},
+}
+
+// c4BoundaryKinds are the boundary macros
+var c4BoundaryKinds = map[string]bool{
+	"Boundary": true, "Enterprise_Boundary": true, "System_Boundary": true, "Container_Boundary": true,
+}
+
+// c4RelKinds are the relationship macros; direction hints only affect layout
+var c4RelKinds = map[string]bool{
+	"Rel": true, "BiRel": true, "Rel_Back": true,
+	"Rel_U": true, "Rel_Up": true, "Rel_D": true, "Rel_Down": true,
+	"Rel_L": true, "Rel_Left": true, "Rel_R": true, "Rel_Right": true,
+}
+
+// c4Ignored are styling and layout statements that don't change the model
+var c4Ignored = map[string]bool{
+	"UpdateElementStyle": true, "UpdateRelStyle": true, "UpdateBoundaryStyle": true,
+	"UpdateLayoutConfig": true, "AddElementTag": true, "AddRelTag": true,
+}
+
+// c4Statement matches Macro(args) with an optional opening brace
+var c4Statement = regexp.MustCompile(`^([A-Za-z_]+)\s*\((.*)\)\s*(\{)?$`)
+
+// IsC4 reports whether mermaid source is a C4 diagram
+func IsC4(source string) bool {
+	lines := strings.Split(source, "\n")
+	start := diagramLine(lines)
+	return start >= 0 && strings.HasPrefix(strings.TrimSpace(lines[start]), "C4")
+}
+
+// ParseC4 reads mermaid C4Context, C4Container or C4Component source. The
+// first problem found is returned as the error.
+func ParseC4(source string) (*C4Model, error) {
+	model, diags := parseC4(strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n"))
+	if len(diags) > 0 {
+		return nil, fmt.Errorf("%s", diags[0])
+	}
+	return model, nil
+}
+
+// parseC4 builds the model, collecting every problem found
+func parseC4(lines []string) (*C4Model, []Diagnostic) {
+	var diags []Diagnostic
+	report := func(line int, format string, args ...any) {
+		diags = append(diags, Diagnostic{Line: line, Message: fmt.Sprintf(format, args...)})
+	}
+
+	start := diagramLine(lines)
+	if start < 0 {
+		return nil, []Diagnostic{{Message: "empty diagram"}}
+	}
+	model := &C4Model{Level: C4Level(firstField(strings.TrimSpace(lines[start])))}
+	switch model.Level {
+	case C4ContextLevel, C4ContainerLevel, C4ComponentLevel:
+	default:
+		return nil, []Diagnostic{{Line: start + 1, Message: fmt.Sprintf("%s isn't supported, use C4Context, C4Container or C4Component", model.Level)}}
+	}
+
+	ids := make(map[string]int) // Element or boundary ID to the line declaring it
+	declare := func(id string, line int) {
+		if first, ok := ids[id]; ok {
+			report(line, "%s is already declared on line %d", id, first)
+			return
+		}
+		ids[id] = line
+	}
+
+	stack := []*C4Boundary{&model.C4Boundary}
+	type relLine struct {
+		rel  C4Rel
+		line int
+	}
+	var rels []relLine
+
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		switch {
+		case line == "" || strings.HasPrefix(line, "%%"):
+			continue
+		case strings.HasPrefix(line, "title "):
+			model.Title = strings.TrimSpace(strings.TrimPrefix(line, "title "))
+			continue
+		case line == "}":
+			if len(stack) == 1 {
+				report(i+1, "} without an open boundary")
+			} else {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+
+		m := c4Statement.FindStringSubmatch(line)
+		if m == nil {
+			report(i+1, "expected Macro(args), e.g. System(id, \"Name\")")
+			continue
+		}
+		macro, args, opens := m[1], c4Args(m[2]), m[3] != ""
+		kind, external := strings.TrimSuffix(macro, "_Ext"), strings.HasSuffix(macro, "_Ext")
+		parent := stack[len(stack)-1]
+
+		switch {
+		case c4ElementArgs[kind] != nil:
+			if len(args) < 2 {
+				report(i+1, "%s needs an ID and a name", macro)
+				continue
+			}
+			e := &C4Element{Kind: kind, ID: args[0], Name: args[1], External: external, Line: i + 1}
+			for j, field := range c4ElementArgs[kind] {
+				if len(args) > j+2 {
+					if field == "technology" {
+						e.Technology = args[j+2]
+					} else {
+						e.Description = args[j+2]
+					}
+				}
+			}
+			declare(e.ID, i+1)
+			parent.Elements = append(parent.Elements, e)
+
+		case c4BoundaryKinds[macro]:
+			if len(args) < 2 {
+				report(i+1, "%s needs an ID and a name", macro)
+				continue
+			}
+			if !opens {
+				report(i+1, "%s must open a block with {", macro)
+				continue
+			}
+			b := &C4Boundary{Kind: macro, ID: args[0], Name: args[1], Line: i + 1}
+			declare(b.ID, i+1)
+			parent.Boundaries = append(parent.Boundaries, b)
+			stack = append(stack, b)
+
+		case c4RelKinds[macro]:
+			if len(args) < 3 {
+				report(i+1, "%s needs from, to and a label", macro)
+				continue
+			}
+			rel := C4Rel{From: args[0], To: args[1], Label: args[2], Bidirection: macro == "BiRel"}
+			if macro == "Rel_Back" {
+				rel.From, rel.To = rel.To, rel.From
+			}
+			if len(args) > 3 {
+				rel.Technology = args[3]
+			}
+			rels = append(rels, relLine{rel, i + 1})
+
+		case c4Ignored[macro]:
+
+		default:
+			report(i+1, "unknown C4 statement %s", macro)
+		}
+	}
+
+	if len(stack) > 1 {
+		open := stack[len(stack)-1]
+		report(open.Line, "boundary %s is never closed with }", open.ID)
+	}
+
+	// Relationships may point at elements declared after them
+	for _, r := range rels {
+		for _, id := range []string{r.rel.From, r.rel.To} {
+			if _, ok := ids[id]; !ok {
+				report(r.line, "relationship refers to undeclared %s", id)
+			}
+		}
+		model.Rels = append(model.Rels, r.rel)
+	}
+
+	return model, diags
+}
+
+// c4Args splits macro arguments on commas outside quotes and unquotes
+// them. Named arguments like $tags="x" are dropped.
+func c4Args(s string) []string {
+	var args []string
+	var current strings.Builder
+	inQuote := false
+	flush := func() {
+		arg := strings.TrimSpace(current.String())
+		current.Reset()
+		if !strings.HasPrefix(arg, "$") {
+			args = append(args, strings.Trim(arg, `"`))
+		}
+	}
+
+	for _, r := range s {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			current.WriteRune(r)
+		case r == ',' && !inQuote:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if strings.TrimSpace(current.String()) != "" || len(args) > 0 {
+		flush()
+	}
+	return args
+}
+
+// Mermaid renders the model as mermaid C4 source
+func (m *C4Model) Mermaid() string {
+	lines := []string{string(m.Level)}
+	if m.Title != "" {
+		lines = append(lines, "    title "+m.Title)
+	}
+	lines = append(lines, m.C4Boundary.mermaidBody("    ")...)
+
+	if len(m.Rels) > 0 {
+		lines = append(lines, "")
+	}
+	for _, r := range m.Rels {
+		macro := "Rel"
+		if r.Bidirection {
+			macro = "BiRel"
+		}
+		args := []string{r.From, r.To, c4Quote(r.Label)}
+		if r.Technology != "" {
+			args = append(args, c4Quote(r.Technology))
+		}
+		lines = append(lines, fmt.Sprintf("    %s(%s)", macro, strings.Join(args, ", ")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// mermaidBody renders a boundary's elements and nested boundaries
+func (b *C4Boundary) mermaidBody(indent string) []string {
+	var lines []string
+	for _, e := range b.Elements {
+		macro := e.Kind
+		if e.External {
+			macro += "_Ext"
+		}
+		args := []string{e.ID, c4Quote(e.Name)}
+		if len(c4ElementArgs[e.Kind]) == 2 {
+			args = append(args, c4Quote(e.Technology))
+		}
+		if e.Description != "" {
+			args = append(args, c4Quote(e.Description))
+		}
+		lines = append(lines, fmt.Sprintf("%s%s(%s)", indent, macro, strings.Join(args, ", ")))
+	}
+	for _, nested := range b.Boundaries {
+		lines = append(lines, fmt.Sprintf("%s%s(%s, %s) {", indent, nested.Kind, nested.ID, c4Quote(nested.Name)))
+		lines = append(lines, nested.mermaidBody(indent+"    ")...)
+		lines = append(lines, indent+"}")
+	}
+	return lines
+}
+
+// c4Quote quotes a C4 macro argument
+func c4Quote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "'") + `"`
+}
+
+// Flowchart renders the model as a plain flowchart the terminal renderer
+// can draw: boundaries become subgraphs, and each element is labelled with
+// its kind and technology, e.g. "API [Container: Go]"
+func (m *C4Model) Flowchart() string {
+	lines := []string{"flowchart TD"}
+	lines = append(lines, m.C4Boundary.flowchartBody("    ")...)
+
+	for _, r := range m.Rels {
+		// The terminal renderer only draws one-way arrows
+		label := r.Label
+		if r.Bidirection {
+			label += " (both ways)"
+		}
+		if r.Technology != "" {
+			label += " [" + r.Technology + "]"
+		}
+		lines = append(lines, fmt.Sprintf("    %s -->|%s| %s", NodeID(r.From), QuoteLabel(label), NodeID(r.To)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// flowchartBody renders a boundary's elements and nested boundaries as
+// flowchart nodes and subgraphs
+func (b *C4Boundary) flowchartBody(indent string) []string {
+	var lines []string
+	for _, e := range b.Elements {
+		open, closing := shapeDelimiters(e.shape())
+		lines = append(lines, fmt.Sprintf("%s%s%s%s%s", indent, NodeID(e.ID), open, QuoteLabel(e.flowchartLabel()), closing))
+	}
+	for _, nested := range b.Boundaries {
+		lines = append(lines, fmt.Sprintf("%ssubgraph %s [%s]", indent, NodeID(nested.ID), QuoteLabel(nested.Name)))
+		lines = append(lines, nested.flowchartBody(indent+"    ")...)
+		lines = append(lines, indent+"end")
+	}
+	return lines
+}
+
+// shape picks the flowchart shape for an element kind
+func (e *C4Element) shape() Shape {
+	switch {
+	case e.Kind == "Person":
+		return ShapeRound
+	case strings.HasSuffix(e.Kind, "Db"):
+		return ShapeDatabase
+	case strings.HasSuffix(e.Kind, "Queue"):
+		return ShapeStadium
+	}
+	return ShapeBox
+}
+
+// flowchartLabel is the element's name with its kind, technology and
+// whether it's external, e.g. "Payments [External System]"
+func (e *C4Element) flowchartLabel() string {
+	kind := strings.TrimSuffix(strings.TrimSuffix(e.Kind, "Db"), "Queue")
+	if e.External {
+		kind = "External " + kind
+	}
+	if e.Technology != "" {
+		kind += ": " + e.Technology
+	}
+	return e.Name + " [" + kind + "]"
+}
+
+// C4Guidance tells the agent how to write C4 diagrams this package and
+// mermaid both accept
+const C4Guidance = `For software architecture, use C4 diagrams:
+- Start with C4Context (people and systems), C4Container (the applications and data stores inside one system) or C4Component (the parts of one container), then an optional title line.
+- Declare elements one per line: Person(id, "Name", "Description"), System(id, "Name", "Description"), SystemDb and SystemQueue likewise, and Container(id, "Name", "Technology", "Description") and Component(id, "Name", "Technology", "Description") with their Db and Queue variants.
+- Add _Ext for anything outside the system being described, e.g. System_Ext(stripe, "Stripe", "Card payments").
+- Group elements in Boundary, Enterprise_Boundary, System_Boundary or Container_Boundary(id, "Name") { ... }, closing each with } on its own line.
+- Connect elements with Rel(from, to, "Label", "Technology") or BiRel. Every ID a Rel uses must be declared, and IDs must be unique.
+- Don't mix flowchart syntax like --> or subgraph into C4 diagrams.`
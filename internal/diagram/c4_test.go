@@ -0,0 +1,136 @@
+package diagram
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const c4Sample = `C4Container
+    title Containers of the Online Shop
+    Person(customer, "Customer", "Buys products")
+    System_Boundary(shop, "Online Shop") {
+        Container(web, "Web App", "React", "Storefront")
+        ContainerDb(db, "Database", "Postgres")
+    }
+    System_Ext(payments, "Payments")
+    Rel(customer, web, "Orders", "HTTPS")
+    BiRel(web, db, "Reads and writes")
+    Rel_Back(payments, web, "Charges", $tags="slow")
+    UpdateRelStyle(customer, web, $textColor="red")`
+
+func TestParseC4(t *testing.T) {
+	model, err := ParseC4(c4Sample)
+	if err != nil {
+		t.Fatalf("ParseC4: %v", err)
+	}
+	if model.Level != C4ContainerLevel || model.Title != "Containers of the Online Shop" {
+		t.Errorf("level %q, title %q", model.Level, model.Title)
+	}
+	if len(model.Elements) != 2 || len(model.Boundaries) != 1 {
+		t.Fatalf("got %d elements and %d boundaries at the top level", len(model.Elements), len(model.Boundaries))
+	}
+	if ext := model.Elements[1]; ext.Kind != "System" || !ext.External || ext.Line != 8 {
+		t.Errorf("external system = %+v", ext)
+	}
+	want := &C4Element{Kind: "ContainerDb", ID: "db", Name: "Database", Technology: "Postgres", Line: 6}
+	if got := model.Boundaries[0].Elements[1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("database = %+v, want %+v", got, want)
+	}
+
+	wantRels := []C4Rel{
+		{From: "customer", To: "web", Label: "Orders", Technology: "HTTPS"},
+		{From: "web", To: "db", Label: "Reads and writes", Bidirection: true},
+		{From: "web", To: "payments", Label: "Charges"},
+	}
+	if !reflect.DeepEqual(model.Rels, wantRels) {
+		t.Errorf("rels = %+v, want %+v", model.Rels, wantRels)
+	}
+}
+
+func TestC4Model_Mermaid(t *testing.T) {
+	model, err := ParseC4(c4Sample)
+	if err != nil {
+		t.Fatalf("ParseC4: %v", err)
+	}
+
+	want := `C4Container
+    title Containers of the Online Shop
+    Person(customer, "Customer", "Buys products")
+    System_Ext(payments, "Payments")
+    System_Boundary(shop, "Online Shop") {
+        Container(web, "Web App", "React", "Storefront")
+        ContainerDb(db, "Database", "Postgres")
+    }
+
+    Rel(customer, web, "Orders", "HTTPS")
+    BiRel(web, db, "Reads and writes")
+    Rel(web, payments, "Charges")`
+	got := model.Mermaid()
+	if got != want {
+		t.Errorf("Mermaid() =\n%s\nwant\n%s", got, want)
+	}
+
+	// The output reads back as the same model
+	again, err := ParseC4(got)
+	if err != nil {
+		t.Fatalf("ParseC4(Mermaid()): %v", err)
+	}
+	if again.Mermaid() != got {
+		t.Errorf("round trip changed the source:\n%s", again.Mermaid())
+	}
+}
+
+func TestC4Model_Flowchart(t *testing.T) {
+	model, err := ParseC4(c4Sample)
+	if err != nil {
+		t.Fatalf("ParseC4: %v", err)
+	}
+
+	want := `flowchart TD
+    customer("Customer [Person]")
+    payments["Payments [External System]"]
+    subgraph shop ["Online Shop"]
+        web["Web App [Container: React]"]
+        db[("Database [Container: Postgres]")]
+    end
+    customer -->|"Orders [HTTPS]"| web
+    web -->|"Reads and writes (both ways)"| db
+    web -->|"Charges"| payments`
+	got := model.Flowchart()
+	if got != want {
+		t.Errorf("Flowchart() =\n%s\nwant\n%s", got, want)
+	}
+	if diags := Validate(got); len(diags) > 0 {
+		t.Errorf("Flowchart() has problems: %v", diags)
+	}
+}
+
+func TestRender_C4(t *testing.T) {
+	out, err := Render(c4Sample)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	for _, want := range []string{"Customer [Person]", "Online", "Database [Container: Postgres]", "Orders [HTTPS]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("render is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestC4Args(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: `a, "Name"`, want: []string{"a", "Name"}},
+		{in: `a, "Name, with comma", "Go"`, want: []string{"a", "Name, with comma", "Go"}},
+		{in: `a, b, "Uses", $tags="x"`, want: []string{"a", "b", "Uses"}},
+		{in: ``, want: nil},
+	}
+	for _, tt := range tests {
+		if got := c4Args(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("c4Args(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
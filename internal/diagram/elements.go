@@ -0,0 +1,205 @@
+package diagram
+
+import (
+	"regexp"
+	"strings"
+)
+
+// ElementKind is what a diagram element is
+type ElementKind int
+
+const (
+	ElementNode ElementKind = iota
+	ElementEdge
+	ElementParticipant
+	ElementMessage
+)
+
+// String names the kind for references, e.g. "node"
+func (k ElementKind) String() string {
+	switch k {
+	case ElementEdge:
+		return "edge"
+	case ElementParticipant:
+		return "participant"
+	case ElementMessage:
+		return "message"
+	}
+	return "node"
+}
+
+// Element is a node, edge, participant or message that can be pointed at
+type Element struct {
+	Kind   ElementKind
+	ID     string    // Node or participant ID; empty for edges and messages
+	Label  string    // Text as drawn: a node's label or an edge's label
+	Source string    // As written, e.g. B{Is it working?} or A -->|Yes| B
+	Line   int       // 1-based line it first appears on
+	Ends   [2]string // Labels of an edge's or message's endpoints
+}
+
+// Reference names the element for a prompt, e.g. "node B{Is it working?}"
+func (e Element) Reference() string {
+	return e.Kind.String() + " " + e.Source
+}
+
+var (
+	// bareNodeID matches a node referenced by ID alone
+	bareNodeID = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_\-]*$`)
+
+	// edgeLabel matches the inline label of an edge operator, e.g. -->|Yes|
+	edgeLabel = regexp.MustCompile(`\|([^|]*)\|`)
+
+	// participantLine matches participant and actor declarations
+	participantLine = regexp.MustCompile(`^(participant|actor)\s+(\S+)(?:\s+as\s+(.+))?$`)
+
+	// messageLine matches a sequence diagram message, e.g. Alice->>Bob: hi
+	messageLine = regexp.MustCompile(`^([^\s:>-]+)\s*(-->>|->>|-->|->|--x|-x|--\)|-\))\s*[+-]?([^\s:]+)\s*:\s*(.*)$`)
+)
+
+// Elements lists the nodes and edges of a flowchart, or the participants
+// and messages of a sequence diagram, in the order they appear. Other
+// diagram types have none.
+func Elements(source string) []Element {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	start := diagramLine(lines)
+	if start < 0 {
+		return nil
+	}
+
+	switch firstField(strings.TrimSpace(lines[start])) {
+	case "flowchart", "graph":
+		return flowchartElements(lines, start)
+	case "sequenceDiagram":
+		return sequenceElements(lines, start)
+	}
+	return nil
+}
+
+// flowchartElements lists each node once, as it's declared with its shape
+// if it ever is, then each edge
+func flowchartElements(lines []string, start int) []Element {
+	// First pass: learn each node's shaped declaration
+	declared := make(map[string]string)
+	labels := make(map[string]string)
+	for _, line := range lines[start+1:] {
+		for _, match := range shapedNode.FindAllStringSubmatch(line, -1) {
+			if _, ok := declared[match[1]]; !ok {
+				declared[match[1]] = match[0]
+				labels[match[1]] = unquoteLabel(match[3])
+			}
+		}
+	}
+
+	var nodes, edges []Element
+	seen := make(map[string]bool)
+	addNode := func(id string, line int) {
+		if seen[id] {
+			return
+		}
+		seen[id] = true
+		node := Element{Kind: ElementNode, ID: id, Label: id, Source: id, Line: line}
+		if src, ok := declared[id]; ok {
+			node.Source, node.Label = src, labels[id]
+		}
+		nodes = append(nodes, node)
+	}
+
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(classSuffix.ReplaceAllString(lines[i], ""))
+		if line == "" || strings.HasPrefix(line, "%%") || isDirective(line) ||
+			line == "end" || strings.HasPrefix(line, "subgraph ") {
+			continue
+		}
+
+		// Split the line into node segments and the operators between them
+		var ids, ops []string
+		last := 0
+		for _, loc := range edgeOperator.FindAllStringIndex(line, -1) {
+			ids = append(ids, segmentID(line[last:loc[0]]))
+			ops = append(ops, strings.TrimSpace(line[loc[0]:loc[1]]))
+			last = loc[1]
+		}
+		ids = append(ids, segmentID(line[last:]))
+
+		for _, id := range ids {
+			if id != "" {
+				addNode(id, i+1)
+			}
+		}
+		for j, op := range ops {
+			from, to := ids[j], ids[j+1]
+			if from == "" || to == "" {
+				continue
+			}
+			edge := Element{Kind: ElementEdge, Source: from + " " + op + " " + to, Line: i + 1}
+			if m := edgeLabel.FindStringSubmatch(op); m != nil {
+				edge.Label = unquoteLabel(m[1])
+			}
+			edge.Ends = [2]string{nodeLabel(from, labels), nodeLabel(to, labels)}
+			edges = append(edges, edge)
+		}
+	}
+
+	return append(nodes, edges...)
+}
+
+// segmentID returns the ID of the node a segment between edge operators
+// names, or "" if it isn't a single node
+func segmentID(segment string) string {
+	segment = strings.TrimSpace(segment)
+	if m := shapedNode.FindStringSubmatchIndex(segment); m != nil && m[0] == 0 && m[1] == len(segment) {
+		return segment[m[2]:m[3]]
+	}
+	if bareNodeID.MatchString(segment) {
+		return segment
+	}
+	return ""
+}
+
+// nodeLabel returns a node's label, or its ID when it has none
+func nodeLabel(id string, labels map[string]string) string {
+	if label, ok := labels[id]; ok {
+		return label
+	}
+	return id
+}
+
+// sequenceElements lists the participants, declared or implied by
+// messages, then each message
+func sequenceElements(lines []string, start int) []Element {
+	var participants, messages []Element
+	names := make(map[string]string)
+	addParticipant := func(id, alias, source string, line int) {
+		if _, ok := names[id]; ok {
+			return
+		}
+		label := id
+		if alias != "" {
+			label = strings.TrimSpace(alias)
+		}
+		names[id] = label
+		participants = append(participants, Element{Kind: ElementParticipant, ID: id, Label: label, Source: source, Line: line})
+	}
+
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if m := participantLine.FindStringSubmatch(line); m != nil {
+			addParticipant(m[2], m[3], strings.TrimSpace(line[len(m[1]):]), i+1)
+			continue
+		}
+		if m := messageLine.FindStringSubmatch(line); m != nil {
+			addParticipant(m[1], "", m[1], i+1)
+			addParticipant(m[3], "", m[3], i+1)
+			messages = append(messages, Element{
+				Kind:   ElementMessage,
+				Label:  strings.TrimSpace(m[4]),
+				Source: line,
+				Line:   i + 1,
+				Ends:   [2]string{names[m[1]], names[m[3]]},
+			})
+		}
+	}
+
+	return append(participants, messages...)
+}
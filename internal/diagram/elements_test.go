@@ -0,0 +1,68 @@
+package diagram
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestElements(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string // Reference, label and ends of each element
+	}{
+		{
+			name: "flowchart",
+			in:   "graph TD\n    A[Start] --> B{Is it working?}\n    B -->|\"Yes\"| C[Great!]\n    B -->|No| D\n    D --> B\n    style A fill:#f9f",
+			want: []string{
+				"node A[Start] | Start | ",
+				"node B{Is it working?} | Is it working? | ",
+				"node C[Great!] | Great! | ",
+				"node D | D | ",
+				"edge A --> B |  | Start → Is it working?",
+				`edge B -->|"Yes"| C | Yes | Is it working? → Great!`,
+				"edge B -->|No| D | No | Is it working? → D",
+				"edge D --> B |  | D → Is it working?",
+			},
+		},
+		{
+			name: "shape declared after use",
+			in:   "flowchart LR\n    api --> db\n    subgraph data\n        db[(Postgres)]:::store\n    end",
+			want: []string{
+				"node api | api | ",
+				"node db[(Postgres)] | Postgres | ",
+				"edge api --> db |  | api → Postgres",
+			},
+		},
+		{
+			name: "sequence",
+			in:   "sequenceDiagram\n    participant A as Alice\n    A->>+Bob: Hello\n    Bob-->>-A: Hi back",
+			want: []string{
+				"participant A as Alice | Alice | ",
+				"participant Bob | Bob | ",
+				"message A->>+Bob: Hello | Hello | Alice → Bob",
+				"message Bob-->>-A: Hi back | Hi back | Bob → Alice",
+			},
+		},
+		{
+			name: "other diagrams",
+			in:   "erDiagram\n    A ||--o{ B : has",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, e := range Elements(tt.in) {
+				ends := ""
+				if e.Ends[0] != "" {
+					ends = e.Ends[0] + " → " + e.Ends[1]
+				}
+				got = append(got, e.Reference()+" | "+e.Label+" | "+ends)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Elements() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
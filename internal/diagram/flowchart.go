@@ -0,0 +1,130 @@
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Shape is how a flowchart node is drawn
+type Shape int
+
+const (
+	ShapeBox      Shape = iota // [label]
+	ShapeRound                 // (label)
+	ShapeDecision              // {label}
+	ShapeDatabase              // [(label)]
+	ShapeStadium               // ([label])
+)
+
+// unsafeID matches characters mermaid doesn't allow in node IDs
+var unsafeID = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// Flowchart builds mermaid flowchart source. Nodes are addressed by
+// arbitrary keys (package paths, resource names) and get safe IDs.
+type Flowchart struct {
+	direction string
+	ids       map[string]string // Key to node ID
+	used      map[string]bool   // Node IDs taken
+	nodes     []string          // Declarations in insertion order
+	edges     []string
+	edgeSet   map[string]bool
+}
+
+// NewFlowchart creates an empty flowchart; direction is TD, LR, BT or RL
+func NewFlowchart(direction string) *Flowchart {
+	return &Flowchart{
+		direction: direction,
+		ids:       make(map[string]string),
+		used:      make(map[string]bool),
+		edgeSet:   make(map[string]bool),
+	}
+}
+
+// Node declares a node for key with a label and shape, returning its ID.
+// Declaring the same key again returns the existing ID.
+func (f *Flowchart) Node(key, label string, shape Shape) string {
+	if id, ok := f.ids[key]; ok {
+		return id
+	}
+
+	id := NodeID(key)
+	for i := 2; f.used[id]; i++ {
+		id = fmt.Sprintf("%s_%d", NodeID(key), i)
+	}
+	f.ids[key] = id
+	f.used[id] = true
+
+	open, closing := shapeDelimiters(shape)
+	f.nodes = append(f.nodes, fmt.Sprintf("%s%s%s%s", id, open, QuoteLabel(label), closing))
+	return id
+}
+
+// Edge links the nodes for two keys, declaring them as boxes labelled with
+// the key if needed. Duplicate edges are ignored.
+func (f *Flowchart) Edge(from, to, label string) {
+	fromID := f.Node(from, from, ShapeBox)
+	toID := f.Node(to, to, ShapeBox)
+
+	edge := fmt.Sprintf("%s --> %s", fromID, toID)
+	if label != "" {
+		edge = fmt.Sprintf("%s -->|%s| %s", fromID, QuoteLabel(label), toID)
+	}
+	if f.edgeSet[edge] {
+		return
+	}
+	f.edgeSet[edge] = true
+	f.edges = append(f.edges, edge)
+}
+
+// Empty reports whether the flowchart has no nodes
+func (f *Flowchart) Empty() bool {
+	return len(f.nodes) == 0
+}
+
+// String renders the flowchart as mermaid source
+func (f *Flowchart) String() string {
+	lines := []string{"graph " + f.direction}
+	for _, n := range f.nodes {
+		lines = append(lines, "    "+n)
+	}
+	for _, e := range f.edges {
+		lines = append(lines, "    "+e)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// NodeID turns arbitrary text into a mermaid-safe node ID
+func NodeID(key string) string {
+	id := strings.Trim(unsafeID.ReplaceAllString(key, "_"), "_")
+	switch {
+	case id == "":
+		return "node"
+	case id == "end" || id == "graph" || id == "subgraph":
+		// Reserved words break the parser
+		return id + "_"
+	case id[0] >= '0' && id[0] <= '9':
+		return "n" + id
+	}
+	return id
+}
+
+// QuoteLabel quotes a label so punctuation in it doesn't end the node
+func QuoteLabel(label string) string {
+	return `"` + strings.ReplaceAll(label, `"`, "#quot;") + `"`
+}
+
+// shapeDelimiters returns the brackets that draw shape
+func shapeDelimiters(shape Shape) (string, string) {
+	switch shape {
+	case ShapeRound:
+		return "(", ")"
+	case ShapeDecision:
+		return "{", "}"
+	case ShapeDatabase:
+		return "[(", ")]"
+	case ShapeStadium:
+		return "([", "])"
+	}
+	return "[", "]"
+}
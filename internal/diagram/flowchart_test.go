@@ -0,0 +1,43 @@
+package diagram
+
+import "testing"
+
+func TestFlowchart(t *testing.T) {
+	f := NewFlowchart("TD")
+	if !f.Empty() {
+		t.Error("New flowchart should be empty")
+	}
+
+	f.Node("internal/app", "internal/app", ShapeBox)
+	f.Node("internal-app", "app (other)", ShapeRound)
+	f.Node("db", `say "hi"`, ShapeDatabase)
+	f.Edge("internal/app", "db", "")
+	f.Edge("internal/app", "db", "")
+	f.Edge("internal-app", "cmd/hauk", "uses")
+
+	want := `graph TD
+    internal_app["internal/app"]
+    internal_app_2("app (other)")
+    db[("say #quot;hi#quot;")]
+    cmd_hauk["cmd/hauk"]
+    internal_app --> db
+    internal_app_2 -->|"uses"| cmd_hauk`
+	if got := f.String(); got != want {
+		t.Errorf("String() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestNodeID(t *testing.T) {
+	tests := map[string]string{
+		"github.com/x/y": "github_com_x_y",
+		"end":            "end_",
+		"2fa":            "n2fa",
+		"///":            "node",
+		"Order_Items":    "Order_Items",
+	}
+	for in, want := range tests {
+		if got := NodeID(in); got != want {
+			t.Errorf("NodeID(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
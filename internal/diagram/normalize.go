@@ -0,0 +1,123 @@
+package diagram
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// flowchartHeader matches the first line of a graph or flowchart
+	flowchartHeader = regexp.MustCompile(`^\s*(graph|flowchart)\b`)
+
+	// shapedNode matches a node declaration with a label, e.g. A[Start],
+	// B{"Is it working?"} or db[(Postgres)]. Quoted labels may contain
+	// brackets.
+	shapedNode = regexp.MustCompile(`([A-Za-z0-9_][A-Za-z0-9_\-]*)(\[\[|\[\(|\(\[|\(\(|\{\{|\[|\(|\{)("[^"]*"|.*?)(\]\]|\)\]|\]\)|\)\)|\}\}|\]|\)|\})`)
+
+	// classSuffix matches a :::className shorthand on a node
+	classSuffix = regexp.MustCompile(`:::[A-Za-z0-9_\-]+`)
+
+	// quotedEdgeLabel matches an inline edge label in quotes, e.g. -->|"yes"|
+	quotedEdgeLabel = regexp.MustCompile(`\|"([^|]*)"\|`)
+
+	// subgraphTitle matches a subgraph with an ID and a title, e.g.
+	// subgraph shop ["Online Shop"]
+	subgraphTitle = regexp.MustCompile(`^(\s*subgraph\s+)([A-Za-z0-9_\-]+)\s*\[(.*)\]\s*$`)
+
+	// edgeOperator matches the arrows and links between nodes, including inline labels
+	edgeOperator = regexp.MustCompile(`\s*(-->\|[^|]*\||---\|[^|]*\||-\.->|==>|-->|---|--[ox])\s*`)
+)
+
+// directiveLines are flowchart statements the ASCII renderer doesn't draw
+var directiveLines = []string{"style ", "classDef ", "class ", "click ", "linkStyle ", "direction "}
+
+// normalize rewrites a mermaid flowchart into the subset the ASCII renderer
+// understands: shaped nodes become their labels, titled subgraphs their
+// titles, and styling is dropped. Other diagram types are returned unchanged.
+func normalize(code string) string {
+	lines := strings.Split(code, "\n")
+	if len(lines) == 0 || !flowchartHeader.MatchString(lines[0]) {
+		return code
+	}
+
+	// First pass: learn each node's label and each subgraph's title, so
+	// edges that name a subgraph by ID still point at it
+	labels := make(map[string]string)
+	for _, line := range lines[1:] {
+		if m := subgraphTitle.FindStringSubmatch(line); m != nil {
+			labels[m[2]] = unquoteLabel(m[3])
+			continue
+		}
+		for _, match := range shapedNode.FindAllStringSubmatch(line, -1) {
+			labels[match[1]] = unquoteLabel(match[3])
+		}
+	}
+
+	out := []string{lines[0]}
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimSpace(line)
+		if isDirective(trimmed) {
+			continue
+		}
+		if m := subgraphTitle.FindStringSubmatch(line); m != nil {
+			out = append(out, m[1]+labels[m[2]])
+			continue
+		}
+		if strings.HasPrefix(trimmed, "%%") || trimmed == "end" || strings.HasPrefix(trimmed, "subgraph ") {
+			out = append(out, line)
+			continue
+		}
+
+		line = classSuffix.ReplaceAllString(line, "")
+		line = shapedNode.ReplaceAllStringFunc(line, func(s string) string {
+			return labels[shapedNode.FindStringSubmatch(s)[1]]
+		})
+		out = append(out, replaceBareIDs(line, labels))
+	}
+	return strings.Join(out, "\n")
+}
+
+// replaceBareIDs swaps node IDs referenced without a shape for their labels
+func replaceBareIDs(line string, labels map[string]string) string {
+	indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+	body := strings.TrimSpace(line)
+
+	var b strings.Builder
+	b.WriteString(indent)
+	last := 0
+	for _, loc := range edgeOperator.FindAllStringIndex(body, -1) {
+		b.WriteString(labelFor(body[last:loc[0]], labels))
+		b.WriteString(quotedEdgeLabel.ReplaceAllString(body[loc[0]:loc[1]], "|$1|"))
+		last = loc[1]
+	}
+	b.WriteString(labelFor(body[last:], labels))
+	return b.String()
+}
+
+// labelFor returns the label for a node ID, or the text unchanged
+func labelFor(text string, labels map[string]string) string {
+	trimmed := strings.TrimSpace(text)
+	if label, ok := labels[trimmed]; ok {
+		return strings.Replace(text, trimmed, label, 1)
+	}
+	return text
+}
+
+// unquoteLabel strips quotes and HTML entities mermaid allows in labels
+func unquoteLabel(label string) string {
+	label = strings.TrimSpace(label)
+	if len(label) >= 2 && label[0] == '"' && label[len(label)-1] == '"' {
+		label = label[1 : len(label)-1]
+	}
+	return strings.NewReplacer("#quot;", `"`, "<br>", " ", "<br/>", " ").Replace(label)
+}
+
+// isDirective reports whether a trimmed line is a styling statement
+func isDirective(line string) bool {
+	for _, prefix := range directiveLines {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
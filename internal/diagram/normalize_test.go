@@ -0,0 +1,68 @@
+package diagram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "shapes become labels",
+			in:   "graph TD\n    A[Start] --> B{Is it working?}\n    B -->|Yes| C[Great!]\n    B -->|No| D[Debug]\n    D --> B",
+			want: "graph TD\n    Start --> Is it working?\n    Is it working? -->|Yes| Great!\n    Is it working? -->|No| Debug\n    Debug --> Is it working?",
+		},
+		{
+			name: "quoted labels and edge labels",
+			in:   "flowchart LR\n    app[\"internal/app\"]\n    db[(\"Postgres\")]\n    app -->|\"reads\"| db",
+			want: "flowchart LR\n    internal/app\n    Postgres\n    internal/app -->|reads| Postgres",
+		},
+		{
+			name: "brackets inside quoted labels",
+			in:   "graph LR\n    op[\"GET /pets/{id}\"] --> list[\"items[]\"]",
+			want: "graph LR\n    GET /pets/{id} --> items[]",
+		},
+		{
+			name: "styling is dropped",
+			in:   "graph TD\n    A:::hot --> B\n    classDef hot fill:#f00\n    style B fill:#0f0",
+			want: "graph TD\n    A --> B",
+		},
+		{
+			name: "subgraphs are titled, also where edges name them",
+			in:   "flowchart TD\n    subgraph shop [\"Online Shop\"]\n        web[Web]\n    end\n    subgraph api [API]\n    end\n    user --> shop\n    shop -->|calls| api",
+			want: "flowchart TD\n    subgraph Online Shop\n        Web\n    end\n    subgraph API\n    end\n    user --> Online Shop\n    Online Shop -->|calls| API",
+		},
+		{
+			name: "other diagrams are untouched",
+			in:   "sequenceDiagram\n    A->>B: hi",
+			want: "sequenceDiagram\n    A->>B: hi",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalize(tt.in); got != tt.want {
+				t.Errorf("normalize() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_Labels(t *testing.T) {
+	out, err := Render("graph TD\n    A[Start] --> B{Is it working?}\n    B -->|Yes| C[Great!]")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	for _, want := range []string{"Start", "Is it working?", "Great!"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render() missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "A[Start]") {
+		t.Errorf("Render() should draw labels, not declarations:\n%s", out)
+	}
+}
@@ -1,19 +1,94 @@
 package diagram
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+
 	"github.com/AlexanderGrooff/mermaid-ascii/cmd"
 )
 
 // Render converts mermaid code to ASCII art
-func Render(mermaidCode string) (string, error) {
-	// Use the mermaid-ascii library to render
-	_, err := cmd.DiagramFactory(mermaidCode)
-	if err != nil {
-		return "", err
+func Render(mermaidCode string) (output string, err error) {
+	// The renderer can panic on input it doesn't expect; never take the TUI down with it
+	defer func() {
+		if r := recover(); r != nil {
+			output, err = "", fmt.Errorf("failed to render diagram: %v", r)
+		}
+	}()
+
+	// C4 diagrams are drawn as the equivalent flowchart
+	if IsC4(mermaidCode) {
+		model, err := ParseC4(mermaidCode)
+		if err != nil {
+			return "", err
+		}
+		mermaidCode = model.Flowchart()
 	}
 
-	// Convert to string representation
-	// Note: The actual rendering implementation will depend on the library's API
-	// This is a placeholder that returns the code as-is for now
-	return mermaidCode, nil
+	return cmd.RenderDiagram(normalize(mermaidCode), nil)
+}
+
+// thumbnailCache memoizes renders so redrawing the chat doesn't re-run layout
+var thumbnailCache = struct {
+	sync.Mutex
+	entries map[string]renderResult
+}{entries: make(map[string]renderResult)}
+
+// renderResult is a cached Render outcome
+type renderResult struct {
+	output string
+	err    error
+}
+
+// maxCachedThumbnails bounds the render cache
+const maxCachedThumbnails = 64
+
+// Thumbnail renders mermaid code and crops it to at most width columns and
+// maxLines lines, noting how much was cut off
+func Thumbnail(mermaidCode string, width, maxLines int) (string, error) {
+	thumbnailCache.Lock()
+	result, ok := thumbnailCache.entries[mermaidCode]
+	thumbnailCache.Unlock()
+
+	if !ok {
+		output, err := Render(mermaidCode)
+		result = renderResult{output: output, err: err}
+
+		thumbnailCache.Lock()
+		if len(thumbnailCache.entries) >= maxCachedThumbnails {
+			thumbnailCache.entries = make(map[string]renderResult)
+		}
+		thumbnailCache.entries[mermaidCode] = result
+		thumbnailCache.Unlock()
+	}
+
+	if result.err != nil {
+		return "", result.err
+	}
+	return crop(result.output, width, maxLines), nil
+}
+
+// crop trims trailing blank lines and cuts output to width columns and maxLines lines
+func crop(output string, width, maxLines int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n "), "\n")
+
+	var hidden int
+	if maxLines > 0 && len(lines) > maxLines {
+		hidden = len(lines) - maxLines
+		lines = lines[:maxLines]
+	}
+
+	for i, line := range lines {
+		line = strings.TrimRight(line, " ")
+		if runes := []rune(line); width > 0 && len(runes) > width {
+			line = string(runes[:width-1]) + "…"
+		}
+		lines[i] = line
+	}
+
+	if hidden > 0 {
+		lines = append(lines, fmt.Sprintf("… %d more lines", hidden))
+	}
+	return strings.Join(lines, "\n")
 }
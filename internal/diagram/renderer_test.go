@@ -0,0 +1,66 @@
+package diagram
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	out, err := Render("graph LR\n    A --> B")
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if !strings.Contains(out, "A") || !strings.Contains(out, "B") || !strings.Contains(out, "┌") {
+		t.Errorf("Render() = %q, want boxes for A and B", out)
+	}
+}
+
+func TestRender_Unsupported(t *testing.T) {
+	if _, err := Render("pie\n    \"a\": 1"); err == nil {
+		t.Error("Render(pie) error = nil, want unsupported diagram error")
+	}
+}
+
+func TestThumbnail_Crops(t *testing.T) {
+	out, err := Thumbnail("graph TD\n    A --> B\n    B --> C\n    C --> D", 6, 4)
+	if err != nil {
+		t.Fatalf("Thumbnail() error = %v", err)
+	}
+
+	lines := strings.Split(out, "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Thumbnail() has %d lines, want 4 plus a note", len(lines))
+	}
+	for i, line := range lines[:4] {
+		if n := len([]rune(line)); n > 6 {
+			t.Errorf("Line %d has %d columns, want <= 6", i, n)
+		}
+	}
+	if !strings.HasPrefix(lines[4], "… ") || !strings.HasSuffix(lines[4], "more lines") {
+		t.Errorf("Last line = %q, want a note about hidden lines", lines[4])
+	}
+}
+
+func TestCrop(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		width    int
+		maxLines int
+		want     string
+	}{
+		{"fits", "ab\ncd", 10, 10, "ab\ncd"},
+		{"trailing blanks", "ab  \n\n   \n", 10, 10, "ab"},
+		{"too wide", "abcdef", 4, 10, "abc…"},
+		{"too tall", "a\nb\nc", 10, 2, "a\nb\n… 1 more lines"},
+		{"unlimited", "abcdef\nb", 0, 0, "abcdef\nb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := crop(tt.in, tt.width, tt.maxLines); got != tt.want {
+				t.Errorf("crop() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
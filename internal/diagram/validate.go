@@ -0,0 +1,175 @@
+package diagram
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Diagnostic is a problem found in mermaid source
+type Diagnostic struct {
+	Line    int // 1-based, 0 for the diagram as a whole
+	Message string
+}
+
+// String is "line N: message", or just the message for the whole diagram
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return d.Message
+	}
+	return fmt.Sprintf("line %d: %s", d.Line, d.Message)
+}
+
+// diagramTypes are the keywords a mermaid diagram can start with
+var diagramTypes = []string{
+	"flowchart", "graph", "sequenceDiagram", "classDiagram", "stateDiagram",
+	"stateDiagram-v2", "erDiagram", "journey", "gantt", "pie", "quadrantChart",
+	"requirementDiagram", "gitGraph", "C4Context", "C4Container", "C4Component",
+	"C4Dynamic", "C4Deployment", "mindmap", "timeline", "sankey-beta",
+	"xychart-beta", "block-beta", "packet-beta", "architecture-beta", "kanban",
+}
+
+// blockOpeners start a block closed by "end", per diagram type
+var blockOpeners = map[string]*regexp.Regexp{
+	"flowchart":       regexp.MustCompile(`^subgraph\b`),
+	"graph":           regexp.MustCompile(`^subgraph\b`),
+	"sequenceDiagram": regexp.MustCompile(`^(alt|opt|loop|par|critical|break|rect|box)\b`),
+}
+
+// danglingEdge matches a flowchart line ending in an arrow with no target
+var danglingEdge = regexp.MustCompile(`(--+>|==+>|-\.+->|--+-|\|[^|]*\|)\s*$`)
+
+// Validate checks mermaid source for mistakes the renderers don't report
+// clearly: a missing or unknown diagram type, unbalanced brackets and
+// quotes in flowcharts, edges without a target and blocks left open or
+// closed twice. It isn't a full parser; source it passes may still fail to
+// render.
+func Validate(source string) []Diagnostic {
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+
+	start := diagramLine(lines)
+	if start < 0 {
+		return []Diagnostic{{Message: "empty diagram"}}
+	}
+
+	kind := firstField(strings.TrimSpace(lines[start]))
+	if !knownType(kind) {
+		return []Diagnostic{{Line: start + 1, Message: fmt.Sprintf("unknown diagram type %q", kind)}}
+	}
+
+	switch C4Level(kind) {
+	case C4ContextLevel, C4ContainerLevel, C4ComponentLevel:
+		_, diags := parseC4(lines)
+		return diags
+	}
+
+	var diags []Diagnostic
+	var open []int // Lines of the blocks not yet closed
+	flowchart := kind == "flowchart" || kind == "graph"
+	opener := blockOpeners[kind]
+
+	for i := start + 1; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" || strings.HasPrefix(line, "%%") || isDirective(line) {
+			continue
+		}
+
+		if opener != nil {
+			switch {
+			case opener.MatchString(line):
+				open = append(open, i+1)
+			case line == "end":
+				if len(open) == 0 {
+					diags = append(diags, Diagnostic{Line: i + 1, Message: "end without an open block"})
+				} else {
+					open = open[:len(open)-1]
+				}
+			}
+		}
+
+		if flowchart {
+			if msg := checkBrackets(line); msg != "" {
+				diags = append(diags, Diagnostic{Line: i + 1, Message: msg})
+			} else if danglingEdge.MatchString(line) {
+				diags = append(diags, Diagnostic{Line: i + 1, Message: "edge has no target node"})
+			}
+		}
+	}
+
+	for _, line := range open {
+		diags = append(diags, Diagnostic{Line: line, Message: "block is never closed with end"})
+	}
+	return diags
+}
+
+// diagramLine returns the index of the line naming the diagram type,
+// after any front matter and comments, or -1 if there's none
+func diagramLine(lines []string) int {
+	frontMatter := false
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "---" && (frontMatter || i == 0):
+			frontMatter = !frontMatter
+		case frontMatter, line == "", strings.HasPrefix(line, "%%"):
+		default:
+			return i
+		}
+	}
+	return -1
+}
+
+// knownType reports whether kind is a mermaid diagram type
+func knownType(kind string) bool {
+	for _, t := range diagramTypes {
+		if kind == t {
+			return true
+		}
+	}
+	return false
+}
+
+// firstField returns the first whitespace-separated word of line
+func firstField(line string) string {
+	if fields := strings.Fields(line); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// checkBrackets reports an unterminated quote or an unbalanced bracket in
+// a flowchart line. Quoted text is skipped, so labels may contain brackets.
+func checkBrackets(line string) string {
+	pairs := map[rune]rune{')': '(', ']': '[', '}': '{'}
+	var stack []rune
+	inQuote := false
+
+	var prev rune
+	for _, r := range line {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case inQuote:
+		case r == '(' || r == '[' || r == '{':
+			stack = append(stack, r)
+		case r == '>' && len(stack) == 0 && (unicode.IsLetter(prev) || unicode.IsDigit(prev) || prev == '_'):
+			// The asymmetric shape, id>label]
+			stack = append(stack, '[')
+		case pairs[r] != 0:
+			if len(stack) == 0 || stack[len(stack)-1] != pairs[r] {
+				return fmt.Sprintf("unexpected %q", r)
+			}
+			stack = stack[:len(stack)-1]
+		}
+		prev = r
+	}
+
+	if inQuote {
+		return "unterminated quote"
+	}
+	if len(stack) > 0 {
+		return fmt.Sprintf("unclosed %q", stack[len(stack)-1])
+	}
+	return ""
+}
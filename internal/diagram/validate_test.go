@@ -0,0 +1,84 @@
+package diagram
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{
+			name: "valid flowchart",
+			in:   "flowchart TD\n    A[Start] --> B{Ready?}\n    B -->|\"yes [now]\"| C>Go]\n    subgraph S\n        C --> D((Done))\n    end\n    style A fill:#f9f",
+		},
+		{
+			name: "front matter and comments",
+			in:   "---\ntitle: Flow\n---\n%% notes\nsequenceDiagram\n    alt ok\n        A->>B: hi\n    else\n        B->>A: no\n    end",
+		},
+		{
+			name: "valid C4",
+			in:   "C4Context\n    title Shop\n    Person(c, \"Customer\")\n    Enterprise_Boundary(b, \"Us\") {\n        System(s, \"Shop\", \"Sells things\")\n    }\n    Rel(c, s, \"Uses\")",
+		},
+		{
+			name: "C4 problems",
+			in:   "C4Container\n    Container(api, \"API\", \"Go\")\n    Contaner(db, \"DB\")\n    api --> db\n    System_Boundary(s, \"Shop\") {\n    Rel(api, db, \"Reads\")",
+			want: []string{
+				"line 3: unknown C4 statement Contaner",
+				"line 4: expected Macro(args), e.g. System(id, \"Name\")",
+				"line 5: boundary s is never closed with }",
+				"line 6: relationship refers to undeclared db",
+			},
+		},
+		{
+			name: "C4 duplicate IDs",
+			in:   "C4Context\n    System(s, \"Shop\")\n    System_Ext(s, \"Other\")\n    }",
+			want: []string{"line 3: s is already declared on line 2", "line 4: } without an open boundary"},
+		},
+		{
+			name: "empty",
+			in:   "\n%% only a comment\n",
+			want: []string{"empty diagram"},
+		},
+		{
+			name: "unknown type",
+			in:   "flowchar TD\n    A --> B",
+			want: []string{`line 1: unknown diagram type "flowchar"`},
+		},
+		{
+			name: "brackets and quotes",
+			in:   "graph LR\n    A[Start --> B\n    C{x)\n    D[\"open] --> E",
+			want: []string{`line 2: unclosed '['`, `line 3: unexpected ')'`, "line 4: unterminated quote"},
+		},
+		{
+			name: "edge without target",
+			in:   "flowchart TD\n    A --> B\n    B -->|yes|\n    C -->",
+			want: []string{"line 3: edge has no target node", "line 4: edge has no target node"},
+		},
+		{
+			name: "unbalanced blocks",
+			in:   "flowchart TD\n    subgraph one\n    A --> B\n    end\n    end\n    subgraph two\n    C",
+			want: []string{"line 5: end without an open block", "line 6: block is never closed with end"},
+		},
+		{
+			name: "sequence block left open",
+			in:   "sequenceDiagram\n    loop every second\n        A->>B: ping",
+			want: []string{"line 2: block is never closed with end"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, d := range Validate(tt.in) {
+				got = append(got, d.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+// Package docs finds mermaid diagrams fenced in Markdown files and writes
+// edited diagrams back without touching the rest of the file
+package docs
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Block is one ```mermaid fence in a Markdown file
+type Block struct {
+	Path    string
+	Line    int    // 1-based line of the opening fence
+	Heading string // Nearest heading above the fence, if any
+	Source  string // Diagram source, without the fence's indentation
+
+	start, end int    // Byte range of the fenced lines in the file
+	raw        string // Those bytes as read, to detect changes on disk
+	indent     string // Indentation of the fence, e.g. inside a list
+	crlf       bool
+}
+
+// Title names the block for pickers, e.g. "docs/arch.md:12 Overview"
+func (b Block) Title() string {
+	title := fmt.Sprintf("%s:%d", b.Path, b.Line)
+	if b.Heading != "" {
+		title += " " + b.Heading
+	}
+	return title
+}
+
+// Scan returns the mermaid blocks of the Markdown files under root, or in
+// root itself if it's a file. Hidden directories, node_modules and vendor
+// are skipped.
+func Scan(root string) ([]Block, error) {
+	var blocks []Block
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if p != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := strings.ToLower(filepath.Ext(p)); ext != ".md" && ext != ".markdown" {
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, Parse(p, data)...)
+		return nil
+	})
+	return blocks, err
+}
+
+// Parse returns the terminated mermaid fences in a Markdown file's content
+func Parse(path string, data []byte) []Block {
+	var blocks []Block
+	var heading string
+
+	// The fence being read, if any
+	var (
+		inFence   bool
+		fenceChar byte
+		fenceLen  int
+		mermaid   bool
+		current   Block
+	)
+
+	offset := 0
+	for lineNo := 1; offset < len(data); lineNo++ {
+		next := bytes.IndexByte(data[offset:], '\n')
+		lineEnd := len(data)
+		if next >= 0 {
+			lineEnd = offset + next + 1
+		}
+		line := strings.TrimRight(string(data[offset:lineEnd]), "\r\n")
+		trimmed := strings.TrimLeft(line, " ")
+		indent := line[:len(line)-len(trimmed)]
+
+		switch {
+		case !inFence:
+			if char, n, info := openingFence(trimmed); n > 0 {
+				inFence, fenceChar, fenceLen = true, char, n
+				mermaid = strings.EqualFold(firstWord(info), "mermaid")
+				current = Block{
+					Path:    path,
+					Line:    lineNo,
+					Heading: heading,
+					start:   lineEnd,
+					indent:  indent,
+					crlf:    strings.HasSuffix(string(data[offset:lineEnd]), "\r\n"),
+				}
+			} else if strings.HasPrefix(trimmed, "#") {
+				heading = strings.TrimSpace(strings.Trim(trimmed, "#"))
+			}
+
+		case closingFence(trimmed, fenceChar, fenceLen):
+			if mermaid {
+				current.end = offset
+				current.raw = string(data[current.start:offset])
+				current.Source = dedent(current.raw, current.indent)
+				blocks = append(blocks, current)
+			}
+			inFence = false
+		}
+
+		offset = lineEnd
+	}
+	return blocks
+}
+
+// openingFence returns the fence character, its length and the info string
+// when line opens a code fence
+func openingFence(line string) (byte, int, string) {
+	if len(line) < 3 || (line[0] != '`' && line[0] != '~') {
+		return 0, 0, ""
+	}
+	char := line[0]
+	n := 0
+	for n < len(line) && line[n] == char {
+		n++
+	}
+	info := line[n:]
+	if n < 3 || (char == '`' && strings.Contains(info, "`")) {
+		return 0, 0, ""
+	}
+	return char, n, strings.TrimSpace(info)
+}
+
+// closingFence reports whether line closes a fence of char at least n long
+func closingFence(line string, char byte, n int) bool {
+	line = strings.TrimRight(line, " \t")
+	if len(line) < n {
+		return false
+	}
+	for i := 0; i < len(line); i++ {
+		if line[i] != char {
+			return false
+		}
+	}
+	return true
+}
+
+// firstWord returns the first word of an info string, e.g. mermaid in
+// "mermaid title=x"
+func firstWord(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// dedent removes the fence's indentation from the fenced lines
+func dedent(raw, indent string) string {
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	lines := strings.Split(strings.TrimSuffix(raw, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, indent)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Save replaces the block's diagram in its file with source, leaving every
+// other byte of the file as it was. It fails if the fenced lines changed
+// on disk since the block was read. The returned block describes the file
+// after saving.
+func Save(b Block, source string) (Block, error) {
+	info, err := os.Stat(b.Path)
+	if err != nil {
+		return b, err
+	}
+	data, err := os.ReadFile(b.Path)
+	if err != nil {
+		return b, err
+	}
+	if b.end > len(data) || string(data[b.start:b.end]) != b.raw {
+		return b, fmt.Errorf("%s changed on disk since the diagram was opened", b.Path)
+	}
+	if source == b.Source {
+		return b, nil
+	}
+
+	source = strings.TrimRight(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	newline := "\n"
+	if b.crlf {
+		newline = "\r\n"
+	}
+	// Lines that didn't change keep their original bytes, so saving an
+	// unchanged diagram leaves the file exactly as it was, whitespace on
+	// blank lines included
+	original := strings.Split(strings.TrimSuffix(strings.ReplaceAll(b.raw, "\r\n", "\n"), "\n"), "\n")
+	var raw strings.Builder
+	for i, line := range strings.Split(source, "\n") {
+		switch {
+		case i < len(original) && strings.TrimPrefix(original[i], b.indent) == line:
+			raw.WriteString(original[i])
+		case line != "":
+			raw.WriteString(b.indent + line)
+		}
+		raw.WriteString(newline)
+	}
+
+	var out bytes.Buffer
+	out.Write(data[:b.start])
+	out.WriteString(raw.String())
+	out.Write(data[b.end:])
+	if err := os.WriteFile(b.Path, out.Bytes(), info.Mode().Perm()); err != nil {
+		return b, err
+	}
+
+	b.Source = source
+	b.raw = raw.String()
+	b.end = b.start + len(b.raw)
+	return b, nil
+}
@@ -0,0 +1,168 @@
+package docs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const architecture = "# Architecture\n\nIntro.\n\n## Overview\n\n```mermaid\ngraph TD\n    A --> B\n```\n\n```go\n// ```mermaid inside Go code isn't a diagram\n```\n\n" +
+	"````markdown\n```mermaid\ngraph LR\n    X --> Y\n```\n````\n\n## Steps\n\n1. First\n\n   ~~~ mermaid title=steps\n   sequenceDiagram\n\n       A->>B: hi\n   ~~~\n\n```mermaid\nunterminated\n"
+
+func TestParse(t *testing.T) {
+	blocks := Parse("arch.md", []byte(architecture))
+	if len(blocks) != 2 {
+		t.Fatalf("Parse() found %d blocks, want 2: %+v", len(blocks), blocks)
+	}
+
+	tests := []struct {
+		line    int
+		heading string
+		source  string
+	}{
+		{7, "Overview", "graph TD\n    A --> B"},
+		{27, "Steps", "sequenceDiagram\n\n    A->>B: hi"},
+	}
+	for i, tt := range tests {
+		b := blocks[i]
+		if b.Line != tt.line || b.Heading != tt.heading || b.Source != tt.source {
+			t.Errorf("block %d = line %d, heading %q, source %q; want %d, %q, %q", i, b.Line, b.Heading, b.Source, tt.line, tt.heading, tt.source)
+		}
+	}
+	if got := blocks[0].Title(); got != "arch.md:7 Overview" {
+		t.Errorf("Title() = %q", got)
+	}
+}
+
+// writeDoc writes content to a Markdown file and returns its blocks
+func writeDoc(t *testing.T, content string) (string, []Block) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "doc.md")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	blocks, err := Scan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, blocks
+}
+
+func TestSave(t *testing.T) {
+	path, blocks := writeDoc(t, architecture)
+
+	saved, err := Save(blocks[1], "sequenceDiagram\n    A->>B: hello\n    B-->>A: hi\n")
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(architecture,
+		"   sequenceDiagram\n\n       A->>B: hi\n",
+		"   sequenceDiagram\n       A->>B: hello\n       B-->>A: hi\n", 1)
+	if string(data) != want {
+		t.Errorf("file after Save() =\n%s\nwant\n%s", data, want)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Save() changed the file mode to %v", info.Mode().Perm())
+	}
+
+	// The returned block can be saved again
+	if _, err := Save(saved, "sequenceDiagram"); err != nil {
+		t.Errorf("second Save() error = %v", err)
+	}
+	if reparsed := Parse(path, mustRead(t, path)); reparsed[1].Source != "sequenceDiagram" {
+		t.Errorf("Source after second save = %q", reparsed[1].Source)
+	}
+}
+
+func TestSave_CRLF(t *testing.T) {
+	content := "Title\r\n\r\n```mermaid\r\ngraph TD\r\n  A --> B\r\n```\r\nEnd\r\n"
+	path, blocks := writeDoc(t, content)
+	if blocks[0].Source != "graph TD\n  A --> B" {
+		t.Fatalf("Source = %q, want LF line endings", blocks[0].Source)
+	}
+
+	if _, err := Save(blocks[0], "graph LR\n  A --> C"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(mustRead(t, path)), "Title\r\n\r\n```mermaid\r\ngraph LR\r\n  A --> C\r\n```\r\nEnd\r\n"; got != want {
+		t.Errorf("file = %q, want %q", got, want)
+	}
+}
+
+func TestSave_Unchanged(t *testing.T) {
+	// Blank lines inside an indented fence keep their whitespace
+	content := "- Step\n\n  ```mermaid\n  graph TD\n  \n      A --> B\n\n  ```\n"
+	path, blocks := writeDoc(t, content)
+
+	if _, err := Save(blocks[0], blocks[0].Source); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(mustRead(t, path)); got != content {
+		t.Errorf("file after saving unchanged = %q, want %q", got, content)
+	}
+
+	// Changing one line leaves the others' bytes alone
+	if _, err := Save(blocks[0], strings.Replace(blocks[0].Source, "TD", "LR", 1)); err != nil {
+		t.Fatal(err)
+	}
+	want := "- Step\n\n  ```mermaid\n  graph LR\n  \n      A --> B\n  ```\n"
+	if got := string(mustRead(t, path)); got != want {
+		t.Errorf("file after saving one change = %q, want %q", got, want)
+	}
+}
+
+func TestSave_ChangedOnDisk(t *testing.T) {
+	path, blocks := writeDoc(t, "```mermaid\ngraph TD\n```\n")
+	if err := os.WriteFile(path, []byte("```mermaid\ngraph LR\n```\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Save(blocks[0], "graph BT"); err == nil || !strings.Contains(err.Error(), "changed on disk") {
+		t.Errorf("Save() error = %v, want changed on disk", err)
+	}
+	if got := string(mustRead(t, path)); got != "```mermaid\ngraph LR\n```\n" {
+		t.Errorf("file = %q, want it untouched", got)
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"README.md":             "```mermaid\ngraph TD\n```\n",
+		"docs/design.markdown":  "```mermaid\nerDiagram\n```\n",
+		"docs/notes.txt":        "```mermaid\ngraph TD\n```\n",
+		"node_modules/x/doc.md": "```mermaid\ngraph TD\n```\n",
+		".github/doc.md":        "```mermaid\ngraph TD\n```\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	blocks, err := Scan(dir)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+	if len(blocks) != 2 || filepath.Base(blocks[0].Path) != "README.md" || filepath.Base(blocks[1].Path) != "design.markdown" {
+		t.Errorf("Scan() = %+v, want README.md and docs/design.markdown", blocks)
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
@@ -0,0 +1,274 @@
+// Package editor is a small line-based text buffer for editing mermaid
+// source in the diagram pane: cursor movement, insertion and deletion, and
+// indentation that follows mermaid's blocks
+package editor
+
+import "strings"
+
+// IndentWidth is the number of spaces one level of indentation adds
+const IndentWidth = 4
+
+// blockWords open a block closed by "end"
+var blockWords = map[string]bool{
+	"subgraph": true, "alt": true, "opt": true, "loop": true, "par": true,
+	"critical": true, "break": true, "rect": true, "box": true,
+}
+
+// midWords continue a block at the opener's indentation, e.g. else in alt
+var midWords = map[string]bool{"else": true, "and": true, "option": true}
+
+// Buffer is editable text with a cursor. Columns count runes.
+type Buffer struct {
+	lines    []string
+	row, col int
+	changed  bool
+}
+
+// New returns a buffer holding text with the cursor at the start
+func New(text string) *Buffer {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return &Buffer{lines: strings.Split(text, "\n")}
+}
+
+// Text returns the buffer's content
+func (b *Buffer) Text() string {
+	return strings.Join(b.lines, "\n")
+}
+
+// Lines returns the buffer's lines; callers must not modify them
+func (b *Buffer) Lines() []string {
+	return b.lines
+}
+
+// Cursor returns the cursor's line and column, both 0-based
+func (b *Buffer) Cursor() (row, col int) {
+	return b.row, b.col
+}
+
+// Changed reports whether the text was edited since New
+func (b *Buffer) Changed() bool {
+	return b.changed
+}
+
+// line returns the cursor's line as runes
+func (b *Buffer) line() []rune {
+	return []rune(b.lines[b.row])
+}
+
+// Insert types s at the cursor. Typing the last letter of a line that
+// closes or continues a block, like end or else, aligns it with the line
+// that opened the block.
+func (b *Buffer) Insert(s string) {
+	if s == "" {
+		return
+	}
+	line := b.line()
+	text := string(line[:b.col]) + s + string(line[b.col:])
+	b.lines[b.row] = text
+	b.col += len([]rune(s))
+	b.changed = true
+
+	if word := strings.TrimSpace(text); closesBlock(word) || midWords[word] {
+		b.reindent(b.openerIndent(b.row))
+	}
+}
+
+// Newline splits the line at the cursor. The new line keeps the current
+// indentation, one level deeper after a line that opens a block or names
+// the diagram type.
+func (b *Buffer) Newline() {
+	line := b.line()
+	before, after := string(line[:b.col]), string(line[b.col:])
+
+	indent := leadingSpace(before)
+	if trimmed := strings.TrimSpace(before); opensBlock(trimmed) || midWords[firstWord(trimmed)] || b.isHeader(b.row) {
+		indent += strings.Repeat(" ", IndentWidth)
+	}
+
+	b.lines[b.row] = before
+	rest := indent + strings.TrimLeft(after, " ")
+	b.lines = append(b.lines[:b.row+1], append([]string{rest}, b.lines[b.row+1:]...)...)
+	b.row++
+	b.col = len([]rune(indent))
+	b.changed = true
+}
+
+// Tab indents to the next multiple of IndentWidth
+func (b *Buffer) Tab() {
+	b.Insert(strings.Repeat(" ", IndentWidth-b.col%IndentWidth))
+}
+
+// Backspace deletes before the cursor. In leading indentation it removes a
+// whole level; at the start of a line it joins the line to the previous one.
+func (b *Buffer) Backspace() {
+	line := b.line()
+	switch {
+	case b.col > 0 && strings.TrimLeft(string(line[:b.col]), " ") == "":
+		n := b.col % IndentWidth
+		if n == 0 {
+			n = IndentWidth
+		}
+		b.lines[b.row] = string(line[:b.col-n]) + string(line[b.col:])
+		b.col -= n
+	case b.col > 0:
+		b.lines[b.row] = string(line[:b.col-1]) + string(line[b.col:])
+		b.col--
+	case b.row > 0:
+		prev := []rune(b.lines[b.row-1])
+		b.lines[b.row-1] = string(prev) + string(line)
+		b.lines = append(b.lines[:b.row], b.lines[b.row+1:]...)
+		b.row--
+		b.col = len(prev)
+	default:
+		return
+	}
+	b.changed = true
+}
+
+// Delete deletes the character under the cursor, joining the next line at
+// the end of a line
+func (b *Buffer) Delete() {
+	line := b.line()
+	switch {
+	case b.col < len(line):
+		b.lines[b.row] = string(line[:b.col]) + string(line[b.col+1:])
+	case b.row < len(b.lines)-1:
+		b.lines[b.row] += b.lines[b.row+1]
+		b.lines = append(b.lines[:b.row+1], b.lines[b.row+2:]...)
+	default:
+		return
+	}
+	b.changed = true
+}
+
+// Left moves the cursor back one character, to the previous line's end at
+// the start of a line
+func (b *Buffer) Left() {
+	switch {
+	case b.col > 0:
+		b.col--
+	case b.row > 0:
+		b.row--
+		b.col = len(b.line())
+	}
+}
+
+// Right moves the cursor forward one character, to the next line's start
+// at the end of a line
+func (b *Buffer) Right() {
+	switch {
+	case b.col < len(b.line()):
+		b.col++
+	case b.row < len(b.lines)-1:
+		b.row++
+		b.col = 0
+	}
+}
+
+// Up moves the cursor up n lines, keeping the column where possible
+func (b *Buffer) Up(n int) {
+	b.moveTo(b.row - n)
+}
+
+// Down moves the cursor down n lines, keeping the column where possible
+func (b *Buffer) Down(n int) {
+	b.moveTo(b.row + n)
+}
+
+// Home moves the cursor to the first non-space character, or to column 0
+// if it's already there
+func (b *Buffer) Home() {
+	indent := len([]rune(leadingSpace(b.lines[b.row])))
+	if b.col == indent {
+		b.col = 0
+	} else {
+		b.col = indent
+	}
+}
+
+// End moves the cursor to the end of the line
+func (b *Buffer) End() {
+	b.col = len(b.line())
+}
+
+// moveTo moves the cursor to row, clamped to the buffer
+func (b *Buffer) moveTo(row int) {
+	if row < 0 {
+		row = 0
+	}
+	if row > len(b.lines)-1 {
+		row = len(b.lines) - 1
+	}
+	b.row = row
+	if n := len(b.line()); b.col > n {
+		b.col = n
+	}
+}
+
+// reindent replaces the cursor line's indentation, keeping the cursor on
+// the same character
+func (b *Buffer) reindent(indent string) {
+	line := b.lines[b.row]
+	old := leadingSpace(line)
+	b.lines[b.row] = indent + line[len(old):]
+	b.col += len([]rune(indent)) - len([]rune(old))
+	if b.col < 0 {
+		b.col = 0
+	}
+}
+
+// openerIndent returns the indentation of the line that opened the block
+// row is in, or none at the top level
+func (b *Buffer) openerIndent(row int) string {
+	depth := 0
+	for r := row - 1; r >= 0; r-- {
+		trimmed := strings.TrimSpace(b.lines[r])
+		switch {
+		case closesBlock(trimmed):
+			depth++
+		case opensBlock(trimmed):
+			if depth == 0 {
+				return leadingSpace(b.lines[r])
+			}
+			depth--
+		}
+	}
+	return ""
+}
+
+// isHeader reports whether row is the line naming the diagram type: the
+// first line that isn't blank or a comment
+func (b *Buffer) isHeader(row int) bool {
+	for r, line := range b.lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "%%") {
+			continue
+		}
+		return r == row && !strings.HasPrefix(trimmed, "---")
+	}
+	return false
+}
+
+// opensBlock reports whether a trimmed line opens a block: a block keyword
+// like subgraph or alt, or a trailing {
+func opensBlock(trimmed string) bool {
+	return blockWords[firstWord(trimmed)] || strings.HasSuffix(trimmed, "{")
+}
+
+// closesBlock reports whether a trimmed line closes a block
+func closesBlock(trimmed string) bool {
+	return trimmed == "end" || trimmed == "}"
+}
+
+// firstWord returns the first space-separated word of s
+func firstWord(s string) string {
+	if fields := strings.Fields(s); len(fields) > 0 {
+		return fields[0]
+	}
+	return ""
+}
+
+// leadingSpace returns the spaces at the start of s
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " "))]
+}
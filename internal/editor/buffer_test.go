@@ -0,0 +1,123 @@
+package editor
+
+import "testing"
+
+// typeText feeds s to the buffer one key at a time, \n as Enter, \t as Tab
+// and \b as Backspace
+func typeText(b *Buffer, s string) {
+	for _, r := range s {
+		switch r {
+		case '\n':
+			b.Newline()
+		case '\t':
+			b.Tab()
+		case '\b':
+			b.Backspace()
+		default:
+			b.Insert(string(r))
+		}
+	}
+}
+
+func TestBuffer_Typing(t *testing.T) {
+	tests := []struct {
+		name  string
+		start string
+		typed string
+		want  string
+	}{
+		{
+			name:  "header indents",
+			typed: "flowchart TD\nA --> B",
+			want:  "flowchart TD\n    A --> B",
+		},
+		{
+			name:  "subgraph indents and end dedents",
+			typed: "graph LR\nsubgraph one\nA --> B\nend\nB --> C",
+			want:  "graph LR\n    subgraph one\n        A --> B\n    end\n    B --> C",
+		},
+		{
+			name:  "else aligns with alt",
+			typed: "sequenceDiagram\nalt ok\nA->>B: yes\nelse\nA->>B: no\nend",
+			want:  "sequenceDiagram\n    alt ok\n        A->>B: yes\n    else\n        A->>B: no\n    end",
+		},
+		{
+			name:  "nested blocks",
+			typed: "graph TD\nsubgraph a\nsubgraph b\nX\nend\nend",
+			want:  "graph TD\n    subgraph a\n        subgraph b\n            X\n        end\n    end",
+		},
+		{
+			name:  "braces",
+			typed: "erDiagram\nUSER {\nint id\n}",
+			want:  "erDiagram\n    USER {\n        int id\n    }",
+		},
+		{
+			name:  "backspace removes an indent level",
+			typed: "graph TD\n\bA",
+			want:  "graph TD\nA",
+		},
+		{
+			name:  "backspace joins lines",
+			start: "graph TD",
+			typed: "\n\b\b",
+			want:  "graph TD",
+		},
+		{
+			name:  "tab to next stop",
+			start: "",
+			typed: "ab\tc",
+			want:  "ab  c",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := New(tt.start)
+			b.End()
+			typeText(b, tt.typed)
+			if got := b.Text(); got != tt.want {
+				t.Errorf("Text() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuffer_Movement(t *testing.T) {
+	b := New("graph TD\n    A --> B\nC")
+
+	b.Down(1)
+	b.Home()
+	if row, col := b.Cursor(); row != 1 || col != 4 {
+		t.Errorf("Home = %d,%d, want 1,4", row, col)
+	}
+	b.Home()
+	if _, col := b.Cursor(); col != 0 {
+		t.Errorf("second Home = col %d, want 0", col)
+	}
+
+	b.End()
+	b.Down(5)
+	if row, col := b.Cursor(); row != 2 || col != 1 {
+		t.Errorf("Down past end = %d,%d, want 2,1", row, col)
+	}
+
+	b.Left()
+	b.Left()
+	if row, col := b.Cursor(); row != 1 || col != 11 {
+		t.Errorf("Left across lines = %d,%d, want 1,11", row, col)
+	}
+	b.Right()
+	b.Delete()
+	if got := b.Text(); got != "graph TD\n    A --> B\n" {
+		t.Errorf("Delete at line start = %q", got)
+	}
+	b.Up(1)
+	b.End()
+	b.Delete()
+	if got := b.Text(); got != "graph TD\n    A --> B" {
+		t.Errorf("Delete joining lines = %q", got)
+	}
+	if !b.Changed() {
+		t.Error("Changed() = false after edits")
+	}
+}
@@ -0,0 +1,484 @@
+package gograph
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/mnesler/hauk-tui/internal/diagram"
+)
+
+// maxLabelLength keeps flowchart boxes readable
+const maxLabelLength = 48
+
+// FuncGraph is the control flow of one function
+type FuncGraph struct {
+	Name     string  // Qualified name, e.g. app.Model.Update
+	Position string  // file:line of the declaration
+	Skipped  []error // Files that didn't parse and weren't searched
+	chart    *diagram.Flowchart
+}
+
+// FuncFlow finds the function or method called name under dir and builds a
+// flowchart of its control flow. name is Func, pkg.Func, Type.Method or
+// pkg.Type.Method. Files that don't parse are left out of the search and
+// listed in Skipped.
+func FuncFlow(dir, name string) (*FuncGraph, error) {
+	fset := token.NewFileSet()
+	matches, skipped, err := findFuncs(fset, dir, name)
+	if err != nil {
+		return nil, err
+	}
+
+	switch len(matches) {
+	case 0:
+		if len(skipped) > 0 {
+			// It may be in one of the files that didn't parse
+			return nil, fmt.Errorf("function %s not found under %s: %w", name, dir, skipped[0])
+		}
+		return nil, fmt.Errorf("function %s not found under %s", name, dir)
+	case 1:
+	default:
+		var found []string
+		for _, m := range matches {
+			found = append(found, fmt.Sprintf("%s (%s)", m.name, fset.Position(m.decl.Pos())))
+		}
+		return nil, fmt.Errorf("%s is ambiguous: %s", name, strings.Join(found, ", "))
+	}
+
+	match := matches[0]
+	if match.decl.Body == nil {
+		return nil, fmt.Errorf("%s has no body", match.name)
+	}
+
+	b := &flowBuilder{fset: fset, chart: diagram.NewFlowchart("TD")}
+	start := b.node(match.name+"()", diagram.ShapeStadium)
+	b.end = b.node("end", diagram.ShapeStadium)
+	b.connect(b.block(match.decl.Body.List, []exit{{from: start}}), b.end)
+
+	return &FuncGraph{
+		Name:     match.name,
+		Position: fset.Position(match.decl.Pos()).String(),
+		Skipped:  skipped,
+		chart:    b.chart,
+	}, nil
+}
+
+// Mermaid renders the control flow as a flowchart
+func (g *FuncGraph) Mermaid() string {
+	return g.chart.String()
+}
+
+// funcMatch is a declaration matching the requested name
+type funcMatch struct {
+	name string // pkg.Type.Method or pkg.Func
+	decl *ast.FuncDecl
+}
+
+// findFuncs returns the declarations under dir matching name, and why any
+// files were skipped
+func findFuncs(fset *token.FileSet, dir, name string) ([]funcMatch, []error, error) {
+	parts := strings.Split(name, ".")
+	if len(parts) > 3 {
+		return nil, nil, fmt.Errorf("invalid function name %q", name)
+	}
+
+	var matches []funcMatch
+	var skipped []error
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && skipDir(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, p, nil, parser.SkipObjectResolution)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("skipped %s: %w", p, err))
+			return nil
+		}
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok {
+				continue
+			}
+			if qualified, ok := matchFunc(file.Name.Name, fn, parts); ok {
+				matches = append(matches, funcMatch{name: qualified, decl: fn})
+			}
+		}
+		return nil
+	})
+	return matches, skipped, err
+}
+
+// matchFunc reports whether fn in package pkg is named by parts, returning
+// its qualified name
+func matchFunc(pkg string, fn *ast.FuncDecl, parts []string) (string, bool) {
+	recv := receiverName(fn)
+	qualified := pkg + "." + fn.Name.Name
+	if recv != "" {
+		qualified = pkg + "." + recv + "." + fn.Name.Name
+	}
+
+	if fn.Name.Name != parts[len(parts)-1] {
+		return "", false
+	}
+
+	switch len(parts) {
+	case 1:
+		return qualified, true
+	case 2:
+		// Either pkg.Func or Type.Method
+		return qualified, recv == "" && parts[0] == pkg || recv == parts[0]
+	default:
+		return qualified, parts[0] == pkg && parts[1] == recv
+	}
+}
+
+// receiverName returns the receiver's type name, or "" for plain functions
+func receiverName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return ""
+	}
+
+	t := fn.Recv.List[0].Type
+	for {
+		switch e := t.(type) {
+		case *ast.StarExpr:
+			t = e.X
+		case *ast.IndexExpr:
+			t = e.X
+		case *ast.IndexListExpr:
+			t = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// exit is a dangling edge waiting for the next node
+type exit struct {
+	from  string
+	label string
+}
+
+// flowContext is an enclosing loop, switch or select that break and
+// continue statements jump out of or back to
+type flowContext struct {
+	label  string // Statement label, if any
+	loop   bool
+	head   string // Loop condition node, for continue
+	breaks []exit
+}
+
+// flowBuilder turns statements into flowchart nodes and edges
+type flowBuilder struct {
+	fset     *token.FileSet
+	chart    *diagram.Flowchart
+	end      string
+	next     int
+	contexts []*flowContext
+	label    string // Label to attach to the next loop or switch
+}
+
+// node adds a node and returns its key
+func (b *flowBuilder) node(label string, shape diagram.Shape) string {
+	b.next++
+	key := fmt.Sprintf("n%d", b.next)
+	b.chart.Node(key, label, shape)
+	return key
+}
+
+// connect links every pending exit to the node to
+func (b *flowBuilder) connect(exits []exit, to string) {
+	for _, e := range exits {
+		b.chart.Edge(e.from, to, e.label)
+	}
+}
+
+// block lays out a statement list, grouping runs of simple statements into
+// one box, and returns the exits that fall through the end
+func (b *flowBuilder) block(stmts []ast.Stmt, in []exit) []exit {
+	for i := 0; i < len(stmts); i++ {
+		if !isSimple(stmts[i]) {
+			in = b.stmt(stmts[i], in)
+			continue
+		}
+
+		j := i
+		for j+1 < len(stmts) && isSimple(stmts[j+1]) {
+			j++
+		}
+		label := b.text(stmts[i])
+		if j > i {
+			label += fmt.Sprintf(" (+%d more)", j-i)
+		}
+		n := b.node(label, diagram.ShapeBox)
+		b.connect(in, n)
+		in = []exit{{from: n}}
+		i = j
+	}
+	return in
+}
+
+// stmt lays out one compound statement
+func (b *flowBuilder) stmt(s ast.Stmt, in []exit) []exit {
+	switch s := s.(type) {
+	case *ast.BlockStmt:
+		return b.block(s.List, in)
+
+	case *ast.LabeledStmt:
+		b.label = s.Label.Name
+		out := b.stmt(s.Stmt, in)
+		b.label = "" // Unclaimed if the statement isn't a loop or switch
+		return out
+
+	case *ast.ReturnStmt:
+		n := b.node(b.text(s), diagram.ShapeRound)
+		b.connect(in, n)
+		b.connect([]exit{{from: n}}, b.end)
+		return nil
+
+	case *ast.ExprStmt:
+		// Only panics get here; they end the function
+		n := b.node(b.text(s), diagram.ShapeRound)
+		b.connect(in, n)
+		b.connect([]exit{{from: n}}, b.end)
+		return nil
+
+	case *ast.IfStmt:
+		cond := b.expr(s.Cond)
+		if s.Init != nil {
+			cond = b.text(s.Init) + "; " + cond
+		}
+		d := b.node(cond, diagram.ShapeDecision)
+		b.connect(in, d)
+
+		out := b.block(s.Body.List, []exit{{from: d, label: "yes"}})
+		if s.Else != nil {
+			return append(out, b.stmt(s.Else, []exit{{from: d, label: "no"}})...)
+		}
+		return append(out, exit{from: d, label: "no"})
+
+	case *ast.SwitchStmt:
+		head := "switch"
+		if s.Tag != nil {
+			head += " " + b.expr(s.Tag)
+		}
+		return b.cases(head, s.Body, in, func(c ast.Stmt) (string, []ast.Stmt) {
+			clause := c.(*ast.CaseClause)
+			return b.exprList(clause.List), clause.Body
+		})
+
+	case *ast.TypeSwitchStmt:
+		return b.cases("switch "+b.text(s.Assign), s.Body, in, func(c ast.Stmt) (string, []ast.Stmt) {
+			clause := c.(*ast.CaseClause)
+			return b.exprList(clause.List), clause.Body
+		})
+
+	case *ast.SelectStmt:
+		return b.cases("select", s.Body, in, func(c ast.Stmt) (string, []ast.Stmt) {
+			clause := c.(*ast.CommClause)
+			if clause.Comm == nil {
+				return "", clause.Body
+			}
+			return b.text(clause.Comm), clause.Body
+		})
+
+	case *ast.ForStmt:
+		head := "for"
+		if s.Init != nil || s.Post != nil {
+			var clauses [3]string
+			if s.Init != nil {
+				clauses[0] = b.text(s.Init)
+			}
+			if s.Cond != nil {
+				clauses[1] = b.expr(s.Cond)
+			}
+			if s.Post != nil {
+				clauses[2] = b.text(s.Post)
+			}
+			head += " " + truncateLabel(strings.Join(clauses[:], "; "))
+		} else if s.Cond != nil {
+			head += " " + b.expr(s.Cond)
+		}
+		return b.loop(head, s.Body, in, s.Cond != nil)
+
+	case *ast.RangeStmt:
+		return b.loop("range "+b.expr(s.X), s.Body, in, true)
+
+	case *ast.BranchStmt:
+		return b.branch(s, in)
+	}
+
+	// Anything else is drawn as a plain step
+	n := b.node(b.text(s), diagram.ShapeBox)
+	b.connect(in, n)
+	return []exit{{from: n}}
+}
+
+// cases lays out a switch or select: a decision with one edge per clause
+func (b *flowBuilder) cases(head string, body *ast.BlockStmt, in []exit, clause func(ast.Stmt) (string, []ast.Stmt)) []exit {
+	d := b.node(head, diagram.ShapeDecision)
+	b.connect(in, d)
+
+	ctx := b.push(false, "")
+	var out []exit
+	hasDefault := false
+	for _, c := range body.List {
+		label, stmts := clause(c)
+		if label == "" {
+			label = "default"
+			hasDefault = true
+		}
+		out = append(out, b.block(stmts, []exit{{from: d, label: truncateLabel(label)}})...)
+	}
+	b.pop()
+
+	if !hasDefault {
+		out = append(out, exit{from: d, label: "no match"})
+	}
+	return append(out, ctx.breaks...)
+}
+
+// loop lays out a for or range loop whose body returns to the condition
+func (b *flowBuilder) loop(head string, body *ast.BlockStmt, in []exit, canFinish bool) []exit {
+	h := b.node(head, diagram.ShapeDecision)
+	b.connect(in, h)
+
+	ctx := b.push(true, h)
+	b.connect(b.block(body.List, []exit{{from: h, label: "loop"}}), h)
+	b.pop()
+
+	out := ctx.breaks
+	if canFinish {
+		out = append(out, exit{from: h, label: "done"})
+	}
+	return out
+}
+
+// branch handles break, continue and goto
+func (b *flowBuilder) branch(s *ast.BranchStmt, in []exit) []exit {
+	label := ""
+	if s.Label != nil {
+		label = s.Label.Name
+	}
+
+	switch s.Tok {
+	case token.BREAK:
+		if ctx := b.find(label, false); ctx != nil {
+			ctx.breaks = append(ctx.breaks, in...)
+			return nil
+		}
+	case token.CONTINUE:
+		if ctx := b.find(label, true); ctx != nil {
+			b.connect(in, ctx.head)
+			return nil
+		}
+	}
+
+	// goto and fallthrough are drawn as steps
+	n := b.node(b.text(s), diagram.ShapeBox)
+	b.connect(in, n)
+	return []exit{{from: n}}
+}
+
+// push enters a loop, switch or select, claiming any pending label
+func (b *flowBuilder) push(loop bool, head string) *flowContext {
+	ctx := &flowContext{label: b.label, loop: loop, head: head}
+	b.label = ""
+	b.contexts = append(b.contexts, ctx)
+	return ctx
+}
+
+// pop leaves the innermost loop, switch or select
+func (b *flowBuilder) pop() {
+	b.contexts = b.contexts[:len(b.contexts)-1]
+}
+
+// find returns the context a break or continue refers to: the one with the
+// label, or the innermost (loop, for continue)
+func (b *flowBuilder) find(label string, loopOnly bool) *flowContext {
+	for i := len(b.contexts) - 1; i >= 0; i-- {
+		ctx := b.contexts[i]
+		if label != "" {
+			if ctx.label == label {
+				return ctx
+			}
+			continue
+		}
+		if ctx.loop || !loopOnly {
+			return ctx
+		}
+	}
+	return nil
+}
+
+// text renders a node's source on one line, shortened for a label
+func (b *flowBuilder) text(n ast.Node) string {
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, b.fset, n); err != nil {
+		return fmt.Sprintf("%T", n)
+	}
+	return truncateLabel(strings.Join(strings.Fields(buf.String()), " "))
+}
+
+// expr renders an expression for a label
+func (b *flowBuilder) expr(e ast.Expr) string {
+	return truncateLabel(types.ExprString(e))
+}
+
+// exprList renders case expressions for an edge label
+func (b *flowBuilder) exprList(list []ast.Expr) string {
+	parts := make([]string, len(list))
+	for i, e := range list {
+		parts[i] = types.ExprString(e)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// isSimple reports whether a statement doesn't affect control flow
+func isSimple(s ast.Stmt) bool {
+	switch s := s.(type) {
+	case *ast.AssignStmt, *ast.IncDecStmt, *ast.DeclStmt, *ast.SendStmt, *ast.GoStmt, *ast.DeferStmt, *ast.EmptyStmt:
+		return true
+	case *ast.ExprStmt:
+		return !isPanic(s.X)
+	}
+	return false
+}
+
+// isPanic reports whether e is a call to panic
+func isPanic(e ast.Expr) bool {
+	call, ok := e.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	ident, ok := call.Fun.(*ast.Ident)
+	return ok && ident.Name == "panic"
+}
+
+// truncateLabel shortens s to maxLabelLength runes
+func truncateLabel(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maxLabelLength {
+		return s
+	}
+	return string(runes[:maxLabelLength-1]) + "…"
+}
@@ -0,0 +1,163 @@
+package gograph
+
+import (
+	"strings"
+	"testing"
+)
+
+const flowSource = `package app
+
+type Model struct{ n int }
+
+func (m *Model) Update(msg any) (int, error) {
+	m.n++
+	switch msg := msg.(type) {
+	case string:
+		if msg == "" {
+			return 0, nil
+		}
+	case int, int64:
+		for i := 0; i < 3; i++ {
+			if i == 1 {
+				continue
+			}
+			if i == 2 {
+				break
+			}
+		}
+	default:
+		panic("unknown message")
+	}
+	return m.n, nil
+}
+
+func Update() {}
+`
+
+func TestFuncFlow(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"app/model.go":      flowSource,
+		"app/model_test.go": "package app\n\nfunc TestUpdate() {}\n",
+	})
+
+	g, err := FuncFlow(dir, "app.Model.Update")
+	if err != nil {
+		t.Fatalf("FuncFlow() error = %v", err)
+	}
+	if g.Name != "app.Model.Update" || !strings.HasSuffix(g.Position, "model.go:5:1") {
+		t.Errorf("Name, Position = %q, %q", g.Name, g.Position)
+	}
+
+	got := g.Mermaid()
+	for _, want := range []string{
+		"graph TD",
+		`n1(["app.Model.Update()"])`,
+		`n2(["end"])`,
+		`n3["m.n++"]`,
+		`n4{"switch msg := msg.(type)"}`,
+		`n4 -->|"string"| n5`,
+		`n5{"msg == #quot;#quot;"}`,
+		`n5 -->|"yes"| n6`,
+		`n6("return 0, nil")`,
+		`n6 --> n2`,
+		`n7{"for i := 0; i < 3; i++"}`,
+		`n4 -->|"int, int64"| n7`,
+		`n7 -->|"loop"| n8`,
+		`n8 -->|"yes"| n7`,  // continue
+		`n9 -->|"no"| n7`,   // back round the loop
+		`n9 -->|"yes"| n11`, // break leaves the loop
+		`n7 -->|"done"| n11`,
+		`n4 -->|"default"| n10`,
+		`n10("panic(#quot;unknown message#quot;)")`,
+		`n5 -->|"no"| n11`,
+		`n11("return m.n, nil")`,
+		`n11 --> n2`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Mermaid() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestFuncFlow_Names(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"app/model.go": flowSource})
+
+	tests := []struct {
+		name    string
+		want    string
+		wantErr string
+	}{
+		{name: "app.Model.Update", want: "app.Model.Update"},
+		{name: "Model.Update", want: "app.Model.Update"},
+		{name: "app.Update", want: "app.Update"},
+		{name: "Update", wantErr: "ambiguous"},
+		{name: "other.Model.Update", wantErr: "not found"},
+		{name: "Missing", wantErr: "not found"},
+		{name: "a.b.c.d", wantErr: "invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := FuncFlow(dir, tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("FuncFlow() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FuncFlow() error = %v", err)
+			}
+			if g.Name != tt.want {
+				t.Errorf("Name = %q, want %q", g.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestFuncFlow_LabelOnlyClaimedByItsStatement(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"app/run.go": `package app
+
+func Run(items []int) {
+done:
+	if len(items) == 0 {
+		return
+	}
+	for range items {
+		break done
+	}
+}
+`})
+
+	g, err := FuncFlow(dir, "Run")
+	if err != nil {
+		t.Fatalf("FuncFlow() error = %v", err)
+	}
+	// done labels the if, so the loop doesn't take it and break done stays a step
+	if got := g.Mermaid(); !strings.Contains(got, `"break done"`) {
+		t.Errorf("Mermaid() = %s, want break done drawn as a step", got)
+	}
+}
+
+func TestFuncFlow_SkipsUnparsable(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"app/model.go":  flowSource,
+		"app/broken.go": "package app\n\nfunc Broken( {\n",
+	})
+
+	g, err := FuncFlow(dir, "app.Model.Update")
+	if err != nil {
+		t.Fatalf("FuncFlow() error = %v, want the broken file skipped", err)
+	}
+	if len(g.Skipped) != 1 || !strings.Contains(g.Skipped[0].Error(), "broken.go") {
+		t.Errorf("Skipped = %v, want broken.go reported", g.Skipped)
+	}
+
+	if _, err := FuncFlow(dir, "Broken"); err == nil || !strings.Contains(err.Error(), "broken.go") {
+		t.Errorf("FuncFlow(Broken) error = %v, want not found naming broken.go", err)
+	}
+}
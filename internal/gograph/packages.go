@@ -0,0 +1,193 @@
+package gograph
+
+import (
+	"bufio"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mnesler/hauk-tui/internal/diagram"
+)
+
+// PackageGraph is the import graph between the packages of one module
+type PackageGraph struct {
+	Module   string              // Module path from go.mod
+	Packages []string            // Import paths, sorted
+	Imports  map[string][]string // Package to the module packages it imports, sorted
+	Skipped  []error             // Why packages were left out, one per package
+}
+
+// Packages parses the Go packages under dir and returns the imports between
+// packages of the module dir belongs to. Test files, vendor and testdata
+// directories are skipped, and so are packages with a file that doesn't
+// parse; those are listed in Skipped.
+func Packages(dir string) (*PackageGraph, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	root, module, err := findModule(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &PackageGraph{Module: module, Imports: make(map[string][]string)}
+	imports := make(map[string]map[string]bool)
+	skipped := make(map[string]error)
+	fset := token.NewFileSet()
+
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != dir && (skipDir(d.Name()) || isModuleRoot(p)) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(p, ".go") || strings.HasSuffix(p, "_test.go") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		pkg := path.Join(module, filepath.ToSlash(rel))
+		if skipped[pkg] != nil {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, p, nil, parser.ImportsOnly)
+		if err != nil {
+			skipped[pkg] = err
+			delete(imports, pkg)
+			return nil
+		}
+		if imports[pkg] == nil {
+			imports[pkg] = make(map[string]bool)
+		}
+		for _, spec := range file.Imports {
+			imported, err := strconv.Unquote(spec.Path.Value)
+			if err == nil && (imported == module || strings.HasPrefix(imported, module+"/")) {
+				imports[pkg][imported] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs := make([]string, 0, len(skipped))
+	for pkg := range skipped {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		g.Skipped = append(g.Skipped, fmt.Errorf("skipped %s: %w", pkg, skipped[pkg]))
+	}
+
+	if len(imports) == 0 {
+		if len(g.Skipped) > 0 {
+			return nil, g.Skipped[0]
+		}
+		return nil, fmt.Errorf("no Go packages found under %s", dir)
+	}
+
+	for pkg, deps := range imports {
+		g.Packages = append(g.Packages, pkg)
+		for dep := range deps {
+			// Only draw edges to packages that were scanned
+			if _, ok := imports[dep]; ok && dep != pkg {
+				g.Imports[pkg] = append(g.Imports[pkg], dep)
+			}
+		}
+		sort.Strings(g.Imports[pkg])
+	}
+	sort.Strings(g.Packages)
+	return g, nil
+}
+
+// Mermaid renders the graph as a flowchart, labelling packages by their
+// path within the module
+func (g *PackageGraph) Mermaid() string {
+	// Labels are unique within a module and make shorter node IDs
+	f := diagram.NewFlowchart("TD")
+	for _, pkg := range g.Packages {
+		f.Node(g.label(pkg), g.label(pkg), diagram.ShapeBox)
+	}
+	for _, pkg := range g.Packages {
+		for _, dep := range g.Imports[pkg] {
+			f.Edge(g.label(pkg), g.label(dep), "")
+		}
+	}
+	return f.String()
+}
+
+// label shortens an import path to its path within the module, "." for
+// the module's root package
+func (g *PackageGraph) label(pkg string) string {
+	if pkg == g.Module {
+		return "."
+	}
+	return strings.TrimPrefix(pkg, g.Module+"/")
+}
+
+// findModule walks up from dir to the nearest go.mod, returning its
+// directory and module path
+func findModule(dir string) (root, module string, err error) {
+	for d := dir; ; d = filepath.Dir(d) {
+		module, err := readModulePath(filepath.Join(d, "go.mod"))
+		if err == nil {
+			return d, module, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", "", err
+		}
+		if filepath.Dir(d) == d {
+			return "", "", fmt.Errorf("no go.mod found in %s or any parent directory", dir)
+		}
+	}
+}
+
+// readModulePath returns the module path declared in a go.mod file
+func readModulePath(goMod string) (string, error) {
+	f, err := os.Open(goMod)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("%s has no module directive", goMod)
+}
+
+// skipDir reports whether a directory should not be scanned for packages
+func skipDir(name string) bool {
+	return name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")
+}
+
+// isModuleRoot reports whether dir holds its own go.mod, making it a separate module
+func isModuleRoot(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, "go.mod"))
+	return err == nil
+}
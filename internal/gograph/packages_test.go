@@ -0,0 +1,155 @@
+package gograph
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files under dir
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// newModule writes a small module: cmd/tool → internal/app → internal/chat, plus noise
+func newModule(t *testing.T) string {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"go.mod":                    "module example.com/tool\n\ngo 1.24\n",
+		"cmd/tool/main.go":          "package main\n\nimport (\n\t\"fmt\"\n\t\"example.com/tool/internal/app\"\n)\n",
+		"internal/app/app.go":       "package app\n\nimport \"example.com/tool/internal/chat\"\n",
+		"internal/app/app_test.go":  "package app\n\nimport \"example.com/tool/internal/testutil\"\n",
+		"internal/chat/chat.go":     "package chat\n\nimport \"strings\"\n",
+		"internal/testutil/util.go": "package testutil\n",
+		"vendor/x/x.go":             "package x\n",
+		"testdata/bad.go":           "this is not go",
+		"tools/go.mod":              "module example.com/tool/tools\n",
+		"tools/gen.go":              "package tools\n",
+	})
+	return dir
+}
+
+func TestPackages(t *testing.T) {
+	dir := newModule(t)
+
+	g, err := Packages(dir)
+	if err != nil {
+		t.Fatalf("Packages() error = %v", err)
+	}
+
+	if g.Module != "example.com/tool" {
+		t.Errorf("Module = %q", g.Module)
+	}
+	wantPackages := []string{
+		"example.com/tool/cmd/tool",
+		"example.com/tool/internal/app",
+		"example.com/tool/internal/chat",
+		"example.com/tool/internal/testutil",
+	}
+	if !reflect.DeepEqual(g.Packages, wantPackages) {
+		t.Errorf("Packages = %v, want %v", g.Packages, wantPackages)
+	}
+
+	wantImports := map[string][]string{
+		"example.com/tool/cmd/tool":     {"example.com/tool/internal/app"},
+		"example.com/tool/internal/app": {"example.com/tool/internal/chat"},
+	}
+	if !reflect.DeepEqual(g.Imports, wantImports) {
+		t.Errorf("Imports = %v, want %v (test imports and stdlib excluded)", g.Imports, wantImports)
+	}
+}
+
+func TestPackages_Subdirectory(t *testing.T) {
+	dir := newModule(t)
+
+	g, err := Packages(filepath.Join(dir, "internal"))
+	if err != nil {
+		t.Fatalf("Packages() error = %v", err)
+	}
+	if len(g.Packages) != 3 {
+		t.Errorf("Packages = %v, want only those under internal/", g.Packages)
+	}
+	if len(g.Imports["example.com/tool/internal/app"]) != 1 {
+		t.Errorf("Imports = %v, want app → chat", g.Imports)
+	}
+}
+
+func TestPackages_Errors(t *testing.T) {
+	if _, err := Packages(t.TempDir()); err == nil {
+		t.Error("Packages() outside a module should fail")
+	}
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"go.mod": "module example.com/empty\n"})
+	if _, err := Packages(dir); err == nil {
+		t.Error("Packages() of a module without Go files should fail")
+	}
+}
+
+func TestPackageGraph_Mermaid(t *testing.T) {
+	g, err := Packages(newModule(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := g.Mermaid()
+	for _, want := range []string{
+		"graph TD",
+		`cmd_tool["cmd/tool"]`,
+		`internal_app["internal/app"]`,
+		"cmd_tool --> internal_app",
+		"internal_app --> internal_chat",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Mermaid() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPackageGraph_Mermaid_RootPackage(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"go.mod":           "module example.com/tool\n",
+		"tool.go":          "package tool\n",
+		"cmd/tool/main.go": "package main\n\nimport \"example.com/tool\"\n",
+	})
+	g, err := Packages(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The root package doesn't take the name of cmd/tool
+	got := g.Mermaid()
+	for _, want := range []string{`node["."]`, `cmd_tool["cmd/tool"]`, "cmd_tool --> node"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Mermaid() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestPackages_SkipsUnparsable(t *testing.T) {
+	dir := newModule(t)
+	writeTree(t, dir, map[string]string{"internal/broken/broken.go": "package broken\n\nimport (\n"})
+
+	g, err := Packages(dir)
+	if err != nil {
+		t.Fatalf("Packages() error = %v, want the broken package skipped", err)
+	}
+	if len(g.Packages) != 4 {
+		t.Errorf("Packages = %v, want the four that parse", g.Packages)
+	}
+	if len(g.Skipped) != 1 || !strings.Contains(g.Skipped[0].Error(), "example.com/tool/internal/broken") {
+		t.Errorf("Skipped = %v, want internal/broken reported", g.Skipped)
+	}
+}
@@ -0,0 +1,302 @@
+package gograph
+
+import (
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// TypeGraph describes the named struct and interface types of one package
+// and how they relate
+type TypeGraph struct {
+	Package    string
+	Types      []*TypeInfo // Sorted by name
+	Embeds     []Relation  // Struct embeds a type
+	Implements []Relation  // Type (From) implements interface (To)
+	References []Relation  // Struct field refers to another type in the package
+}
+
+// TypeInfo is a struct or interface
+type TypeInfo struct {
+	Name      string
+	Interface bool
+	Members   []Member // Fields for structs, methods for interfaces
+}
+
+// Member is a struct field or interface method
+type Member struct {
+	Name     string
+	Type     string // Field type, or method signature without "func"
+	Exported bool
+}
+
+// Relation links two types, labelled for references
+type Relation struct {
+	From, To string
+	Label    string
+}
+
+// Types type-checks the Go package in dir (test files excluded) and
+// returns its struct and interface types. Imports that can't be resolved
+// don't stop the check; types from them are just left unresolved.
+func Types(dir string) (*TypeGraph, error) {
+	fset := token.NewFileSet()
+	files, err := parseDir(fset, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := types.Config{
+		Importer: importer.Default(),
+		Error:    func(error) {}, // Keep going past unresolved imports
+	}
+	info := &types.Info{Defs: make(map[*ast.Ident]types.Object)}
+	pkg, _ := conf.Check(files[0].Name.Name, fset, files, info)
+	if pkg == nil {
+		return nil, fmt.Errorf("failed to type-check %s", dir)
+	}
+	source := sourceTypes(files, info)
+
+	g := &TypeGraph{Package: pkg.Name()}
+	scope := pkg.Scope()
+
+	// Collect named structs and interfaces
+	var named []*types.Named
+	for _, name := range scope.Names() {
+		tn, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || tn.IsAlias() {
+			continue
+		}
+		n, ok := tn.Type().(*types.Named)
+		if !ok {
+			continue
+		}
+		switch n.Underlying().(type) {
+		case *types.Struct, *types.Interface:
+			named = append(named, n)
+		}
+	}
+
+	local := make(map[string]bool)
+	for _, n := range named {
+		local[n.Obj().Name()] = true
+	}
+	qualifier := types.RelativeTo(pkg)
+
+	for _, n := range named {
+		ti := &TypeInfo{Name: n.Obj().Name()}
+
+		switch u := n.Underlying().(type) {
+		case *types.Struct:
+			for i := 0; i < u.NumFields(); i++ {
+				field := u.Field(i)
+				fieldType := typeText(field, qualifier, source)
+
+				if field.Embedded() {
+					if target := localName(field.Type(), local); target != "" {
+						g.Embeds = append(g.Embeds, Relation{From: ti.Name, To: target})
+					}
+				} else if target := localName(field.Type(), local); target != "" && target != ti.Name {
+					g.References = append(g.References, Relation{From: ti.Name, To: target, Label: field.Name()})
+				}
+
+				ti.Members = append(ti.Members, Member{
+					Name:     field.Name(),
+					Type:     simplifyType(fieldType),
+					Exported: field.Exported(),
+				})
+			}
+
+		case *types.Interface:
+			ti.Interface = true
+			for i := 0; i < u.NumExplicitMethods(); i++ {
+				method := u.ExplicitMethod(i)
+				sig := strings.TrimPrefix(typeText(method, qualifier, source), "func")
+				ti.Members = append(ti.Members, Member{
+					Name:     method.Name(),
+					Type:     simplifyType(sig),
+					Exported: method.Exported(),
+				})
+			}
+			for i := 0; i < u.NumEmbeddeds(); i++ {
+				if target := localName(u.EmbeddedType(i), local); target != "" {
+					g.Embeds = append(g.Embeds, Relation{From: ti.Name, To: target})
+				}
+			}
+		}
+
+		g.Types = append(g.Types, ti)
+	}
+
+	// Which concrete types implement which interfaces, by value or pointer
+	for _, iface := range named {
+		it, ok := iface.Underlying().(*types.Interface)
+		if !ok || it.NumMethods() == 0 {
+			continue
+		}
+		for _, n := range named {
+			if _, isIface := n.Underlying().(*types.Interface); isIface {
+				continue
+			}
+			if types.Implements(n, it) || types.Implements(types.NewPointer(n), it) {
+				g.Implements = append(g.Implements, Relation{From: n.Obj().Name(), To: iface.Obj().Name()})
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// Mermaid renders the graph as a classDiagram
+func (g *TypeGraph) Mermaid() string {
+	lines := []string{"classDiagram"}
+
+	for _, t := range g.Types {
+		lines = append(lines, fmt.Sprintf("    class %s {", t.Name))
+		if t.Interface {
+			lines = append(lines, "        <<interface>>")
+		}
+		for _, m := range t.Members {
+			visibility := "-"
+			if m.Exported {
+				visibility = "+"
+			}
+			if t.Interface {
+				lines = append(lines, fmt.Sprintf("        %s%s%s", visibility, m.Name, m.Type))
+			} else {
+				lines = append(lines, fmt.Sprintf("        %s%s %s", visibility, m.Type, m.Name))
+			}
+		}
+		lines = append(lines, "    }")
+	}
+
+	for _, r := range g.Embeds {
+		lines = append(lines, fmt.Sprintf("    %s *-- %s : embeds", r.From, r.To))
+	}
+	for _, r := range g.Implements {
+		lines = append(lines, fmt.Sprintf("    %s <|.. %s", r.To, r.From))
+	}
+	for _, r := range g.References {
+		lines = append(lines, fmt.Sprintf("    %s --> %s : %s", r.From, r.To, r.Label))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// parseDir parses the non-test Go files of the package in dir
+func parseDir(fset *token.FileSet, dir string) ([]*ast.File, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() && strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	var files []*ast.File
+	for _, name := range names {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		// Files of another package (e.g. package main tools) are skipped
+		if len(files) > 0 && file.Name.Name != files[0].Name.Name {
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// sourceTypes maps struct fields and interface methods to their type as
+// written, for when the checker couldn't resolve it
+func sourceTypes(files []*ast.File, info *types.Info) map[types.Object]string {
+	source := make(map[types.Object]string)
+	record := func(fields *ast.FieldList) {
+		for _, field := range fields.List {
+			for _, name := range field.Names {
+				if obj := info.Defs[name]; obj != nil {
+					source[obj] = types.ExprString(field.Type)
+				}
+			}
+		}
+	}
+
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch t := n.(type) {
+			case *ast.StructType:
+				record(t.Fields)
+			case *ast.InterfaceType:
+				record(t.Methods)
+			}
+			return true
+		})
+	}
+	return source
+}
+
+// typeText renders the type of obj, falling back to the source text when
+// it refers to something that couldn't be resolved
+func typeText(obj types.Object, qualifier types.Qualifier, source map[types.Object]string) string {
+	text := types.TypeString(obj.Type(), qualifier)
+	if strings.Contains(text, "invalid type") {
+		if written, ok := source[obj]; ok {
+			return written
+		}
+		if v, ok := obj.(*types.Var); ok && v.Embedded() {
+			return v.Name()
+		}
+	}
+	return text
+}
+
+// localName returns the name of the package type t refers to, through
+// pointers, slices, arrays and maps, or "" if it isn't one of local
+func localName(t types.Type, local map[string]bool) string {
+	for {
+		switch u := t.(type) {
+		case *types.Pointer:
+			t = u.Elem()
+		case *types.Slice:
+			t = u.Elem()
+		case *types.Array:
+			t = u.Elem()
+		case *types.Map:
+			t = u.Elem()
+		case *types.Named:
+			if local[u.Obj().Name()] && u.Obj().Pkg() != nil {
+				return u.Obj().Name()
+			}
+			return ""
+		default:
+			return ""
+		}
+	}
+}
+
+// simplifyType rewrites type strings mermaid's class syntax chokes on
+func simplifyType(s string) string {
+	return strings.NewReplacer(
+		"interface{}", "any",
+		"struct{}", "struct",
+		"{", "(",
+		"}", ")",
+	).Replace(s)
+}
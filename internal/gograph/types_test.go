@@ -0,0 +1,129 @@
+package gograph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const shapesSource = `package shapes
+
+import (
+	"fmt"
+	"example.com/missing/dep"
+)
+
+// Shape is anything with an area
+type Shape interface {
+	Area() float64
+	fmt.Stringer
+}
+
+// Named is embedded by shapes
+type Named struct {
+	Name string
+	tags map[string]struct{}
+}
+
+type Circle struct {
+	Named
+	Radius float64
+	Center *Point
+}
+
+func (c Circle) Area() float64   { return 3.14 * c.Radius * c.Radius }
+func (c Circle) String() string  { return c.Name }
+
+type Square struct {
+	Side  float64
+	Extra dep.Thing
+}
+
+func (s *Square) Area() float64  { return s.Side * s.Side }
+func (s *Square) String() string { return "square" }
+
+type Point struct{ X, Y int }
+
+type Canvas struct {
+	Shapes []Shape
+}
+
+type ID = string
+`
+
+func TestTypes(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{
+		"shapes.go":      shapesSource,
+		"shapes_test.go": "package shapes\n\ntype testOnly struct{}\n",
+	})
+
+	g, err := Types(dir)
+	if err != nil {
+		t.Fatalf("Types() error = %v", err)
+	}
+
+	var names []string
+	for _, ty := range g.Types {
+		names = append(names, ty.Name)
+	}
+	if want := []string{"Canvas", "Circle", "Named", "Point", "Shape", "Square"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Types = %v, want %v", names, want)
+	}
+
+	wantEmbeds := []Relation{{From: "Circle", To: "Named"}}
+	if !reflect.DeepEqual(g.Embeds, wantEmbeds) {
+		t.Errorf("Embeds = %v, want %v", g.Embeds, wantEmbeds)
+	}
+
+	wantImplements := []Relation{{From: "Circle", To: "Shape"}, {From: "Square", To: "Shape"}}
+	if !reflect.DeepEqual(g.Implements, wantImplements) {
+		t.Errorf("Implements = %v, want %v", g.Implements, wantImplements)
+	}
+
+	wantRefs := []Relation{
+		{From: "Canvas", To: "Shape", Label: "Shapes"},
+		{From: "Circle", To: "Point", Label: "Center"},
+	}
+	if !reflect.DeepEqual(g.References, wantRefs) {
+		t.Errorf("References = %v, want %v", g.References, wantRefs)
+	}
+}
+
+func TestTypeGraph_Mermaid(t *testing.T) {
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"shapes.go": shapesSource})
+
+	g, err := Types(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := g.Mermaid()
+	for _, want := range []string{
+		"classDiagram",
+		"class Shape {\n        <<interface>>\n        +Area() float64\n    }",
+		"+float64 Radius",
+		"-map[string]struct tags",
+		"Circle *-- Named : embeds",
+		"Shape <|.. Square",
+		"Canvas --> Shape : Shapes",
+		"+dep.Thing Extra", // Unresolved import falls back to the source text
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Mermaid() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestTypes_Errors(t *testing.T) {
+	if _, err := Types(t.TempDir()); err == nil {
+		t.Error("Types() of a directory without Go files should fail")
+	}
+
+	dir := t.TempDir()
+	writeTree(t, dir, map[string]string{"bad.go": "package bad\n\nfunc {"})
+	if _, err := Types(dir); err == nil {
+		t.Error("Types() of unparseable source should fail")
+	}
+}
@@ -0,0 +1,268 @@
+package highlight
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/ui"
+)
+
+// Kind classifies a token
+type Kind int
+
+const (
+	Plain Kind = iota
+	Keyword
+	Type
+	String
+	Number
+	Comment
+	Operator
+	Key
+	Variable
+)
+
+// Token is a run of source text of a single kind
+type Token struct {
+	Kind Kind
+	Text string
+}
+
+// Palette maps token kinds to styles
+type Palette map[Kind]lipgloss.Style
+
+// ThemePalette derives a palette from a theme's code, secondary text and
+// user accent colors, drawn on bg
+func ThemePalette(theme *ui.Theme, bg lipgloss.Color) Palette {
+	base := lipgloss.NewStyle().Background(bg)
+	code := base.Foreground(theme.AccentCode)
+	accent := base.Foreground(theme.AccentUser)
+	secondary := base.Foreground(theme.TextSecondary)
+
+	return Palette{
+		Plain:    code,
+		Keyword:  accent.Bold(true),
+		Type:     accent,
+		String:   secondary,
+		Number:   accent,
+		Comment:  secondary.Italic(true).Faint(true),
+		Operator: accent,
+		Key:      accent,
+		Variable: code.Bold(true),
+	}
+}
+
+// Tokenize splits src into tokens for the given fence language. Unknown
+// languages produce a single plain token.
+func Tokenize(lang, src string) []Token {
+	l := lookup(lang)
+	if l == nil {
+		return []Token{{Kind: Plain, Text: src}}
+	}
+	return l.tokenize(src)
+}
+
+// Highlight renders lines of code in lang with the palette, one output line per input line
+func Highlight(lang string, lines []string, palette Palette) []string {
+	tokens := Tokenize(lang, strings.Join(lines, "\n"))
+
+	out := make([]string, 0, len(lines))
+	var current strings.Builder
+	for _, tok := range tokens {
+		style := palette[tok.Kind]
+
+		// Tokens may span lines (block comments); style each piece separately
+		pieces := strings.Split(tok.Text, "\n")
+		for i, piece := range pieces {
+			if i > 0 {
+				out = append(out, current.String())
+				current.Reset()
+			}
+			if piece != "" {
+				current.WriteString(style.Render(piece))
+			}
+		}
+	}
+	out = append(out, current.String())
+
+	return out
+}
+
+// tokenize scans src according to the language definition
+func (l *language) tokenize(src string) []Token {
+	var tokens []Token
+
+	// push appends text, merging with the previous token of the same kind
+	push := func(kind Kind, text string) {
+		if n := len(tokens); n > 0 && tokens[n-1].Kind == kind {
+			tokens[n-1].Text += text
+			return
+		}
+		tokens = append(tokens, Token{Kind: kind, Text: text})
+	}
+
+	for i := 0; i < len(src); {
+		rest := src[i:]
+
+		if n := l.matchComment(rest); n > 0 {
+			push(Comment, rest[:n])
+			i += n
+			continue
+		}
+
+		c := rune(src[i])
+		switch {
+		case strings.ContainsRune(l.stringQuotes, c):
+			n := scanString(rest)
+			kind := String
+			if l.keysBeforeColon && followedByColon(src[i+n:]) {
+				kind = Key
+			}
+			push(kind, rest[:n])
+			i += n
+
+		case l.variables && c == '$':
+			n := scanVariable(rest)
+			push(Variable, rest[:n])
+			i += n
+
+		case unicode.IsDigit(c) && (i == 0 || !isIdentByte(src[i-1])):
+			n := scanNumber(rest)
+			push(Number, rest[:n])
+			i += n
+
+		case isIdentByte(src[i]):
+			n := scanIdent(rest)
+			push(l.classify(rest[:n], src[i+n:]), rest[:n])
+			i += n
+
+		default:
+			if op := l.matchOperator(rest); op != "" {
+				push(Operator, op)
+				i += len(op)
+				continue
+			}
+			push(Plain, rest[:1])
+			i++
+		}
+	}
+
+	return tokens
+}
+
+// classify decides the kind of an identifier given the text that follows it
+func (l *language) classify(word, after string) Kind {
+	if l.keysBeforeColon && followedByColon(after) {
+		return Key
+	}
+
+	lookupWord := word
+	if l.ignoreCase {
+		lookupWord = strings.ToLower(word)
+	}
+	switch {
+	case l.keywords[lookupWord]:
+		return Keyword
+	case l.types[lookupWord]:
+		return Type
+	}
+	return Plain
+}
+
+// matchComment returns the length of a comment starting at s, or 0
+func (l *language) matchComment(s string) int {
+	for _, prefix := range l.lineComments {
+		if strings.HasPrefix(s, prefix) {
+			if end := strings.IndexByte(s, '\n'); end >= 0 {
+				return end
+			}
+			return len(s)
+		}
+	}
+
+	open, closing := l.blockComment[0], l.blockComment[1]
+	if open != "" && strings.HasPrefix(s, open) {
+		if end := strings.Index(s[len(open):], closing); end >= 0 {
+			return len(open) + end + len(closing)
+		}
+		return len(s)
+	}
+
+	return 0
+}
+
+// matchOperator returns the longest operator starting at s, or ""
+func (l *language) matchOperator(s string) string {
+	for _, op := range l.operators {
+		if strings.HasPrefix(s, op) {
+			return op
+		}
+	}
+	return ""
+}
+
+// scanString returns the length of the quoted string starting at s,
+// stopping at the end of the line if it is unterminated
+func scanString(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// scanVariable returns the length of a shell variable reference at s
+func scanVariable(s string) int {
+	if len(s) > 1 && s[1] == '{' {
+		if end := strings.IndexByte(s, '}'); end >= 0 {
+			return end + 1
+		}
+	}
+	n := 1
+	for n < len(s) && isIdentByte(s[n]) {
+		n++
+	}
+	return n
+}
+
+// scanNumber returns the length of the number literal at s
+func scanNumber(s string) int {
+	n := 0
+	for n < len(s) && (isIdentByte(s[n]) || s[n] == '.') {
+		n++
+	}
+	return n
+}
+
+// scanIdent returns the length of the identifier at s
+func scanIdent(s string) int {
+	n := 0
+	for n < len(s) && isIdentByte(s[n]) {
+		n++
+	}
+	return n
+}
+
+// followedByColon reports whether s starts with optional spaces then a colon
+// that isn't part of a longer operator like :: or :=
+func followedByColon(s string) bool {
+	s = strings.TrimLeft(s, " \t")
+	return strings.HasPrefix(s, ":") && !strings.HasPrefix(s, "::") && !strings.HasPrefix(s, ":=")
+}
+
+// isIdentByte reports whether c can appear in an identifier
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
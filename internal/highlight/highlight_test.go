@@ -0,0 +1,142 @@
+package highlight
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/ui"
+)
+
+// kinds returns the kind of each non-whitespace token's text
+func kinds(tokens []Token) map[string]Kind {
+	found := make(map[string]Kind)
+	for _, tok := range tokens {
+		if strings.TrimSpace(tok.Text) != "" {
+			found[strings.TrimSpace(tok.Text)] = tok.Kind
+		}
+	}
+	return found
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		lang string
+		src  string
+		want map[string]Kind
+	}{
+		{
+			name: "go",
+			lang: "go",
+			src:  "func main() {\n\tx := \"hi\" // greet\n\treturn 42\n}",
+			want: map[string]Kind{"func": Keyword, ":=": Operator, "\"hi\"": String, "// greet": Comment, "return": Keyword, "42": Number},
+		},
+		{
+			name: "go block comment",
+			lang: "golang",
+			src:  "/* a\nb */ var s string",
+			want: map[string]Kind{"/* a\nb */": Comment, "var": Keyword, "string": Type},
+		},
+		{
+			name: "mermaid",
+			lang: "mermaid",
+			src:  "graph TD\n    A[Start] --> B\n    %% note",
+			want: map[string]Kind{"graph": Keyword, "TD": Keyword, "-->": Operator, "%% note": Comment},
+		},
+		{
+			name: "json",
+			lang: "json",
+			src:  `{"name": "hauk", "stars": 5, "ok": true}`,
+			want: map[string]Kind{`"name"`: Key, `"hauk"`: String, "5": Number, "true": Type},
+		},
+		{
+			name: "yaml",
+			lang: "yml",
+			src:  "theme: dracula # comment\ncount: 3",
+			want: map[string]Kind{"theme": Key, "# comment": Comment, "count": Key, "3": Number},
+		},
+		{
+			name: "sql is case insensitive",
+			lang: "sql",
+			src:  "SELECT id FROM users -- all\nwhere id = 'x'",
+			want: map[string]Kind{"SELECT": Keyword, "FROM": Keyword, "where": Keyword, "-- all": Comment, "'x'": String},
+		},
+		{
+			name: "shell",
+			lang: "bash",
+			src:  "export NAME=\"$HOME\" && echo ${NAME} # done",
+			want: map[string]Kind{"export": Keyword, "\"$HOME\"": String, "&&": Operator, "echo": Type, "${NAME}": Variable, "# done": Comment},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := kinds(Tokenize(tt.lang, tt.src))
+			for text, want := range tt.want {
+				if kind, ok := got[text]; !ok || kind != want {
+					t.Errorf("Token %q kind = %v (found %t), want %v", text, kind, ok, want)
+				}
+			}
+		})
+	}
+}
+
+func TestTokenize_PreservesSource(t *testing.T) {
+	src := "func f() {\n\ts := `raw\nstring` /* x */\n}\n"
+	var b strings.Builder
+	for _, tok := range Tokenize("go", src) {
+		b.WriteString(tok.Text)
+	}
+	if b.String() != src {
+		t.Errorf("Concatenated tokens = %q, want %q", b.String(), src)
+	}
+}
+
+func TestTokenize_Unknown(t *testing.T) {
+	tokens := Tokenize("brainfuck", "+++")
+	if len(tokens) != 1 || tokens[0].Kind != Plain || tokens[0].Text != "+++" {
+		t.Errorf("Tokenize(unknown) = %+v, want single plain token", tokens)
+	}
+	if Supported("brainfuck") {
+		t.Error("Supported(brainfuck) = true, want false")
+	}
+	if !Supported(" Go ") {
+		t.Error("Supported(\" Go \") = false, want true")
+	}
+}
+
+func TestHighlight_KeepsLines(t *testing.T) {
+	lines := []string{"/* spans", "two lines */", "", "var x = 1"}
+	out := Highlight("go", lines, ThemePalette(ui.ActiveTheme, ui.ActiveTheme.ChatBg))
+
+	if len(out) != len(lines) {
+		t.Fatalf("Highlight() returned %d lines, want %d", len(out), len(lines))
+	}
+	for i := range lines {
+		if w := lipgloss.Width(out[i]); w != len(lines[i]) {
+			t.Errorf("Line %d width = %d, want %d", i, w, len(lines[i]))
+		}
+	}
+}
+
+func TestThemePalette(t *testing.T) {
+	theme := ui.GetTheme("dracula")
+	palette := ThemePalette(theme, theme.ChatBg)
+
+	tests := []struct {
+		kind Kind
+		want lipgloss.Color
+	}{
+		{Plain, theme.AccentCode},
+		{Keyword, theme.AccentUser},
+		{String, theme.TextSecondary},
+		{Comment, theme.TextSecondary},
+	}
+
+	for _, tt := range tests {
+		if got := palette[tt.kind].GetForeground(); got != tt.want {
+			t.Errorf("Palette[%v] foreground = %v, want %v", tt.kind, got, tt.want)
+		}
+	}
+}
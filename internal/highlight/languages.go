@@ -0,0 +1,120 @@
+package highlight
+
+import "strings"
+
+// language describes how to tokenize one source language
+type language struct {
+	keywords        map[string]bool
+	types           map[string]bool // Builtin types and constants
+	lineComments    []string
+	blockComment    [2]string // Opening and closing delimiters, empty if unsupported
+	stringQuotes    string    // Characters that open and close strings
+	operators       []string  // Multi-character operators, longest first
+	ignoreCase      bool      // Keywords match case-insensitively (SQL)
+	keysBeforeColon bool      // Words or strings followed by ':' are keys (YAML, JSON)
+	variables       bool      // $NAME and ${NAME} are variables (shell)
+}
+
+// words builds a lookup set from a space-separated list
+func words(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(list) {
+		set[w] = true
+	}
+	return set
+}
+
+var goLang = &language{
+	keywords: words(`break case chan const continue default defer else fallthrough for func go goto
+		if import interface map package range return select struct switch type var`),
+	types: words(`bool byte complex64 complex128 error float32 float64 int int8 int16 int32 int64
+		rune string uint uint8 uint16 uint32 uint64 uintptr any comparable
+		true false nil iota append cap close copy delete len make new panic print println recover`),
+	lineComments: []string{"//"},
+	blockComment: [2]string{"/*", "*/"},
+	stringQuotes: "\"'`",
+	operators:    []string{"<-", ":=", "==", "!=", "<=", ">=", "&&", "||", "..."},
+}
+
+var mermaidLang = &language{
+	keywords: words(`graph flowchart subgraph end direction TD TB BT LR RL
+		sequenceDiagram participant actor note over left right of loop alt else opt par and critical break rect
+		autonumber activate deactivate classDiagram class erDiagram stateDiagram state
+		gitGraph commit branch checkout merge journey gantt pie mindmap timeline
+		C4Context C4Container C4Component Person System System_Ext Container ContainerDb Component Rel Boundary
+		style classDef linkStyle click`),
+	lineComments: []string{"%%"},
+	stringQuotes: "\"",
+	operators: []string{"<|--", "--|>", "||--o{", "}o--||", "|o--o|", "-->>", "-.->", "--->", "-->",
+		"==>", "---", "->>", "--x", "--o", "->", "*--", "o--", "..>", "..", "--", ":::"},
+}
+
+var jsonLang = &language{
+	types:           words(`true false null`),
+	stringQuotes:    "\"",
+	keysBeforeColon: true,
+}
+
+var yamlLang = &language{
+	types:           words(`true false null yes no on off ~`),
+	lineComments:    []string{"#"},
+	stringQuotes:    "\"'",
+	operators:       []string{"---", "...", "- ", "|-", ">-"},
+	keysBeforeColon: true,
+}
+
+var sqlLang = &language{
+	keywords: words(`select from where and or not insert into values update set delete create table
+		alter drop index view primary key foreign references on join left right inner outer full cross
+		group by order having limit offset as distinct union all is null default unique check constraint
+		if exists cascade begin commit rollback transaction returning with case when then else end
+		asc desc like in between autoincrement auto_increment serial`),
+	types: words(`int integer smallint bigint decimal numeric real float double precision boolean bool
+		char varchar text date time timestamp timestamptz uuid json jsonb blob bytea true false`),
+	lineComments: []string{"--"},
+	blockComment: [2]string{"/*", "*/"},
+	stringQuotes: "'\"`",
+	operators:    []string{"<=", ">=", "<>", "!=", "::", "||"},
+	ignoreCase:   true,
+}
+
+var shellLang = &language{
+	keywords: words(`if then else elif fi for do done while until case esac function in return
+		export local readonly source exit break continue`),
+	types:        words(`echo cd ls cat grep sed awk git go make docker kubectl curl true false`),
+	lineComments: []string{"#"},
+	stringQuotes: "\"'",
+	operators:    []string{"&&", "||", ">>", "<<", "|&", "2>&1"},
+	variables:    true,
+}
+
+// languages maps fence info strings to language definitions
+var languages = map[string]*language{
+	"go":         goLang,
+	"golang":     goLang,
+	"mermaid":    mermaidLang,
+	"mmd":        mermaidLang,
+	"json":       jsonLang,
+	"yaml":       yamlLang,
+	"yml":        yamlLang,
+	"sql":        sqlLang,
+	"postgres":   sqlLang,
+	"postgresql": sqlLang,
+	"mysql":      sqlLang,
+	"sqlite":     sqlLang,
+	"sh":         shellLang,
+	"bash":       shellLang,
+	"shell":      shellLang,
+	"zsh":        shellLang,
+	"console":    shellLang,
+}
+
+// lookup returns the language for a fence info string, or nil if unsupported
+func lookup(lang string) *language {
+	return languages[strings.ToLower(strings.TrimSpace(lang))]
+}
+
+// Supported reports whether lang has a highlighter
+func Supported(lang string) bool {
+	return lookup(lang) != nil
+}
@@ -0,0 +1,188 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mnesler/hauk-tui/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultLimit is the maximum number of prompts kept on disk
+const DefaultLimit = 1000
+
+// History holds prompts submitted in this session and in previous ones
+type History struct {
+	global  []string // Loaded from disk, oldest first
+	session []string // Submitted during this session, oldest first
+	saved   int      // How many session prompts are already on disk
+	limit   int
+}
+
+// file is the on-disk representation of the history
+type file struct {
+	Prompts []string `yaml:"prompts"`
+}
+
+// New creates an empty history
+func New() *History {
+	return &History{
+		global:  make([]string, 0),
+		session: make([]string, 0),
+		limit:   DefaultLimit,
+	}
+}
+
+// Path returns the path to the history file
+func Path() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.yaml"), nil
+}
+
+// Load reads the global history from disk, or returns an empty history if the file doesn't exist
+func Load() (*History, error) {
+	h := New()
+
+	path, err := Path()
+	if err != nil {
+		return h, err
+	}
+
+	prompts, err := read(path)
+	if err != nil {
+		return h, err
+	}
+	if prompts != nil {
+		h.global = prompts
+	}
+
+	return h, nil
+}
+
+// read returns the prompts in a history file, or nil if it doesn't exist
+func read(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	var f file
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse history file: %w", err)
+	}
+	return f.Prompts, nil
+}
+
+// Save adds this session's new prompts to the history on disk, keeping the
+// newest entries up to the limit. The file is re-read first, so prompts
+// saved by other hauk instances since Load are kept.
+func (h *History) Save() error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+
+	if mkdirErr := os.MkdirAll(filepath.Dir(path), 0755); mkdirErr != nil {
+		return fmt.Errorf("failed to create history directory: %w", mkdirErr)
+	}
+
+	entries, err := read(path)
+	if err != nil {
+		return err
+	}
+	entries = append(entries, h.session[h.saved:]...)
+	if len(entries) > h.limit {
+		entries = entries[len(entries)-h.limit:]
+	}
+
+	data, err := yaml.Marshal(file{Prompts: entries})
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+
+	// Replace the file in one step so a concurrent reader never sees half of it
+	tmp, err := os.CreateTemp(filepath.Dir(path), "history-*.yaml")
+	if err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	h.saved = len(h.session)
+	return nil
+}
+
+// Add records a submitted prompt, skipping blanks and immediate repeats
+func (h *History) Add(prompt string) {
+	if strings.TrimSpace(prompt) == "" {
+		return
+	}
+
+	entries := h.Entries()
+	if len(entries) > 0 && entries[len(entries)-1] == prompt {
+		return
+	}
+
+	h.session = append(h.session, prompt)
+}
+
+// Entries returns the global history followed by this session's prompts, oldest first
+func (h *History) Entries() []string {
+	entries := make([]string, 0, len(h.global)+len(h.session))
+	entries = append(entries, h.global...)
+	return append(entries, h.session...)
+}
+
+// SessionStart returns the index of Entries where this session's prompts begin
+func (h *History) SessionStart() int {
+	return len(h.global)
+}
+
+// Len returns the total number of entries
+func (h *History) Len() int {
+	return len(h.global) + len(h.session)
+}
+
+// Get returns the entry at index i of Entries
+func (h *History) Get(i int) string {
+	if i < 0 || i >= h.Len() {
+		return ""
+	}
+	if i < len(h.global) {
+		return h.global[i]
+	}
+	return h.session[i-len(h.global)]
+}
+
+// Search looks backwards from index before for an entry containing query
+// (case-insensitive) and returns its index, or -1 if there is no match
+func (h *History) Search(query string, before int) int {
+	if before > h.Len() {
+		before = h.Len()
+	}
+	query = strings.ToLower(query)
+
+	for i := before - 1; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(h.Get(i)), query) {
+			return i
+		}
+	}
+	return -1
+}
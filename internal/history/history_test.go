@@ -0,0 +1,181 @@
+package history
+
+import (
+	"os"
+	"testing"
+)
+
+func TestAdd(t *testing.T) {
+	h := New()
+
+	h.Add("first")
+	h.Add("first") // Immediate repeat is skipped
+	h.Add("   ")   // Blank is skipped
+	h.Add("second")
+	h.Add("first") // Non-adjacent repeat is kept
+
+	want := []string{"first", "second", "first"}
+	got := h.Entries()
+	if len(got) != len(want) {
+		t.Fatalf("Entries() = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Entries()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+
+	if h.SessionStart() != 0 {
+		t.Errorf("SessionStart() = %d, want 0", h.SessionStart())
+	}
+}
+
+func TestGet(t *testing.T) {
+	h := New()
+	h.global = []string{"old"}
+	h.Add("new")
+
+	tests := []struct {
+		index int
+		want  string
+	}{
+		{0, "old"},
+		{1, "new"},
+		{-1, ""},
+		{2, ""},
+	}
+
+	for _, tt := range tests {
+		if got := h.Get(tt.index); got != tt.want {
+			t.Errorf("Get(%d) = %q, want %q", tt.index, got, tt.want)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	h := New()
+	h.Add("draw a flowchart")
+	h.Add("now make it left-to-right")
+	h.Add("add a database node")
+	h.Add("Now make it top-down")
+
+	tests := []struct {
+		name   string
+		query  string
+		before int
+		want   int
+	}{
+		{"newest match", "now make", h.Len(), 3},
+		{"older match", "now make", 3, 1},
+		{"no older match", "now make", 1, -1},
+		{"case insensitive", "FLOWCHART", h.Len(), 0},
+		{"no match", "sequence", h.Len(), -1},
+		{"before past end", "database", 100, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := h.Search(tt.query, tt.before); got != tt.want {
+				t.Errorf("Search(%q, %d) = %d, want %d", tt.query, tt.before, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	// Missing file gives an empty history
+	h, err := Load()
+	if err != nil {
+		t.Fatalf("Load() with no file error = %v", err)
+	}
+	if h.Len() != 0 {
+		t.Errorf("Load() with no file Len() = %d, want 0", h.Len())
+	}
+
+	h.Add("line one\nline two")
+	h.Add("second prompt")
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// A new session sees the previous prompts as global history
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Len() != 2 {
+		t.Fatalf("Loaded Len() = %d, want 2", loaded.Len())
+	}
+	if loaded.Get(0) != "line one\nline two" {
+		t.Errorf("Loaded Get(0) = %q, want multi-line prompt", loaded.Get(0))
+	}
+	if loaded.SessionStart() != 2 {
+		t.Errorf("Loaded SessionStart() = %d, want 2", loaded.SessionStart())
+	}
+}
+
+func TestSave_KeepsOtherInstances(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	// Two instances started from the same (empty) history
+	first, _ := Load()
+	second, _ := Load()
+
+	first.Add("from first")
+	if err := first.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	second.Add("from second")
+	if err := second.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	first.Add("first again")
+	if err := first.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []string{"from first", "from second", "first again"}
+	got := loaded.Entries()
+	if len(got) != len(want) {
+		t.Fatalf("Loaded entries = %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Loaded entries = %q, want %q", got, want)
+			break
+		}
+	}
+}
+
+func TestSave_Limit(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	h := New()
+	h.limit = 2
+	h.Add("a")
+	h.Add("b")
+	h.Add("c")
+	if err := h.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if loaded.Len() != 2 || loaded.Get(0) != "b" || loaded.Get(1) != "c" {
+		t.Errorf("Loaded entries = %q, want [b c]", loaded.Entries())
+	}
+}
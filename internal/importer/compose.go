@@ -0,0 +1,331 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mnesler/hauk-tui/internal/diagram"
+	"gopkg.in/yaml.v3"
+)
+
+// Compose is the services of a docker-compose file and what connects them
+type Compose struct {
+	Services []*ComposeService // In file order
+	Networks []string          // Declared or used, excluding the default network
+	Volumes  []string          // Named volumes
+}
+
+// ComposeService is one service
+type ComposeService struct {
+	Name      string
+	Image     string // Image, or the build context when built locally
+	Ports     []Port
+	DependsOn []Dependency
+	Networks  []string
+	Mounts    []Mount
+}
+
+// Port is a container port, published on the host unless Published is empty
+type Port struct {
+	Published string
+	Target    string
+	Protocol  string // Empty for tcp
+}
+
+// Dependency is a depends_on entry
+type Dependency struct {
+	Service   string
+	Condition string // e.g. service_healthy; empty for the short syntax
+}
+
+// Mount is a named volume or bind mount
+type Mount struct {
+	Source string // Volume name or host path
+	Target string
+	Bind   bool
+}
+
+// composeService mirrors the service fields read by ParseCompose; the
+// fields with a short and a long syntax are decoded by hand
+type composeService struct {
+	Image     string    `yaml:"image"`
+	Build     yaml.Node `yaml:"build"`
+	Ports     yaml.Node `yaml:"ports"`
+	DependsOn yaml.Node `yaml:"depends_on"`
+	Networks  yaml.Node `yaml:"networks"`
+	Volumes   yaml.Node `yaml:"volumes"`
+}
+
+// LoadCompose reads and parses a docker-compose file
+func LoadCompose(path string) (*Compose, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := ParseCompose(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// ParseCompose extracts services with their ports, dependencies, networks
+// and volumes from a docker-compose file
+func ParseCompose(data []byte) (*Compose, error) {
+	var doc struct {
+		Services yaml.Node `yaml:"services"`
+		Networks yaml.Node `yaml:"networks"`
+		Volumes  yaml.Node `yaml:"volumes"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	c := &Compose{}
+	for _, pair := range mappingPairs(&doc.Networks) {
+		c.addNetwork(pair[0].Value)
+	}
+	for _, pair := range mappingPairs(&doc.Volumes) {
+		c.Volumes = append(c.Volumes, pair[0].Value)
+	}
+
+	for _, pair := range mappingPairs(&doc.Services) {
+		var raw composeService
+		if err := pair[1].Decode(&raw); err != nil {
+			return nil, fmt.Errorf("service %s: %w", pair[0].Value, err)
+		}
+		s, err := newComposeService(pair[0].Value, raw)
+		if err != nil {
+			return nil, fmt.Errorf("service %s: %w", pair[0].Value, err)
+		}
+		for _, n := range s.Networks {
+			c.addNetwork(n)
+		}
+		c.Services = append(c.Services, s)
+	}
+
+	if len(c.Services) == 0 {
+		return nil, fmt.Errorf("no services found")
+	}
+	return c, nil
+}
+
+// addNetwork records a network once, leaving out the implicit default
+func (c *Compose) addNetwork(name string) {
+	if name == "default" {
+		return
+	}
+	for _, n := range c.Networks {
+		if n == name {
+			return
+		}
+	}
+	c.Networks = append(c.Networks, name)
+}
+
+// newComposeService decodes the short and long syntaxes of a service
+func newComposeService(name string, raw composeService) (*ComposeService, error) {
+	s := &ComposeService{Name: name, Image: raw.Image}
+	if s.Image == "" {
+		s.Image = buildContext(&raw.Build)
+	}
+
+	for _, node := range raw.Ports.Content {
+		p, err := parsePort(node)
+		if err != nil {
+			return nil, err
+		}
+		s.Ports = append(s.Ports, p)
+	}
+
+	// depends_on is a list of names or a map of name to condition
+	if raw.DependsOn.Kind == yaml.MappingNode {
+		for _, pair := range mappingPairs(&raw.DependsOn) {
+			var opts struct {
+				Condition string `yaml:"condition"`
+			}
+			_ = pair[1].Decode(&opts)
+			s.DependsOn = append(s.DependsOn, Dependency{Service: pair[0].Value, Condition: opts.Condition})
+		}
+	} else {
+		for _, node := range raw.DependsOn.Content {
+			s.DependsOn = append(s.DependsOn, Dependency{Service: node.Value})
+		}
+	}
+
+	// networks is a list of names or a map of name to options
+	if raw.Networks.Kind == yaml.MappingNode {
+		for _, pair := range mappingPairs(&raw.Networks) {
+			s.Networks = append(s.Networks, pair[0].Value)
+		}
+	} else {
+		for _, node := range raw.Networks.Content {
+			s.Networks = append(s.Networks, node.Value)
+		}
+	}
+
+	for _, node := range raw.Volumes.Content {
+		if m, ok := parseMount(node); ok {
+			s.Mounts = append(s.Mounts, m)
+		}
+	}
+
+	return s, nil
+}
+
+// buildContext returns the context of a build given as a path or a map
+func buildContext(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return "build " + node.Value
+	case yaml.MappingNode:
+		if ctx := mappingValue(node, "context"); ctx != nil {
+			return "build " + ctx.Value
+		}
+		return "build"
+	}
+	return ""
+}
+
+// parsePort reads "[ip:][published:]target[/protocol]" or the long syntax
+func parsePort(node *yaml.Node) (Port, error) {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Target    string `yaml:"target"`
+			Published string `yaml:"published"`
+			Protocol  string `yaml:"protocol"`
+		}
+		if err := node.Decode(&long); err != nil {
+			return Port{}, err
+		}
+		return Port{Published: long.Published, Target: long.Target, Protocol: portProtocol(long.Protocol)}, nil
+	}
+
+	spec, protocol, _ := strings.Cut(node.Value, "/")
+	p := Port{Protocol: portProtocol(protocol)}
+	parts := strings.Split(spec, ":")
+	p.Target = parts[len(parts)-1]
+	if len(parts) >= 2 {
+		p.Published = parts[len(parts)-2]
+	}
+	if p.Target == "" {
+		return Port{}, fmt.Errorf("invalid port %q", node.Value)
+	}
+	return p, nil
+}
+
+// portProtocol drops the default tcp so only other protocols are shown
+func portProtocol(protocol string) string {
+	if protocol == "tcp" {
+		return ""
+	}
+	return protocol
+}
+
+// parseMount reads "source:target[:mode]" or the long syntax, skipping
+// anonymous volumes and tmpfs
+func parseMount(node *yaml.Node) (Mount, bool) {
+	if node.Kind == yaml.MappingNode {
+		var long struct {
+			Type   string `yaml:"type"`
+			Source string `yaml:"source"`
+			Target string `yaml:"target"`
+		}
+		if node.Decode(&long) != nil || long.Source == "" || long.Type == "tmpfs" {
+			return Mount{}, false
+		}
+		return Mount{Source: long.Source, Target: long.Target, Bind: long.Type == "bind"}, true
+	}
+
+	parts := strings.Split(node.Value, ":")
+	if len(parts) < 2 {
+		return Mount{}, false
+	}
+	source := parts[0]
+	bind := strings.HasPrefix(source, ".") || strings.HasPrefix(source, "/") || strings.HasPrefix(source, "~") || strings.HasPrefix(source, "$")
+	return Mount{Source: source, Target: parts[1], Bind: bind}, true
+}
+
+// String is "published→target" with a protocol other than tcp appended
+func (p Port) String() string {
+	s := p.Target
+	if p.Published != "" {
+		s = p.Published + "→" + p.Target
+	}
+	if p.Protocol != "" {
+		s += "/" + p.Protocol
+	}
+	return s
+}
+
+// Mermaid renders the services as a flowchart: published ports from the
+// host, depends_on between services, and the networks and volumes each
+// service uses
+func (c *Compose) Mermaid() string {
+	f := diagram.NewFlowchart("LR")
+
+	var published bool
+	for _, s := range c.Services {
+		for _, p := range s.Ports {
+			published = published || p.Published != ""
+		}
+	}
+	if published {
+		f.Node("host", "Host", diagram.ShapeRound)
+	}
+
+	for _, s := range c.Services {
+		label := s.Name
+		if s.Image != "" {
+			label += " (" + s.Image + ")"
+		}
+		f.Node("svc:"+s.Name, label, diagram.ShapeBox)
+	}
+	for _, n := range c.Networks {
+		f.Node("net:"+n, "net/"+n, diagram.ShapeStadium)
+	}
+	for _, v := range c.Volumes {
+		f.Node("vol:"+v, "vol/"+v, diagram.ShapeDatabase)
+	}
+
+	for _, s := range c.Services {
+		var ports []string
+		for _, p := range s.Ports {
+			if p.Published != "" {
+				ports = append(ports, p.String())
+			}
+		}
+		if len(ports) > 0 {
+			f.Edge("host", "svc:"+s.Name, strings.Join(ports, ", "))
+		}
+
+		for _, d := range s.DependsOn {
+			f.Node("svc:"+d.Service, d.Service, diagram.ShapeBox)
+			label := "depends on"
+			if d.Condition != "" {
+				label += " (" + strings.TrimPrefix(d.Condition, "service_") + ")"
+			}
+			f.Edge("svc:"+s.Name, "svc:"+d.Service, label)
+		}
+
+		for _, n := range s.Networks {
+			if n != "default" {
+				f.Edge("svc:"+s.Name, "net:"+n, "")
+			}
+		}
+
+		for _, m := range s.Mounts {
+			key := "vol:" + m.Source
+			if m.Bind {
+				key = "bind:" + m.Source
+				f.Node(key, m.Source, diagram.ShapeDatabase)
+			} else {
+				f.Node(key, "vol/"+m.Source, diagram.ShapeDatabase)
+			}
+			f.Edge("svc:"+s.Name, key, m.Target)
+		}
+	}
+
+	return f.String()
+}
@@ -0,0 +1,150 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+const shopCompose = `name: shop
+services:
+  proxy:
+    image: nginx:1.25
+    ports:
+      - "80:80"
+      - "127.0.0.1:8443:443"
+    depends_on: [web]
+    networks: [frontend]
+    volumes:
+      - ./nginx.conf:/etc/nginx/nginx.conf:ro
+  web:
+    build: ./web
+    ports:
+      - target: 3000
+        published: 3000
+      - "9229"
+      - target: 5000
+        published: 5000
+        protocol: sctp
+      - "7000:7000/sctp"
+      - "8000:8000/tcp"
+    depends_on:
+      db:
+        condition: service_healthy
+      cache:
+        condition: service_started
+    networks:
+      frontend:
+      backend:
+        aliases: [app]
+    environment:
+      DB_HOST: db
+  db:
+    image: postgres:16
+    networks: [backend]
+    volumes:
+      - type: volume
+        source: pgdata
+        target: /var/lib/postgresql/data
+      - /tmp
+  cache:
+    image: redis:7
+    ports: ["6379:6379/udp"]
+networks:
+  frontend:
+  backend:
+    internal: true
+volumes:
+  pgdata:
+`
+
+func TestParseCompose(t *testing.T) {
+	c, err := ParseCompose([]byte(shopCompose))
+	if err != nil {
+		t.Fatalf("ParseCompose() error = %v", err)
+	}
+
+	var names []string
+	for _, s := range c.Services {
+		names = append(names, s.Name)
+	}
+	if got := strings.Join(names, " "); got != "proxy web db cache" {
+		t.Errorf("Services = %s, want file order", got)
+	}
+	if got := strings.Join(c.Networks, " "); got != "frontend backend" {
+		t.Errorf("Networks = %s", got)
+	}
+
+	proxy, web, db, cache := c.Services[0], c.Services[1], c.Services[2], c.Services[3]
+
+	tests := []struct {
+		name string
+		got  Port
+		want string
+	}{
+		{"short", proxy.Ports[0], "80→80"},
+		{"host address", proxy.Ports[1], "8443→443"},
+		{"long", web.Ports[0], "3000→3000"},
+		{"unpublished", web.Ports[1], "9229"},
+		{"protocol", cache.Ports[0], "6379→6379/udp"},
+		{"long sctp", web.Ports[2], "5000→5000/sctp"},
+		{"sctp", web.Ports[3], "7000→7000/sctp"},
+		{"tcp", web.Ports[4], "8000→8000"},
+	}
+	for _, tt := range tests {
+		if got := tt.got.String(); got != tt.want {
+			t.Errorf("%s port = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	if web.Image != "build ./web" {
+		t.Errorf("web image = %q, want the build context", web.Image)
+	}
+	if len(web.DependsOn) != 2 || web.DependsOn[0] != (Dependency{Service: "db", Condition: "service_healthy"}) {
+		t.Errorf("web depends_on = %+v", web.DependsOn)
+	}
+	if strings.Join(web.Networks, " ") != "frontend backend" {
+		t.Errorf("web networks = %v", web.Networks)
+	}
+	if len(proxy.Mounts) != 1 || !proxy.Mounts[0].Bind || proxy.Mounts[0].Target != "/etc/nginx/nginx.conf" {
+		t.Errorf("proxy mounts = %+v, want the config bind mount", proxy.Mounts)
+	}
+	if len(db.Mounts) != 1 || db.Mounts[0] != (Mount{Source: "pgdata", Target: "/var/lib/postgresql/data"}) {
+		t.Errorf("db mounts = %+v, want only the named volume", db.Mounts)
+	}
+}
+
+func TestCompose_Mermaid(t *testing.T) {
+	c, err := ParseCompose([]byte(shopCompose))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := c.Mermaid()
+	for _, want := range []string{
+		"graph LR",
+		`host("Host")`,
+		`svc_proxy["proxy (nginx:1.25)"]`,
+		`net_frontend(["net/frontend"])`,
+		`vol_pgdata[("vol/pgdata")]`,
+		`host -->|"80→80, 8443→443"| svc_proxy`,
+		`svc_web -->|"depends on (healthy)"| svc_db`,
+		"svc_web --> net_backend",
+		`svc_proxy -->|"/etc/nginx/nginx.conf"| bind_nginx_conf`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Mermaid() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestParseCompose_Errors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"no services":  "version: \"3\"\n",
+		"invalid":      "services: [",
+		"invalid port": "services:\n  web:\n    ports: [\"80:\"]\n",
+	} {
+		if _, err := ParseCompose([]byte(doc)); err == nil {
+			t.Errorf("%s: ParseCompose() should fail", name)
+		}
+	}
+}
@@ -0,0 +1,318 @@
+package importer
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MaxGitCommits limits how much history goes into a gitGraph; mermaid
+// becomes unreadable well before this
+const MaxGitCommits = 100
+
+// GitHistory is a commit graph with each commit assigned to a branch lane
+type GitHistory struct {
+	Branches []string  // Lanes in drawing order, the main branch first
+	Commits  []*Commit // Parents before children
+}
+
+// Commit is one commit of the history
+type Commit struct {
+	Hash    string
+	Parents []string
+	Subject string
+	Tags    []string
+	Branch  string // Lane the commit is drawn on
+}
+
+// mergeSubject finds the branch name in default merge commit messages
+var mergeSubject = regexp.MustCompile(`^Merge (?:(?:remote-tracking )?branch '([^']+)'|pull request #\d+ from (\S+))`)
+
+// unsafeBranch matches characters mermaid doesn't allow in branch names
+var unsafeBranch = regexp.MustCompile(`[^A-Za-z0-9/_.\-]+`)
+
+// GitLog reads the newest MaxGitCommits commits reachable from branches in
+// the repository at dir using the git command. With no branches, all
+// local branches are read.
+func GitLog(dir string, branches []string) (*GitHistory, error) {
+	heads, err := gitBranchHeads(dir, branches)
+	if err != nil {
+		return nil, err
+	}
+	if len(heads) == 0 {
+		return nil, fmt.Errorf("no branches found in %s", dir)
+	}
+
+	order := make([]string, 0, len(heads))
+	revs := make([]string, 0, len(heads))
+	for name := range heads {
+		order = append(order, name)
+	}
+	if len(branches) > 0 {
+		order = branches
+	} else {
+		sortBranches(order)
+	}
+	for _, name := range order {
+		revs = append(revs, heads[name])
+	}
+
+	args := append([]string{"log", "--topo-order", "--reverse", fmt.Sprintf("-n%d", MaxGitCommits),
+		"--format=%H%x1f%P%x1f%D%x1f%s"}, revs...)
+	out, err := git(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ParseGitLog(out, heads, order), nil
+}
+
+// gitBranchHeads resolves branches, or all local branches, to commit hashes
+func gitBranchHeads(dir string, branches []string) (map[string]string, error) {
+	heads := make(map[string]string)
+	if len(branches) > 0 {
+		for _, name := range branches {
+			hash, err := git(dir, "rev-parse", "--verify", "--quiet", name+"^{commit}")
+			if err != nil {
+				return nil, fmt.Errorf("unknown branch %q", name)
+			}
+			heads[name] = strings.TrimSpace(hash)
+		}
+		return heads, nil
+	}
+
+	out, err := git(dir, "for-each-ref", "--format=%(refname:short)%1f%(objectname)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if name, hash, ok := strings.Cut(line, "\x1f"); ok {
+			heads[name] = hash
+		}
+	}
+	return heads, nil
+}
+
+// git runs a git command in dir and returns its output
+func git(dir string, args ...string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// sortBranches puts the usual main branch names first, the rest by name
+func sortBranches(names []string) {
+	rank := func(name string) int {
+		switch name {
+		case "main", "master", "trunk":
+			return 0
+		case "develop", "development":
+			return 1
+		}
+		return 2
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if ri, rj := rank(names[i]), rank(names[j]); ri != rj {
+			return ri < rj
+		}
+		return names[i] < names[j]
+	})
+}
+
+// ParseGitLog builds a history from `git log --topo-order --reverse
+// --format=%H%x1f%P%x1f%D%x1f%s` output. Commits are assigned to the lane
+// of the first branch in order whose first-parent chain reaches them;
+// commits only reachable through merges get a lane named after the merge.
+func ParseGitLog(out string, heads map[string]string, order []string) *GitHistory {
+	h := &GitHistory{}
+	byHash := make(map[string]*Commit)
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			continue
+		}
+		c := &Commit{Hash: fields[0], Parents: strings.Fields(fields[1]), Subject: fields[3]}
+		for _, ref := range strings.Split(fields[2], ", ") {
+			if tag, ok := strings.CutPrefix(ref, "tag: "); ok {
+				c.Tags = append(c.Tags, tag)
+			}
+		}
+		h.Commits = append(h.Commits, c)
+		byHash[c.Hash] = c
+	}
+
+	used := make(map[string]bool)
+	assign := func(hash, lane string) {
+		h.Branches = append(h.Branches, lane)
+		used[lane] = true
+		for c := byHash[hash]; c != nil && c.Branch == ""; {
+			c.Branch = lane
+			if len(c.Parents) == 0 {
+				break
+			}
+			c = byHash[c.Parents[0]]
+		}
+	}
+
+	for _, name := range order {
+		if c := byHash[heads[name]]; c != nil && c.Branch == "" {
+			assign(c.Hash, branchName(name, used))
+		}
+	}
+
+	// Branches merged and deleted, newest first
+	mergedAs := make(map[string]string)
+	for _, c := range h.Commits {
+		if len(c.Parents) > 1 {
+			if m := mergeSubject.FindStringSubmatch(c.Subject); m != nil {
+				mergedAs[c.Parents[1]] = m[1] + m[2]
+			}
+		}
+	}
+	for i := len(h.Commits) - 1; i >= 0; i-- {
+		c := h.Commits[i]
+		if c.Branch != "" {
+			continue
+		}
+		name := mergedAs[c.Hash]
+		if name == "" {
+			name = "branch-" + shortHash(c.Hash)
+		}
+		assign(c.Hash, branchName(name, used))
+	}
+
+	return h
+}
+
+// branchName makes name safe for mermaid and unique among used
+func branchName(name string, used map[string]bool) string {
+	name = strings.Trim(unsafeBranch.ReplaceAllString(name, "-"), "-")
+	if name == "" {
+		name = "branch"
+	}
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", name, i)
+	}
+	return unique
+}
+
+// Mermaid renders the history as a gitGraph. Branches are created right
+// after the commit they fork from, so mermaid draws them from there.
+func (h *GitHistory) Mermaid() string {
+	if len(h.Commits) == 0 || len(h.Branches) == 0 {
+		return "gitGraph"
+	}
+
+	var lines []string
+	main := h.Branches[0]
+	if main != "main" {
+		lines = append(lines, fmt.Sprintf("%%%%{init: {'gitGraph': {'mainBranchName': '%s'}}}%%%%", main))
+	}
+	lines = append(lines, "gitGraph")
+
+	// Where each lane forks off another
+	byHash := make(map[string]*Commit)
+	for _, c := range h.Commits {
+		byHash[c.Hash] = c
+	}
+	forks := make(map[string][]string)
+	started := map[string]bool{main: true}
+	for _, c := range h.Commits {
+		if started[c.Branch] {
+			continue
+		}
+		started[c.Branch] = true
+		if len(c.Parents) > 0 && byHash[c.Parents[0]] != nil {
+			forks[c.Parents[0]] = append(forks[c.Parents[0]], c.Branch)
+		}
+	}
+
+	created := map[string]bool{main: true}
+	current := main
+	for _, c := range h.Commits {
+		if !created[c.Branch] {
+			// Forked from a commit outside the history
+			lines = append(lines, "    branch "+c.Branch)
+			created[c.Branch] = true
+			current = c.Branch
+		}
+		if current != c.Branch {
+			lines = append(lines, "    checkout "+c.Branch)
+			current = c.Branch
+		}
+
+		attrs := "id: " + quoteString(commitLabel(c))
+		if len(c.Tags) > 0 {
+			attrs += " tag: " + quoteString(c.Tags[0])
+		}
+		if from := h.mergedBranch(c, byHash); from != "" && created[from] {
+			lines = append(lines, fmt.Sprintf("    merge %s %s", from, attrs))
+		} else {
+			lines = append(lines, "    commit "+attrs)
+		}
+
+		for _, lane := range forks[c.Hash] {
+			lines = append(lines, "    branch "+lane)
+			created[lane] = true
+			current = lane
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// mergedBranch returns the lane merged into c's lane, if c is a merge
+func (h *GitHistory) mergedBranch(c *Commit, byHash map[string]*Commit) string {
+	if len(c.Parents) < 2 {
+		return ""
+	}
+	if p := byHash[c.Parents[1]]; p != nil && p.Branch != c.Branch {
+		return p.Branch
+	}
+	return ""
+}
+
+// commitLabel is the short hash and subject, which also keeps ids unique
+func commitLabel(c *Commit) string {
+	label := shortHash(c.Hash) + " " + c.Subject
+	if runes := []rune(label); len(runes) > 40 {
+		label = string(runes[:39]) + "…"
+	}
+	return label
+}
+
+// quoteString quotes an id or tag. Mermaid strings have no escapes, so
+// double quotes, backslashes and control characters are replaced.
+func quoteString(s string) string {
+	return `"` + strings.Map(func(r rune) rune {
+		switch {
+		case r == '"':
+			return '\''
+		case r == '\\':
+			return '/'
+		case unicode.IsControl(r):
+			return ' '
+		}
+		return r
+	}, s) + `"`
+}
+
+// shortHash abbreviates a commit hash
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// logLine formats one commit like GitLog's --format
+func logLine(hash, parents, refs, subject string) string {
+	return strings.Join([]string{hash, parents, refs, subject}, "\x1f")
+}
+
+func TestParseGitLog(t *testing.T) {
+	out := strings.Join([]string{
+		logLine("a000000", "", "", "init"),
+		logLine("b000000", "a000000", "tag: v1.0", "release"),
+		logLine("c000000", "b000000", "", "feature work"),
+		logLine("d000000", "c000000", "", "more feature work"),
+		logLine("e000000", "b000000", "", `fix "quoted" bug`),
+		logLine("f000000", "e000000 d000000", "HEAD -> main", "Merge pull request #7 from org/feature-x"),
+		logLine("g000000", "b000000", "develop", "develop work"),
+	}, "\n")
+	heads := map[string]string{"main": "f000000", "develop": "g000000"}
+
+	h := ParseGitLog(out, heads, []string{"main", "develop"})
+
+	if want := []string{"main", "develop", "org/feature-x"}; !reflect.DeepEqual(h.Branches, want) {
+		t.Errorf("Branches = %v, want %v", h.Branches, want)
+	}
+	lanes := make(map[string]string)
+	for _, c := range h.Commits {
+		lanes[c.Hash] = c.Branch
+	}
+	if lanes["a000000"] != "main" || lanes["d000000"] != "org/feature-x" || lanes["g000000"] != "develop" {
+		t.Errorf("Lanes = %v", lanes)
+	}
+
+	want := strings.Join([]string{
+		"gitGraph",
+		`    commit id: "a000000 init"`,
+		`    commit id: "b000000 release" tag: "v1.0"`,
+		"    branch org/feature-x",
+		"    branch develop",
+		"    checkout org/feature-x",
+		`    commit id: "c000000 feature work"`,
+		`    commit id: "d000000 more feature work"`,
+		"    checkout main",
+		`    commit id: "e000000 fix 'quoted' bug"`,
+		`    merge org/feature-x id: "f000000 Merge pull request #7 from org/…"`,
+		"    checkout develop",
+		`    commit id: "g000000 develop work"`,
+	}, "\n")
+	if got := h.Mermaid(); got != want {
+		t.Errorf("Mermaid() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParseGitLog_MainBranchName(t *testing.T) {
+	h := ParseGitLog(logLine("a000000", "", "", "init"), map[string]string{"master": "a000000"}, []string{"master"})
+
+	got := h.Mermaid()
+	if !strings.HasPrefix(got, "%%{init: {'gitGraph': {'mainBranchName': 'master'}}}%%\ngitGraph") {
+		t.Errorf("Mermaid() = %q, want the main branch renamed", got)
+	}
+}
+
+func TestParseGitLog_QuotesForMermaid(t *testing.T) {
+	h := ParseGitLog(logLine("a000000", "", `tag: "v1"\beta`, "path C:\\tmp\tdone"), map[string]string{"main": "a000000"}, []string{"main"})
+
+	want := `    commit id: "a000000 path C:/tmp done" tag: "'v1'/beta"`
+	if got := h.Mermaid(); !strings.HasSuffix(got, "\n"+want) {
+		t.Errorf("Mermaid() = %q, want it to end with %q", got, want)
+	}
+}
+
+func TestGitLog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("GIT_AUTHOR_NAME", "test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"commit", "-q", "--allow-empty", "-m", "init"},
+		{"checkout", "-q", "-b", "topic"},
+		{"commit", "-q", "--allow-empty", "-m", "topic work"},
+		{"checkout", "-q", "main"},
+		{"commit", "-q", "--allow-empty", "-m", "main work"},
+		{"merge", "-q", "--no-ff", "topic", "-m", "Merge branch 'topic'"},
+	} {
+		if _, err := git(dir, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	h, err := GitLog(dir, nil)
+	if err != nil {
+		t.Fatalf("GitLog() error = %v", err)
+	}
+	if len(h.Commits) != 4 || !reflect.DeepEqual(h.Branches, []string{"main", "topic"}) {
+		t.Errorf("GitLog() = %d commits on %v, want 4 on main and topic", len(h.Commits), h.Branches)
+	}
+	if got := h.Mermaid(); !strings.Contains(got, "merge topic") {
+		t.Errorf("Mermaid() = %q, want topic merged", got)
+	}
+
+	if _, err := GitLog(dir, []string{"missing"}); err == nil {
+		t.Error("GitLog() of an unknown branch should fail")
+	}
+	if _, err := GitLog(t.TempDir(), nil); err == nil {
+		t.Error("GitLog() outside a repository should fail")
+	}
+}
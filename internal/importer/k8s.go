@@ -0,0 +1,491 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mnesler/hauk-tui/internal/diagram"
+	"gopkg.in/yaml.v3"
+)
+
+// Topology is how traffic and configuration flow between the Kubernetes
+// resources declared in a set of manifests
+type Topology struct {
+	Resources []*Resource // Ingresses, services, workloads, then config
+	Links     []Link
+	Skipped   []string // Files that aren't valid YAML, e.g. Helm templates
+}
+
+// Resource is one manifest object
+type Resource struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Public    bool // LoadBalancer or NodePort service
+
+	spec yaml.Node
+	pod  *k8sPodTemplate // Pod template of workloads
+}
+
+// Link is traffic or configuration flowing From one resource To another
+type Link struct {
+	From, To *Resource
+	Label    string
+}
+
+// k8sKinds are the kinds that appear in the diagram, in drawing order,
+// with their kubectl short names
+var k8sKinds = []struct{ kind, short string }{
+	{"Ingress", "ing"},
+	{"Service", "svc"},
+	{"Deployment", "deploy"},
+	{"StatefulSet", "sts"},
+	{"DaemonSet", "ds"},
+	{"CronJob", "cronjob"},
+	{"Job", "job"},
+	{"Pod", "pod"},
+	{"ConfigMap", "cm"},
+	{"Secret", "secret"},
+}
+
+// k8sObject is the envelope every manifest shares
+type k8sObject struct {
+	Kind     string      `yaml:"kind"`
+	Metadata k8sMeta     `yaml:"metadata"`
+	Spec     yaml.Node   `yaml:"spec"`
+	Items    []yaml.Node `yaml:"items"` // kind: List
+}
+
+type k8sMeta struct {
+	Name      string            `yaml:"name"`
+	Namespace string            `yaml:"namespace"`
+	Labels    map[string]string `yaml:"labels"`
+}
+
+type k8sNameRef struct {
+	Name string `yaml:"name"`
+}
+
+type k8sPodTemplate struct {
+	Metadata k8sMeta `yaml:"metadata"`
+	Spec     struct {
+		Volumes []struct {
+			ConfigMap *k8sNameRef `yaml:"configMap"`
+			Secret    *struct {
+				SecretName string `yaml:"secretName"`
+			} `yaml:"secret"`
+			Projected *struct {
+				Sources []struct {
+					ConfigMap *k8sNameRef `yaml:"configMap"`
+					Secret    *k8sNameRef `yaml:"secret"`
+				} `yaml:"sources"`
+			} `yaml:"projected"`
+		} `yaml:"volumes"`
+		Containers     []k8sContainer `yaml:"containers"`
+		InitContainers []k8sContainer `yaml:"initContainers"`
+	} `yaml:"spec"`
+}
+
+type k8sContainer struct {
+	EnvFrom []struct {
+		ConfigMapRef *k8sNameRef `yaml:"configMapRef"`
+		SecretRef    *k8sNameRef `yaml:"secretRef"`
+	} `yaml:"envFrom"`
+	Env []struct {
+		ValueFrom *struct {
+			ConfigMapKeyRef *k8sNameRef `yaml:"configMapKeyRef"`
+			SecretKeyRef    *k8sNameRef `yaml:"secretKeyRef"`
+		} `yaml:"valueFrom"`
+	} `yaml:"env"`
+}
+
+type k8sWorkloadSpec struct {
+	Template    k8sPodTemplate `yaml:"template"`
+	JobTemplate struct {
+		Spec struct {
+			Template k8sPodTemplate `yaml:"template"`
+		} `yaml:"spec"`
+	} `yaml:"jobTemplate"`
+}
+
+type k8sServiceSpec struct {
+	Type     string            `yaml:"type"`
+	Selector map[string]string `yaml:"selector"`
+	Ports    []struct {
+		Port       string `yaml:"port"`
+		TargetPort string `yaml:"targetPort"`
+	} `yaml:"ports"`
+}
+
+type k8sBackend struct {
+	Service *struct {
+		Name string `yaml:"name"`
+	} `yaml:"service"`
+	ServiceName string `yaml:"serviceName"` // extensions/v1beta1
+}
+
+type k8sIngressSpec struct {
+	DefaultBackend *k8sBackend `yaml:"defaultBackend"`
+	Backend        *k8sBackend `yaml:"backend"`
+	Rules          []struct {
+		Host string `yaml:"host"`
+		HTTP *struct {
+			Paths []struct {
+				Path    string     `yaml:"path"`
+				Backend k8sBackend `yaml:"backend"`
+			} `yaml:"paths"`
+		} `yaml:"http"`
+	} `yaml:"rules"`
+}
+
+// serviceName returns the backend's service for either API version
+func (b *k8sBackend) serviceName() string {
+	if b == nil {
+		return ""
+	}
+	if b.Service != nil {
+		return b.Service.Name
+	}
+	return b.ServiceName
+}
+
+// LoadManifests reads the Kubernetes YAML files under path, a directory
+// or a single file, and links ingresses to services, services to the
+// workloads they select and config maps and secrets to the workloads that
+// use them
+func LoadManifests(path string) (*Topology, error) {
+	t := &Topology{}
+	err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(p); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		return t.readFile(p)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(t.Resources) == 0 {
+		return nil, fmt.Errorf("no Kubernetes resources found in %s", path)
+	}
+	t.link()
+	t.sortResources()
+	return t, nil
+}
+
+// readFile adds the resources of every document in a manifest file
+func (t *Topology) readFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	for {
+		var obj k8sObject
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			t.Skipped = append(t.Skipped, path)
+			return nil
+		}
+		t.add(obj)
+	}
+}
+
+// add records obj if it's a kind the diagram shows, unpacking lists
+func (t *Topology) add(obj k8sObject) {
+	if obj.Kind == "List" {
+		for _, item := range obj.Items {
+			var inner k8sObject
+			if item.Decode(&inner) == nil {
+				t.add(inner)
+			}
+		}
+		return
+	}
+	if k8sShortName(obj.Kind) == "" || obj.Metadata.Name == "" {
+		return
+	}
+
+	ns := obj.Metadata.Namespace
+	if ns == "" {
+		ns = "default"
+	}
+	r := &Resource{Kind: obj.Kind, Namespace: ns, Name: obj.Metadata.Name, spec: obj.Spec}
+
+	switch obj.Kind {
+	case "Pod":
+		r.pod = &k8sPodTemplate{Metadata: obj.Metadata}
+		_ = obj.Spec.Decode(&r.pod.Spec)
+	case "Deployment", "StatefulSet", "DaemonSet", "Job", "CronJob":
+		var spec k8sWorkloadSpec
+		_ = obj.Spec.Decode(&spec)
+		r.pod = &spec.Template
+		if obj.Kind == "CronJob" {
+			r.pod = &spec.JobTemplate.Spec.Template
+		}
+	}
+	t.Resources = append(t.Resources, r)
+}
+
+// sortResources orders resources by kind, namespace and name
+func (t *Topology) sortResources() {
+	rank := func(kind string) int {
+		for i, k := range k8sKinds {
+			if k.kind == kind {
+				return i
+			}
+		}
+		return len(k8sKinds)
+	}
+	sort.SliceStable(t.Resources, func(i, j int) bool {
+		a, b := t.Resources[i], t.Resources[j]
+		if ra, rb := rank(a.Kind), rank(b.Kind); ra != rb {
+			return ra < rb
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+}
+
+// find returns the resource of kind in namespace, adding a placeholder for
+// references to resources the manifests don't declare
+func (t *Topology) find(kind, namespace, name string) *Resource {
+	for _, r := range t.Resources {
+		if r.Kind == kind && r.Namespace == namespace && r.Name == name {
+			return r
+		}
+	}
+	r := &Resource{Kind: kind, Namespace: namespace, Name: name}
+	t.Resources = append(t.Resources, r)
+	return r
+}
+
+// link works out the flows between resources
+func (t *Topology) link() {
+	// Copy the list, find can append placeholders
+	resources := append([]*Resource{}, t.Resources...)
+
+	for _, r := range resources {
+		switch r.Kind {
+		case "Ingress":
+			var spec k8sIngressSpec
+			_ = r.spec.Decode(&spec)
+			for _, b := range []*k8sBackend{spec.DefaultBackend, spec.Backend} {
+				if name := b.serviceName(); name != "" {
+					t.addLink(r, t.find("Service", r.Namespace, name), "default")
+				}
+			}
+			for _, rule := range spec.Rules {
+				if rule.HTTP == nil {
+					continue
+				}
+				for _, p := range rule.HTTP.Paths {
+					if name := p.Backend.serviceName(); name != "" {
+						t.addLink(r, t.find("Service", r.Namespace, name), rule.Host+p.Path)
+					}
+				}
+			}
+
+		case "Service":
+			var spec k8sServiceSpec
+			_ = r.spec.Decode(&spec)
+			r.Public = spec.Type == "LoadBalancer" || spec.Type == "NodePort"
+			var ports []string
+			for _, p := range spec.Ports {
+				if p.TargetPort != "" && p.TargetPort != p.Port {
+					ports = append(ports, p.Port+"→"+p.TargetPort)
+				} else {
+					ports = append(ports, p.Port)
+				}
+			}
+			if len(spec.Selector) == 0 {
+				continue
+			}
+			for _, w := range resources {
+				if w.pod != nil && w.Namespace == r.Namespace && selects(spec.Selector, w.pod.Metadata.Labels) {
+					t.addLink(r, w, strings.Join(ports, ", "))
+				}
+			}
+
+		default:
+			if r.pod != nil {
+				t.linkConfig(r)
+			}
+		}
+	}
+}
+
+// linkConfig links the config maps and secrets a workload mounts or reads
+// into its environment
+func (t *Topology) linkConfig(w *Resource) {
+	spec := w.pod.Spec
+	use := func(kind, name, how string) {
+		if name != "" {
+			t.addLink(t.find(kind, w.Namespace, name), w, how)
+		}
+	}
+
+	for _, v := range spec.Volumes {
+		if v.ConfigMap != nil {
+			use("ConfigMap", v.ConfigMap.Name, "volume")
+		}
+		if v.Secret != nil {
+			use("Secret", v.Secret.SecretName, "volume")
+		}
+		if v.Projected != nil {
+			for _, s := range v.Projected.Sources {
+				if s.ConfigMap != nil {
+					use("ConfigMap", s.ConfigMap.Name, "volume")
+				}
+				if s.Secret != nil {
+					use("Secret", s.Secret.Name, "volume")
+				}
+			}
+		}
+	}
+
+	for _, c := range append(append([]k8sContainer{}, spec.InitContainers...), spec.Containers...) {
+		for _, e := range c.EnvFrom {
+			if e.ConfigMapRef != nil {
+				use("ConfigMap", e.ConfigMapRef.Name, "env")
+			}
+			if e.SecretRef != nil {
+				use("Secret", e.SecretRef.Name, "env")
+			}
+		}
+		for _, e := range c.Env {
+			if e.ValueFrom == nil {
+				continue
+			}
+			if e.ValueFrom.ConfigMapKeyRef != nil {
+				use("ConfigMap", e.ValueFrom.ConfigMapKeyRef.Name, "env")
+			}
+			if e.ValueFrom.SecretKeyRef != nil {
+				use("Secret", e.ValueFrom.SecretKeyRef.Name, "env")
+			}
+		}
+	}
+}
+
+// addLink records a flow once, merging labels of repeated links
+func (t *Topology) addLink(from, to *Resource, label string) {
+	for i, l := range t.Links {
+		if l.From == from && l.To == to {
+			if label != "" && !containsLabel(l.Label, label) {
+				if l.Label != "" {
+					label = l.Label + ", " + label
+				}
+				t.Links[i].Label = label
+			}
+			return
+		}
+	}
+	t.Links = append(t.Links, Link{From: from, To: to, Label: label})
+}
+
+// containsLabel reports whether a comma-joined label already has part
+func containsLabel(label, part string) bool {
+	for _, p := range strings.Split(label, ", ") {
+		if p == part {
+			return true
+		}
+	}
+	return false
+}
+
+// selects reports whether every selector label matches
+func selects(selector, labels map[string]string) bool {
+	for k, v := range selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// k8sShortName returns the kubectl short name of a kind shown in the
+// diagram, or "" for other kinds
+func k8sShortName(kind string) string {
+	for _, k := range k8sKinds {
+		if k.kind == kind {
+			return k.short
+		}
+	}
+	return ""
+}
+
+// Mermaid renders the topology as a flowchart from the internet through
+// ingresses and services to workloads, with config flowing in
+func (t *Topology) Mermaid() string {
+	namespaces := make(map[string]bool)
+	for _, r := range t.Resources {
+		namespaces[r.Namespace] = true
+	}
+
+	f := diagram.NewFlowchart("LR")
+	key := func(r *Resource) string {
+		return r.Kind + "/" + r.Namespace + "/" + r.Name
+	}
+
+	public := false
+	for _, r := range t.Resources {
+		if r.Kind == "Ingress" || r.Public {
+			public = true
+		}
+	}
+	if public {
+		f.Node("internet", "Internet", diagram.ShapeRound)
+	}
+
+	for _, r := range t.Resources {
+		label := k8sShortName(r.Kind) + "/" + r.Name
+		if len(namespaces) > 1 {
+			label += " (" + r.Namespace + ")"
+		}
+		f.Node(key(r), label, k8sShape(r.Kind))
+		if r.Kind == "Ingress" || r.Public {
+			f.Edge("internet", key(r), "")
+		}
+	}
+
+	for _, l := range t.Links {
+		f.Edge(key(l.From), key(l.To), l.Label)
+	}
+	return f.String()
+}
+
+// k8sShape draws entry points as stadiums, services as rounded boxes,
+// config as cylinders and workloads as boxes
+func k8sShape(kind string) diagram.Shape {
+	switch kind {
+	case "Ingress":
+		return diagram.ShapeStadium
+	case "Service":
+		return diagram.ShapeRound
+	case "ConfigMap", "Secret":
+		return diagram.ShapeDatabase
+	}
+	return diagram.ShapeBox
+}
@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// webManifests is a deployment with its service and config
+const webManifests = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  labels: {app: web}
+spec:
+  template:
+    metadata:
+      labels: {app: web, tier: front}
+    spec:
+      containers:
+        - name: web
+          envFrom:
+            - configMapRef: {name: web-config}
+          env:
+            - name: DB_PASSWORD
+              valueFrom:
+                secretKeyRef: {name: db-credentials, key: password}
+      volumes:
+        - name: certs
+          secret: {secretName: tls-certs}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector: {app: web}
+  ports:
+    - port: 80
+      targetPort: 8080
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data: {LOG_LEVEL: info}
+`
+
+// edgeManifests routes an ingress to web and an undeclared api service,
+// and lists a database
+const edgeManifests = `apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: main
+spec:
+  rules:
+    - host: shop.example.com
+      http:
+        paths:
+          - path: /
+            pathType: Prefix
+            backend:
+              service:
+                name: web
+                port: {number: 80}
+          - path: /api
+            backend:
+              service:
+                name: api
+                port: {number: 80}
+---
+apiVersion: v1
+kind: List
+items:
+  - apiVersion: apps/v1
+    kind: StatefulSet
+    metadata: {name: db}
+    spec:
+      template:
+        metadata: {labels: {app: db}}
+        spec:
+          containers:
+            - name: pg
+              env:
+                - name: PASSWORD
+                  valueFrom: {secretKeyRef: {name: db-credentials, key: password}}
+  - apiVersion: v1
+    kind: Service
+    metadata: {name: db}
+    spec:
+      type: LoadBalancer
+      selector: {app: db}
+      ports: [{port: 5432}]
+`
+
+// writeManifests lays out manifests the way a repo might, with a Helm
+// template and a hidden directory to skip
+func writeManifests(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"app/web.yaml":          webManifests,
+		"ingress.yml":           edgeManifests,
+		"app/templates/x.yaml":  "{{ if .Values.enabled }}: [\n",
+		".github/workflow.yaml": "kind: Deployment\nmetadata: {name: hidden}\n",
+		"README.md":             "kind: Deployment\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadManifests(t *testing.T) {
+	dir := writeManifests(t)
+
+	top, err := LoadManifests(dir)
+	if err != nil {
+		t.Fatalf("LoadManifests() error = %v", err)
+	}
+
+	var names []string
+	for _, r := range top.Resources {
+		names = append(names, k8sShortName(r.Kind)+"/"+r.Name)
+	}
+	want := "ing/main svc/api svc/db svc/web deploy/web sts/db cm/web-config secret/db-credentials secret/tls-certs"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("Resources = %s, want %s", got, want)
+	}
+
+	if len(top.Skipped) != 1 || !strings.HasSuffix(top.Skipped[0], "x.yaml") {
+		t.Errorf("Skipped = %v, want the Helm template", top.Skipped)
+	}
+
+	links := make(map[string]string)
+	for _, l := range top.Links {
+		links[l.From.Name+" -> "+l.To.Kind+"/"+l.To.Name] = l.Label
+	}
+	for link, label := range map[string]string{
+		"main -> Service/web":              "shop.example.com/",
+		"main -> Service/api":              "shop.example.com/api",
+		"web -> Deployment/web":            "80→8080",
+		"db -> StatefulSet/db":             "5432",
+		"web-config -> Deployment/web":     "env",
+		"db-credentials -> Deployment/web": "env",
+		"db-credentials -> StatefulSet/db": "env",
+		"tls-certs -> Deployment/web":      "volume",
+	} {
+		if got, ok := links[link]; !ok || got != label {
+			t.Errorf("link %s = %q (found %v), want %q", link, got, ok, label)
+		}
+	}
+	if len(links) != 8 {
+		t.Errorf("Links = %v, want 8", links)
+	}
+}
+
+func TestTopology_Mermaid(t *testing.T) {
+	top, err := LoadManifests(writeManifests(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := top.Mermaid()
+	for _, want := range []string{
+		"graph LR",
+		`internet("Internet")`,
+		`Ingress_default_main(["ing/main"])`,
+		`Service_default_web("svc/web")`,
+		`ConfigMap_default_web_config[("cm/web-config")]`,
+		"internet --> Ingress_default_main",
+		"internet --> Service_default_db", // LoadBalancer
+		`Service_default_web -->|"80→8080"| Deployment_default_web`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Mermaid() missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "internet --> Service_default_web") {
+		t.Error("ClusterIP services shouldn't be reachable from the internet")
+	}
+}
+
+func TestLoadManifests_Namespaces(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "all.yaml")
+	manifests := "kind: Service\nmetadata: {name: web, namespace: shop}\nspec:\n  selector: {app: web}\n---\n" +
+		"kind: Deployment\nmetadata: {name: web, namespace: blog}\nspec:\n  template:\n    metadata: {labels: {app: web}}\n"
+	if err := os.WriteFile(path, []byte(manifests), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	top, err := LoadManifests(path)
+	if err != nil {
+		t.Fatalf("LoadManifests() error = %v", err)
+	}
+	if len(top.Links) != 0 {
+		t.Errorf("Links = %+v, services only select pods in their namespace", top.Links)
+	}
+	if got := top.Mermaid(); !strings.Contains(got, `"svc/web (shop)"`) {
+		t.Errorf("Mermaid() = %s, want namespaces in labels", got)
+	}
+
+	if _, err := LoadManifests(t.TempDir()); err == nil {
+		t.Error("LoadManifests() of an empty directory should fail")
+	}
+}
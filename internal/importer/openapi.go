@@ -0,0 +1,393 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mnesler/hauk-tui/internal/diagram"
+	"gopkg.in/yaml.v3"
+)
+
+// httpMethods are the operation keys of an OpenAPI path item, in the order
+// they're drawn
+var httpMethods = []string{"get", "post", "put", "patch", "delete", "head", "options", "trace"}
+
+// API is the parts of an OpenAPI 3 document that diagrams need
+type API struct {
+	Title      string
+	Version    string
+	Operations []*Operation // In document order
+}
+
+// Operation is one method on one path
+type Operation struct {
+	ID           string // operationId, if set
+	Method       string // Upper case
+	Path         string
+	Summary      string
+	Tags         []string
+	Request      string // Request body schema, e.g. Pet or Pet[]
+	Responses    []Response
+	Security     []string // Security scheme names
+	Callbacks    []string
+	Dependencies []string // Downstream services from x-dependencies
+}
+
+// Response is one documented response of an operation
+type Response struct {
+	Status      string
+	Description string
+	Schema      string
+}
+
+// Name is the method and path, e.g. "GET /pets/{id}"
+func (op *Operation) Name() string {
+	return op.Method + " " + op.Path
+}
+
+// openAPIDoc mirrors the document fields read by ParseOpenAPI
+type openAPIDoc struct {
+	OpenAPI string `yaml:"openapi"`
+	Swagger string `yaml:"swagger"`
+	Info    struct {
+		Title   string `yaml:"title"`
+		Version string `yaml:"version"`
+	} `yaml:"info"`
+	Security []map[string][]string `yaml:"security"`
+	Paths    yaml.Node             `yaml:"paths"`
+}
+
+// openAPIOperation mirrors an operation object
+type openAPIOperation struct {
+	OperationID string   `yaml:"operationId"`
+	Summary     string   `yaml:"summary"`
+	Tags        []string `yaml:"tags"`
+	RequestBody *struct {
+		Content openAPIContent `yaml:"content"`
+	} `yaml:"requestBody"`
+	Responses    yaml.Node              `yaml:"responses"`
+	Security     *[]map[string][]string `yaml:"security"`
+	Callbacks    map[string]yaml.Node   `yaml:"callbacks"`
+	Dependencies []string               `yaml:"x-dependencies"`
+}
+
+// openAPIResponse mirrors a response object
+type openAPIResponse struct {
+	Description string         `yaml:"description"`
+	Content     openAPIContent `yaml:"content"`
+}
+
+// openAPIContent maps media types to their schema
+type openAPIContent map[string]struct {
+	Schema openAPISchema `yaml:"schema"`
+}
+
+// openAPISchema is enough of a schema object to name it
+type openAPISchema struct {
+	Ref   string         `yaml:"$ref"`
+	Type  string         `yaml:"type"`
+	Items *openAPISchema `yaml:"items"`
+}
+
+// name returns the component name of a $ref, or the type
+func (s openAPISchema) name() string {
+	switch {
+	case s.Ref != "":
+		return s.Ref[strings.LastIndex(s.Ref, "/")+1:]
+	case s.Type == "array" && s.Items != nil:
+		return s.Items.name() + "[]"
+	}
+	return s.Type
+}
+
+// LoadOpenAPI reads and parses an OpenAPI 3 document in YAML or JSON
+func LoadOpenAPI(path string) (*API, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	api, err := ParseOpenAPI(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return api, nil
+}
+
+// ParseOpenAPI extracts the operations of an OpenAPI 3 document. JSON is
+// parsed as YAML, which it is a subset of.
+func ParseOpenAPI(data []byte) (*API, error) {
+	var doc openAPIDoc
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.OpenAPI == "" {
+		if doc.Swagger != "" {
+			return nil, fmt.Errorf("swagger %s documents aren't supported, convert to OpenAPI 3 first", doc.Swagger)
+		}
+		return nil, fmt.Errorf("not an OpenAPI document (no openapi version)")
+	}
+
+	api := &API{Title: doc.Info.Title, Version: doc.Info.Version}
+	if api.Title == "" {
+		api.Title = "API"
+	}
+
+	for _, pair := range mappingPairs(&doc.Paths) {
+		path, item := pair[0].Value, pair[1]
+		for _, method := range httpMethods {
+			node := mappingValue(item, method)
+			if node == nil {
+				continue
+			}
+			var raw openAPIOperation
+			if err := node.Decode(&raw); err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			op, err := newOperation(strings.ToUpper(method), path, raw, doc.Security)
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", strings.ToUpper(method), path, err)
+			}
+			api.Operations = append(api.Operations, op)
+		}
+	}
+
+	if len(api.Operations) == 0 {
+		return nil, fmt.Errorf("no operations found under paths")
+	}
+	return api, nil
+}
+
+// newOperation flattens a decoded operation; global security applies
+// unless the operation sets its own
+func newOperation(method, path string, raw openAPIOperation, security []map[string][]string) (*Operation, error) {
+	op := &Operation{
+		ID:           raw.OperationID,
+		Method:       method,
+		Path:         path,
+		Summary:      raw.Summary,
+		Tags:         raw.Tags,
+		Dependencies: raw.Dependencies,
+	}
+
+	if raw.RequestBody != nil {
+		op.Request = raw.RequestBody.Content.schema()
+	}
+
+	for _, pair := range mappingPairs(&raw.Responses) {
+		var r openAPIResponse
+		if err := pair[1].Decode(&r); err != nil {
+			return nil, err
+		}
+		op.Responses = append(op.Responses, Response{Status: pair[0].Value, Description: r.Description, Schema: r.Content.schema()})
+	}
+
+	if raw.Security != nil {
+		security = *raw.Security
+	}
+	seen := make(map[string]bool)
+	for _, requirement := range security {
+		for scheme := range requirement {
+			if !seen[scheme] {
+				seen[scheme] = true
+				op.Security = append(op.Security, scheme)
+			}
+		}
+	}
+	sort.Strings(op.Security)
+
+	for name := range raw.Callbacks {
+		op.Callbacks = append(op.Callbacks, name)
+	}
+	sort.Strings(op.Callbacks)
+
+	return op, nil
+}
+
+// schema names the schema of the JSON media type, or else the first
+func (c openAPIContent) schema() string {
+	if media, ok := c["application/json"]; ok {
+		return media.Schema.name()
+	}
+	types := make([]string, 0, len(c))
+	for t := range c {
+		types = append(types, t)
+	}
+	if len(types) == 0 {
+		return ""
+	}
+	sort.Strings(types)
+	return c[types[0]].Schema.name()
+}
+
+// mappingPairs returns a YAML mapping's keys and values in document order
+func mappingPairs(node *yaml.Node) [][2]*yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	var pairs [][2]*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, [2]*yaml.Node{node.Content[i], node.Content[i+1]})
+	}
+	return pairs
+}
+
+// mappingValue returns the value for key in a YAML mapping, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for _, pair := range mappingPairs(node) {
+		if pair[0].Value == key {
+			return pair[1]
+		}
+	}
+	return nil
+}
+
+// Find returns the operations matching each selector: an operationId or a
+// path, which selects every method on it
+func (a *API) Find(selectors []string) ([]*Operation, error) {
+	var found []*Operation
+	for _, sel := range selectors {
+		matched := false
+		for _, op := range a.Operations {
+			if strings.EqualFold(op.ID, sel) || op.Path == sel {
+				found = append(found, op)
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("no operation with id or path %q", sel)
+		}
+	}
+	return found, nil
+}
+
+// Overview renders a flowchart of the endpoints grouped by tag, with
+// untagged ones under "default"
+func (a *API) Overview() string {
+	f := diagram.NewFlowchart("LR")
+	f.Node("api", strings.TrimSpace(a.Title+" "+a.Version), diagram.ShapeStadium)
+
+	for _, op := range a.Operations {
+		tags := op.Tags
+		if len(tags) == 0 {
+			tags = []string{"default"}
+		}
+		for _, tag := range tags {
+			f.Node("tag:"+tag, tag, diagram.ShapeRound)
+			f.Edge("api", "tag:"+tag, "")
+			f.Node("op:"+op.Name(), op.Name(), diagram.ShapeBox)
+			f.Edge("tag:"+tag, "op:"+op.Name(), "")
+		}
+		for _, dep := range op.Dependencies {
+			f.Node("dep:"+dep, dep, diagram.ShapeDatabase)
+			f.Edge("op:"+op.Name(), "dep:"+dep, "")
+		}
+	}
+
+	return f.String()
+}
+
+// Diagram renders a sequence diagram of the selected operations, or the
+// overview flowchart when none are selected
+func (a *API) Diagram(selectors []string) (string, error) {
+	if len(selectors) == 0 {
+		return a.Overview(), nil
+	}
+	ops, err := a.Find(selectors)
+	if err != nil {
+		return "", err
+	}
+	return a.Sequence(ops), nil
+}
+
+// Sequence renders a sequence diagram of the operations: the client's
+// request, authentication, calls to dependencies, each documented response
+// and callbacks
+func (a *API) Sequence(ops []*Operation) string {
+	lines := []string{"sequenceDiagram", "    participant Client", "    participant API as " + sequenceText(a.Title)}
+
+	// Declare everything the operations talk to, in order of first use
+	declared := make(map[string]bool)
+	for _, op := range ops {
+		for _, name := range append(append([]string{}, op.Security...), op.Dependencies...) {
+			if !declared[name] {
+				declared[name] = true
+				lines = append(lines, fmt.Sprintf("    participant %s as %s", diagram.NodeID(name), name))
+			}
+		}
+	}
+
+	for _, op := range ops {
+		title := op.Name()
+		if op.Summary != "" {
+			title += ": " + op.Summary
+		}
+		lines = append(lines, "    Note over Client,API: "+sequenceText(title))
+
+		request := op.Name()
+		if op.Request != "" {
+			request += " (" + op.Request + ")"
+		}
+		lines = append(lines, "    Client->>API: "+sequenceText(request))
+
+		for _, scheme := range op.Security {
+			lines = append(lines,
+				fmt.Sprintf("    API->>%s: check credentials", diagram.NodeID(scheme)),
+				fmt.Sprintf("    %s-->>API: ok", diagram.NodeID(scheme)))
+		}
+		for _, dep := range op.Dependencies {
+			lines = append(lines,
+				fmt.Sprintf("    API->>%s: call", diagram.NodeID(dep)),
+				fmt.Sprintf("    %s-->>API: result", diagram.NodeID(dep)))
+		}
+
+		lines = append(lines, responseLines(op.Responses)...)
+
+		for _, cb := range op.Callbacks {
+			lines = append(lines, "    API--)Client: callback "+sequenceText(cb))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// responseLines draws one response, or alternatives for several
+func responseLines(responses []Response) []string {
+	if len(responses) == 0 {
+		return []string{"    API-->>Client: response"}
+	}
+
+	text := func(r Response) string {
+		s := r.Status
+		if r.Description != "" {
+			s += " " + r.Description
+		}
+		if r.Schema != "" {
+			s += " (" + r.Schema + ")"
+		}
+		return sequenceText(s)
+	}
+
+	if len(responses) == 1 {
+		return []string{"    API-->>Client: " + text(responses[0])}
+	}
+
+	var lines []string
+	for i, r := range responses {
+		keyword := "else"
+		if i == 0 {
+			keyword = "alt"
+		}
+		lines = append(lines,
+			fmt.Sprintf("    %s %s", keyword, sequenceText(r.Status)),
+			"        API-->>Client: "+text(r))
+	}
+	return append(lines, "    end")
+}
+
+// sequenceText removes characters that end a sequence diagram message
+func sequenceText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	return strings.NewReplacer(";", ",", "#", "", "%%", "%").Replace(s)
+}
@@ -0,0 +1,162 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+)
+
+const petStore = `
+openapi: 3.0.3
+info:
+  title: Pet Store
+  version: "1.0"
+security:
+  - api_key: []
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      summary: List all pets
+      security: []
+      responses:
+        "200":
+          description: A list of pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/Pet"
+    post:
+      operationId: createPet
+      tags: [pets]
+      x-dependencies: [postgres, events]
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/Pet"
+      responses:
+        "201":
+          description: Created
+        "400":
+          description: Invalid pet
+      callbacks:
+        petAdopted:
+          "{$request.body#/callbackUrl}":
+            post:
+              responses:
+                "200": {description: ok}
+  /pets/{petId}:
+    get:
+      operationId: showPet
+      tags: [pets]
+      responses:
+        "200": {description: The pet}
+  /health:
+    get:
+      responses:
+        "204": {description: Healthy}
+`
+
+func TestParseOpenAPI(t *testing.T) {
+	api, err := ParseOpenAPI([]byte(petStore))
+	if err != nil {
+		t.Fatalf("ParseOpenAPI() error = %v", err)
+	}
+
+	if api.Title != "Pet Store" || api.Version != "1.0" {
+		t.Errorf("Title, Version = %q, %q", api.Title, api.Version)
+	}
+	var names []string
+	for _, op := range api.Operations {
+		names = append(names, op.Name())
+	}
+	if got := strings.Join(names, ", "); got != "GET /pets, POST /pets, GET /pets/{petId}, GET /health" {
+		t.Errorf("Operations = %s, want document order", got)
+	}
+
+	list, create := api.Operations[0], api.Operations[1]
+	if len(list.Security) != 0 || list.Responses[0].Schema != "Pet[]" {
+		t.Errorf("listPets = %+v, want no security and an array response", list)
+	}
+	if create.Request != "Pet" || strings.Join(create.Security, ",") != "api_key" ||
+		strings.Join(create.Dependencies, ",") != "postgres,events" || strings.Join(create.Callbacks, ",") != "petAdopted" {
+		t.Errorf("createPet = %+v", create)
+	}
+	if len(create.Responses) != 2 || create.Responses[1].Status != "400" {
+		t.Errorf("createPet responses = %+v, want 201 then 400", create.Responses)
+	}
+}
+
+func TestParseOpenAPI_JSON(t *testing.T) {
+	doc := `{"openapi": "3.1.0", "info": {"title": "Ping"}, "paths": {"/ping": {"get": {"responses": {"200": {"description": "pong"}}}}}}`
+	api, err := ParseOpenAPI([]byte(doc))
+	if err != nil {
+		t.Fatalf("ParseOpenAPI() error = %v", err)
+	}
+	if len(api.Operations) != 1 || api.Operations[0].Name() != "GET /ping" {
+		t.Errorf("Operations = %+v", api.Operations)
+	}
+}
+
+func TestParseOpenAPI_Errors(t *testing.T) {
+	tests := map[string]string{
+		"swagger":       "swagger: \"2.0\"\npaths: {}\n",
+		"no openapi":    "info: {title: x}\n",
+		"no operations": "openapi: 3.0.0\npaths: {}\n",
+		"invalid":       "openapi: [",
+	}
+	for name, doc := range tests {
+		if _, err := ParseOpenAPI([]byte(doc)); err == nil {
+			t.Errorf("%s: ParseOpenAPI() should fail", name)
+		}
+	}
+}
+
+func TestAPI_Diagram(t *testing.T) {
+	api, err := ParseOpenAPI([]byte(petStore))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	overview, err := api.Diagram(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`api(["Pet Store 1.0"])`,
+		"api --> tag_pets",
+		`op_GET_pets_petId["GET /pets/{petId}"]`,
+		"tag_default --> op_GET_health",
+		"op_POST_pets --> dep_postgres",
+	} {
+		if !strings.Contains(overview, want) {
+			t.Errorf("overview missing %q:\n%s", want, overview)
+		}
+	}
+
+	sequence, err := api.Diagram([]string{"createPet", "/pets/{petId}"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"sequenceDiagram\n    participant Client\n    participant API as Pet Store\n    participant api_key as api_key",
+		"Client->>API: POST /pets (Pet)",
+		"API->>api_key: check credentials",
+		"API->>postgres: call",
+		"    alt 201\n        API-->>Client: 201 Created\n    else 400\n        API-->>Client: 400 Invalid pet\n    end",
+		"API--)Client: callback petAdopted",
+		"Client->>API: GET /pets/{petId}",
+		"API-->>Client: 200 The pet",
+	} {
+		if !strings.Contains(sequence, want) {
+			t.Errorf("sequence missing %q:\n%s", want, sequence)
+		}
+	}
+
+	if _, err := api.Diagram([]string{"deletePet"}); err == nil {
+		t.Error("Diagram() of an unknown operation should fail")
+	}
+}
@@ -0,0 +1,537 @@
+// Package importer turns descriptions of a system kept outside the code,
+// like database schemas, git history and deployment manifests, into
+// mermaid diagrams
+package importer
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mnesler/hauk-tui/internal/diagram"
+)
+
+// Schema is the tables and foreign keys declared by SQL DDL
+type Schema struct {
+	Tables      []*Table // In declaration order
+	ForeignKeys []ForeignKey
+}
+
+// Table is a CREATE TABLE statement
+type Table struct {
+	Name    string
+	Columns []*Column
+}
+
+// Column is one column of a table
+type Column struct {
+	Name       string
+	Type       string
+	PrimaryKey bool
+	ForeignKey bool
+	Unique     bool
+	NotNull    bool
+}
+
+// ForeignKey links columns of Table to RefTable
+type ForeignKey struct {
+	Table      string
+	Columns    []string
+	RefTable   string
+	RefColumns []string // Empty when the primary key is implied
+}
+
+// LoadSQL reads and parses a DDL file
+func LoadSQL(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s, err := ParseSQL(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return s, nil
+}
+
+// ParseSQL extracts tables, columns, primary keys and foreign keys from
+// CREATE TABLE and ALTER TABLE ... ADD statements. It understands the
+// common subset of Postgres, MySQL and SQLite; other statements are skipped.
+func ParseSQL(src string) (*Schema, error) {
+	s := &Schema{}
+	for _, stmt := range splitStatements(lexSQL(src)) {
+		switch {
+		case stmt.keyword(0, "CREATE"):
+			s.createTable(stmt)
+		case stmt.keyword(0, "ALTER") && stmt.keyword(1, "TABLE"):
+			s.alterTable(stmt[2:])
+		}
+	}
+
+	if len(s.Tables) == 0 {
+		return nil, fmt.Errorf("no CREATE TABLE statements found")
+	}
+	return s, nil
+}
+
+// Mermaid renders the schema as an erDiagram
+func (s *Schema) Mermaid() string {
+	lines := []string{"erDiagram"}
+
+	for _, t := range s.Tables {
+		lines = append(lines, fmt.Sprintf("    %s {", diagram.NodeID(t.Name)))
+		for _, c := range t.Columns {
+			line := fmt.Sprintf("        %s %s", erType(c.Type), diagram.NodeID(c.Name))
+			if keys := c.keys(); keys != "" {
+				line += " " + keys
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "    }")
+	}
+
+	for _, fk := range s.ForeignKeys {
+		lines = append(lines, fmt.Sprintf("    %s %s--%s %s : %q",
+			diagram.NodeID(fk.RefTable), s.parentCardinality(fk),
+			s.childCardinality(fk), diagram.NodeID(fk.Table),
+			strings.Join(fk.Columns, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// table returns the table called name, ignoring case and schema prefixes
+func (s *Schema) table(name string) *Table {
+	for _, t := range s.Tables {
+		if strings.EqualFold(t.Name, name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// parentCardinality is exactly one when every foreign key column is NOT
+// NULL, otherwise zero or one
+func (s *Schema) parentCardinality(fk ForeignKey) string {
+	t := s.table(fk.Table)
+	for _, name := range fk.Columns {
+		if c := t.column(name); c == nil || !(c.NotNull || c.PrimaryKey) {
+			return "|o"
+		}
+	}
+	return "||"
+}
+
+// childCardinality is at most one when the foreign key is also the
+// primary key or a unique column, otherwise many
+func (s *Schema) childCardinality(fk ForeignKey) string {
+	t := s.table(fk.Table)
+	if len(fk.Columns) == 1 {
+		if c := t.column(fk.Columns[0]); c != nil && c.Unique {
+			return "o|"
+		}
+	}
+
+	var pk []string
+	for _, c := range t.Columns {
+		if c.PrimaryKey {
+			pk = append(pk, strings.ToLower(c.Name))
+		}
+	}
+	var cols []string
+	for _, name := range fk.Columns {
+		cols = append(cols, strings.ToLower(name))
+	}
+	if len(pk) > 0 && strings.Join(pk, ",") == strings.Join(cols, ",") {
+		return "o|"
+	}
+	return "o{"
+}
+
+// column returns the column called name, ignoring case
+func (t *Table) column(name string) *Column {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// keys returns the erDiagram key markers for a column
+func (c *Column) keys() string {
+	var keys []string
+	if c.PrimaryKey {
+		keys = append(keys, "PK")
+	}
+	if c.ForeignKey {
+		keys = append(keys, "FK")
+	}
+	if c.Unique && !c.PrimaryKey {
+		keys = append(keys, "UK")
+	}
+	return strings.Join(keys, ", ")
+}
+
+// erType turns a SQL type into an erDiagram attribute type, which can't
+// contain spaces or commas
+func erType(sqlType string) string {
+	if sqlType == "" {
+		return "unknown"
+	}
+	return strings.NewReplacer(" (", "(", ", ", "-", ",", "-", " ", "_").Replace(strings.ToLower(sqlType))
+}
+
+// createTable handles CREATE [TEMPORARY] TABLE [IF NOT EXISTS] name (...)
+func (s *Schema) createTable(stmt sqlTokens) {
+	i := 1
+	for i < len(stmt) && !stmt.keyword(i, "TABLE") {
+		if !stmt.keyword(i, "OR", "REPLACE", "TEMP", "TEMPORARY", "UNLOGGED", "GLOBAL", "LOCAL") {
+			return // CREATE INDEX, VIEW, ...
+		}
+		i++
+	}
+	i++
+	if stmt.keyword(i, "IF") {
+		i += 3 // IF NOT EXISTS
+	}
+
+	name, i := stmt.name(i)
+	if name == "" || i >= len(stmt) || stmt[i].text != "(" {
+		return // CREATE TABLE ... AS SELECT
+	}
+
+	t := &Table{Name: name}
+	s.Tables = append(s.Tables, t)
+	for _, def := range stmt.group(i) {
+		s.tableElement(t, def)
+	}
+}
+
+// alterTable handles ALTER TABLE name ADD ... for columns and constraints
+func (s *Schema) alterTable(stmt sqlTokens) {
+	i := 0
+	for stmt.keyword(i, "ONLY", "IF", "EXISTS") {
+		i++
+	}
+	name, i := stmt.name(i)
+	t := s.table(name)
+	if t == nil {
+		return
+	}
+
+	for _, action := range stmt[i:].split(",") {
+		if !action.keyword(0, "ADD") {
+			continue
+		}
+		action = action[1:]
+		if action.keyword(0, "COLUMN") {
+			action = action[1:]
+		}
+		s.tableElement(t, action)
+	}
+}
+
+// tableElement handles one column or constraint inside CREATE TABLE (...)
+func (s *Schema) tableElement(t *Table, def sqlTokens) {
+	if def.keyword(0, "CONSTRAINT") {
+		if len(def) <= 2 {
+			return // Unnamed or cut off
+		}
+		def = def[2:]
+	}
+	if len(def) == 0 {
+		return
+	}
+
+	switch {
+	case def.keyword(0, "PRIMARY"):
+		for _, name := range def.columnList(2) {
+			if c := t.column(name); c != nil {
+				c.PrimaryKey = true
+			}
+		}
+
+	case def.keyword(0, "FOREIGN"):
+		cols := def.columnList(2)
+		if ref := def.find("REFERENCES"); ref > 0 {
+			s.addForeignKey(t, cols, def[ref+1:])
+		}
+
+	case def.keyword(0, "UNIQUE"):
+		open := def.find("(")
+		if cols := def.columnList(open); len(cols) == 1 {
+			if c := t.column(cols[0]); c != nil {
+				c.Unique = true
+			}
+		}
+
+	case def.keyword(0, "KEY", "INDEX", "CHECK", "FULLTEXT", "SPATIAL", "EXCLUDE", "LIKE", "PERIOD"):
+		// Indexes and checks don't appear in the diagram
+
+	default:
+		s.column(t, def)
+	}
+}
+
+// column parses "name type [constraints]"
+func (s *Schema) column(t *Table, def sqlTokens) {
+	if !def[0].ident {
+		return
+	}
+	c := &Column{Name: def[0].text}
+	t.Columns = append(t.Columns, c)
+
+	// The type runs until the first constraint keyword
+	i := 1
+	var typ []string
+	for i < len(def) && !def.keyword(i, columnConstraints...) {
+		if def[i].text == "(" {
+			end := def.closing(i)
+			if end <= i {
+				break // Unclosed at the end of a truncated definition
+			}
+			typ = append(typ, "("+strings.Join(def[i+1:end].texts(), ", ")+")")
+			i = end + 1
+			continue
+		}
+		typ = append(typ, def[i].text)
+		i++
+	}
+	c.Type = strings.Join(typ, " ")
+
+	for ; i < len(def); i++ {
+		switch {
+		case def.keyword(i, "PRIMARY"):
+			c.PrimaryKey, c.NotNull = true, true
+		case def.keyword(i, "NOT") && def.keyword(i+1, "NULL"):
+			c.NotNull = true
+		case def.keyword(i, "UNIQUE"):
+			c.Unique = true
+		case def.keyword(i, "REFERENCES"):
+			s.addForeignKey(t, []string{c.Name}, def[i+1:])
+			return
+		case def[i].text == "(":
+			i = def.closing(i) // Skip DEFAULT (...) and CHECK (...)
+		}
+	}
+}
+
+// addForeignKey records a foreign key from "REFERENCES table [(cols)]"
+func (s *Schema) addForeignKey(t *Table, cols []string, ref sqlTokens) {
+	refTable, i := ref.name(0)
+	if refTable == "" || len(cols) == 0 {
+		return
+	}
+
+	var refCols []string
+	if i < len(ref) && ref[i].text == "(" {
+		refCols = ref.columnList(i)
+	}
+	for _, name := range cols {
+		if c := t.column(name); c != nil {
+			c.ForeignKey = true
+		}
+	}
+	s.ForeignKeys = append(s.ForeignKeys, ForeignKey{Table: t.Name, Columns: cols, RefTable: refTable, RefColumns: refCols})
+}
+
+// columnConstraints end a column's type
+var columnConstraints = []string{
+	"NOT", "NULL", "DEFAULT", "PRIMARY", "REFERENCES", "UNIQUE", "CHECK",
+	"CONSTRAINT", "AUTO_INCREMENT", "AUTOINCREMENT", "COLLATE", "GENERATED",
+	"COMMENT", "ON", "IDENTITY", "AS",
+}
+
+// sqlToken is a word, quoted identifier, string literal or punctuation
+type sqlToken struct {
+	text  string
+	word  bool // Unquoted word, which may be a keyword
+	ident bool // Word or quoted identifier
+}
+
+type sqlTokens []sqlToken
+
+// lexSQL splits DDL into tokens, dropping comments
+func lexSQL(src string) sqlTokens {
+	var tokens sqlTokens
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+
+		case strings.HasPrefix(src[i:], "--") || c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+
+		case c == '\'' || c == '"' || c == '`' || (c == '[' && i+1 < len(src) && src[i+1] != ']'):
+			closer := c
+			if c == '[' {
+				closer = ']'
+			}
+			j := i + 1
+			var b strings.Builder
+			for j < len(src) {
+				if src[j] == closer {
+					// Doubled quotes escape themselves
+					if j+1 < len(src) && src[j+1] == closer && closer != ']' {
+						b.WriteByte(closer)
+						j += 2
+						continue
+					}
+					break
+				}
+				b.WriteByte(src[j])
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: b.String(), ident: c != '\''})
+			i = j + 1
+
+		case isWordByte(c):
+			j := i
+			for j < len(src) && isWordByte(src[j]) {
+				j++
+			}
+			tokens = append(tokens, sqlToken{text: src[i:j], word: true, ident: true})
+			i = j
+
+		default:
+			tokens = append(tokens, sqlToken{text: string(c)})
+			i++
+		}
+	}
+	return tokens
+}
+
+// isWordByte reports whether c can be part of an unquoted word
+func isWordByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// splitStatements splits tokens on semicolons
+func splitStatements(tokens sqlTokens) []sqlTokens {
+	return tokens.split(";")
+}
+
+// split splits tokens on a punctuation mark outside parentheses, dropping
+// empty parts
+func (ts sqlTokens) split(sep string) []sqlTokens {
+	var parts []sqlTokens
+	depth, start := 0, 0
+	for i, t := range ts {
+		switch {
+		case t.text == "(" && !t.ident:
+			depth++
+		case t.text == ")" && !t.ident:
+			depth--
+		case t.text == sep && !t.ident && depth == 0:
+			if i > start {
+				parts = append(parts, ts[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(ts) {
+		parts = append(parts, ts[start:])
+	}
+	return parts
+}
+
+// keyword reports whether token i is an unquoted word matching one of
+// keywords, ignoring case
+func (ts sqlTokens) keyword(i int, keywords ...string) bool {
+	if i < 0 || i >= len(ts) || !ts[i].word {
+		return false
+	}
+	for _, k := range keywords {
+		if strings.EqualFold(ts[i].text, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the index of the first keyword or punctuation token
+// matching text, or -1
+func (ts sqlTokens) find(text string) int {
+	for i, t := range ts {
+		if strings.EqualFold(t.text, text) && (t.word || !t.ident) {
+			return i
+		}
+	}
+	return -1
+}
+
+// name reads a possibly schema-qualified name at i, returning its last
+// part and the index after it
+func (ts sqlTokens) name(i int) (string, int) {
+	if i >= len(ts) || !ts[i].ident {
+		return "", i
+	}
+	name := ts[i].text
+	i++
+	for i+1 < len(ts) && ts[i].text == "." && ts[i+1].ident {
+		name = ts[i+1].text
+		i += 2
+	}
+	return name, i
+}
+
+// closing returns the index of the parenthesis closing the one at open
+func (ts sqlTokens) closing(open int) int {
+	depth := 0
+	for i := open; i < len(ts); i++ {
+		switch {
+		case ts[i].text == "(" && !ts[i].ident:
+			depth++
+		case ts[i].text == ")" && !ts[i].ident:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(ts) - 1
+}
+
+// group returns the comma-separated elements inside the parentheses
+// opening at open
+func (ts sqlTokens) group(open int) []sqlTokens {
+	if open < 0 || open >= len(ts) || ts[open].text != "(" {
+		return nil
+	}
+	return ts[open+1 : ts.closing(open)].split(",")
+}
+
+// columnList returns the names in "(a, b)" at open
+func (ts sqlTokens) columnList(open int) []string {
+	var names []string
+	for _, part := range ts.group(open) {
+		if part[0].ident {
+			names = append(names, part[0].text)
+		}
+	}
+	return names
+}
+
+// texts joins each token group back into text
+func (ts sqlTokens) texts() []string {
+	var out []string
+	for _, part := range ts.split(",") {
+		var words []string
+		for _, t := range part {
+			words = append(words, t.text)
+		}
+		out = append(out, strings.Join(words, " "))
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const shopSchema = `-- Postgres
+CREATE TABLE IF NOT EXISTS public.users (
+  id SERIAL PRIMARY KEY,
+  email VARCHAR(255) NOT NULL UNIQUE,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT now()
+);
+
+/* MySQL */
+CREATE TABLE ` + "`orders`" + ` (
+  ` + "`id`" + ` INT NOT NULL AUTO_INCREMENT,
+  ` + "`user_id`" + ` INT NOT NULL,
+  total DECIMAL(10, 2) DEFAULT (0),
+  note TEXT DEFAULT 'a, b; c',
+  PRIMARY KEY (` + "`id`" + `),
+  KEY idx_user (user_id),
+  CONSTRAINT fk_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB;
+
+-- SQLite
+CREATE TABLE profiles (user_id INTEGER PRIMARY KEY REFERENCES users(id), bio TEXT);
+
+-- pg_dump style
+CREATE TABLE items (id integer, order_id integer, sku text);
+ALTER TABLE ONLY items ADD CONSTRAINT items_pkey PRIMARY KEY (id);
+ALTER TABLE items ADD CONSTRAINT fk_order FOREIGN KEY (order_id) REFERENCES orders(id), ADD COLUMN qty int NOT NULL;
+CREATE INDEX items_sku ON items (sku);
+CREATE VIEW big_orders AS SELECT * FROM orders;
+`
+
+func TestParseSQL(t *testing.T) {
+	s, err := ParseSQL(shopSchema)
+	if err != nil {
+		t.Fatalf("ParseSQL() error = %v", err)
+	}
+
+	var names []string
+	for _, table := range s.Tables {
+		names = append(names, table.Name)
+	}
+	if got := strings.Join(names, " "); got != "users orders profiles items" {
+		t.Errorf("Tables = %s", got)
+	}
+
+	tests := []struct {
+		table, column, typ string
+		pk, fk, unique     bool
+	}{
+		{"users", "id", "SERIAL", true, false, false},
+		{"users", "email", "VARCHAR (255)", false, false, true},
+		{"users", "created_at", "TIMESTAMP WITH TIME ZONE", false, false, false},
+		{"orders", "id", "INT", true, false, false},
+		{"orders", "user_id", "INT", false, true, false},
+		{"orders", "total", "DECIMAL (10, 2)", false, false, false},
+		{"orders", "note", "TEXT", false, false, false},
+		{"profiles", "user_id", "INTEGER", true, true, false},
+		{"items", "id", "integer", true, false, false},
+		{"items", "qty", "int", false, false, false},
+	}
+	for _, tt := range tests {
+		c := s.table(tt.table).column(tt.column)
+		if c == nil {
+			t.Errorf("%s.%s missing", tt.table, tt.column)
+			continue
+		}
+		if c.Type != tt.typ || c.PrimaryKey != tt.pk || c.ForeignKey != tt.fk || c.Unique != tt.unique {
+			t.Errorf("%s.%s = %+v", tt.table, tt.column, c)
+		}
+	}
+
+	if len(s.ForeignKeys) != 3 {
+		t.Fatalf("ForeignKeys = %+v, want 3", s.ForeignKeys)
+	}
+	if fk := s.ForeignKeys[2]; fk.Table != "items" || fk.RefTable != "orders" || fk.RefColumns[0] != "id" {
+		t.Errorf("ALTER TABLE foreign key = %+v", fk)
+	}
+}
+
+func TestSchema_Mermaid(t *testing.T) {
+	s, err := ParseSQL(shopSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := s.Mermaid()
+	for _, want := range []string{
+		"erDiagram",
+		"    users {\n        serial id PK\n        varchar(255) email UK\n        timestamp_with_time_zone created_at\n    }",
+		"decimal(10-2) total",
+		"integer user_id PK, FK",
+		`users ||--o{ orders : "user_id"`,   // NOT NULL, many orders
+		`users ||--o| profiles : "user_id"`, // Shared primary key
+		`orders |o--o{ items : "order_id"`,  // Nullable
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Mermaid() missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestLoadSQL_Errors(t *testing.T) {
+	if _, err := LoadSQL(filepath.Join(t.TempDir(), "missing.sql")); err == nil {
+		t.Error("LoadSQL() of a missing file should fail")
+	}
+
+	path := filepath.Join(t.TempDir(), "views.sql")
+	if err := os.WriteFile(path, []byte("CREATE VIEW v AS SELECT 1;"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadSQL(path); err == nil || !strings.Contains(err.Error(), "no CREATE TABLE") {
+		t.Errorf("LoadSQL() error = %v, want no tables", err)
+	}
+}
+
+func TestParseSQL_Truncated(t *testing.T) {
+	// Half-written schemas mustn't crash the import
+	for _, src := range []string{
+		"CREATE TABLE t (a int, CONSTRAINT);",
+		"CREATE TABLE t (a varchar((",
+		"CREATE TABLE t (a int); ALTER TABLE t ADD CONSTRAINT",
+	} {
+		s, err := ParseSQL(src)
+		if err != nil {
+			t.Errorf("ParseSQL(%q) error = %v", src, err)
+			continue
+		}
+		if len(s.Tables) != 1 || len(s.Tables[0].Columns) != 1 || s.Tables[0].Columns[0].Name != "a" {
+			t.Errorf("ParseSQL(%q) = %+v, want table t with column a", src, s.Tables)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"sync"
 
 	"github.com/sirupsen/logrus"
@@ -186,7 +187,7 @@ func Component(name string) *logrus.Entry {
 	if Log == nil {
 		// Return a dummy entry if logger not initialized
 		l := logrus.New()
-		l.Out = nil // Discard output
+		l.Out = io.Discard
 		return l.WithField("component", name)
 	}
 	return Log.WithField("component", name)
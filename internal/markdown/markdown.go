@@ -0,0 +1,266 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mnesler/hauk-tui/internal/highlight"
+	"github.com/mnesler/hauk-tui/internal/ui"
+)
+
+// Block-level patterns
+var (
+	headingPattern   = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	bulletPattern    = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	orderedPattern   = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	quotePattern     = regexp.MustCompile(`^>\s?(.*)$`)
+	rulePattern      = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	fenceOpenPattern = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w+-]*)")
+)
+
+// Inline patterns, tried in order at each position
+var (
+	codeSpanPattern = regexp.MustCompile("^`([^`]+)`")
+	boldPattern     = regexp.MustCompile(`^(\*\*|__)(\S(?:.*?\S)?)(\*\*|__)`)
+	italicPattern   = regexp.MustCompile(`^(\*|_)(\S(?:.*?\S)?)(\*|_)`)
+	linkPattern     = regexp.MustCompile(`^\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// CodeRenderer renders the lines of a fenced code block. lang is the info
+// string after the opening fence, possibly empty.
+type CodeRenderer func(lang string, lines []string) string
+
+// Renderer turns markdown into themed terminal text
+type Renderer struct {
+	Width int            // Wrap width for prose
+	Code  CodeRenderer   // Renders fenced code blocks
+	Bg    lipgloss.Color // Background the text is drawn on
+}
+
+// NewRenderer creates a renderer that wraps to width on the chat background
+func NewRenderer(width int) *Renderer {
+	return &Renderer{
+		Width: width,
+		Code:  RenderCode,
+		Bg:    ui.ActiveTheme.ChatBg,
+	}
+}
+
+// Render renders markdown source to styled text wrapped to width
+func Render(src string, width int) string {
+	return NewRenderer(width).Render(src)
+}
+
+// RenderCode renders a code block with the theme's code style, highlighting
+// languages the highlighter supports
+func RenderCode(lang string, lines []string) string {
+	bg := ui.ActiveTheme.ChatBg
+	style := ui.GetCodeStyle().
+		Background(bg).
+		MarginBackground(bg)
+
+	highlighted := highlight.Highlight(lang, lines, highlight.ThemePalette(ui.ActiveTheme, bg))
+	for i, line := range highlighted {
+		highlighted[i] = style.Render(line)
+	}
+	return strings.Join(highlighted, "\n")
+}
+
+// Render renders markdown source to styled text
+func (r *Renderer) Render(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+
+	var out []string
+	var paragraph []string
+
+	// flush emits the pending paragraph as one wrapped block
+	flush := func() {
+		if len(paragraph) > 0 {
+			out = append(out, r.wrap(r.inline(strings.Join(paragraph, " "), r.textStyle()), "", ""))
+			paragraph = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		// Fenced code block: collect until the matching closing fence
+		if match := fenceOpenPattern.FindStringSubmatch(line); match != nil {
+			flush()
+			fence := match[1]
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			// An empty rendering drops the block entirely
+			if rendered := r.Code(match[2], code); rendered != "" {
+				out = append(out, rendered)
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			flush()
+			// Keep a single blank line between blocks
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+
+		case headingPattern.MatchString(trimmed):
+			flush()
+			match := headingPattern.FindStringSubmatch(trimmed)
+			style := r.textStyle().Bold(true).Foreground(ui.ActiveTheme.AccentAgent)
+			if len(match[1]) > 2 {
+				style = style.Foreground(ui.ActiveTheme.TextPrimary)
+			}
+			out = append(out, r.wrap(r.inline(match[2], style), "", ""))
+
+		case rulePattern.MatchString(line):
+			flush()
+			out = append(out, r.mutedStyle().Render(strings.Repeat("─", r.Width)))
+
+		case bulletPattern.MatchString(line):
+			flush()
+			match := bulletPattern.FindStringSubmatch(line)
+			indent := strings.Repeat(" ", len(match[1]))
+			out = append(out, r.wrap(r.inline(match[2], r.textStyle()), indent+"• ", indent+"  "))
+
+		case orderedPattern.MatchString(line):
+			flush()
+			match := orderedPattern.FindStringSubmatch(line)
+			indent := strings.Repeat(" ", len(match[1]))
+			marker := match[2] + " "
+			out = append(out, r.wrap(r.inline(match[3], r.textStyle()), indent+marker, indent+strings.Repeat(" ", len(marker))))
+
+		case quotePattern.MatchString(trimmed):
+			flush()
+			match := quotePattern.FindStringSubmatch(trimmed)
+			out = append(out, r.wrap(r.inline(match[1], r.mutedStyle().Italic(true)), "│ ", "│ "))
+
+		default:
+			paragraph = append(paragraph, trimmed)
+		}
+	}
+	flush()
+
+	// Drop trailing blank lines
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+
+	return strings.Join(out, "\n")
+}
+
+// textStyle is the base style for prose
+func (r *Renderer) textStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.TextPrimary).
+		Background(r.Bg)
+}
+
+// mutedStyle is the style for quotes, rules and link targets
+func (r *Renderer) mutedStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(ui.ActiveTheme.TextMuted).
+		Background(r.Bg)
+}
+
+// wrap wraps styled text to the renderer width, prefixing the first line
+// with first and continuation lines with rest
+func (r *Renderer) wrap(text, first, rest string) string {
+	width := r.Width - lipgloss.Width(first)
+	if width < 1 {
+		width = 1
+	}
+
+	wrapped := lipgloss.NewStyle().Width(width).Render(text)
+	lines := strings.Split(wrapped, "\n")
+
+	prefixStyle := r.textStyle().Foreground(ui.ActiveTheme.TextSecondary)
+	for i, line := range lines {
+		prefix := rest
+		if i == 0 {
+			prefix = first
+		}
+		// Trailing padding from the wrap isn't needed inside a bubble
+		lines[i] = prefixStyle.Render(prefix) + strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// inline renders emphasis, code spans and links within a line of text
+func (r *Renderer) inline(text string, base lipgloss.Style) string {
+	var b strings.Builder
+	var plain strings.Builder
+
+	// emit writes pending plain text in the base style
+	emit := func() {
+		if plain.Len() > 0 {
+			b.WriteString(base.Render(plain.String()))
+			plain.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		// Emphasis markers must not start in the middle of a word (snake_case)
+		atWordStart := i == 0 || !isWordByte(text[i-1])
+
+		switch {
+		case codeSpanPattern.MatchString(rest):
+			match := codeSpanPattern.FindStringSubmatch(rest)
+			emit()
+			b.WriteString(base.Foreground(ui.ActiveTheme.AccentCode).Render(match[1]))
+			i += len(match[0])
+
+		case atWordStart && boldPattern.MatchString(rest):
+			match := boldPattern.FindStringSubmatch(rest)
+			if match[1] != match[3] {
+				plain.WriteByte(text[i])
+				i++
+				continue
+			}
+			emit()
+			b.WriteString(r.inline(match[2], base.Bold(true)))
+			i += len(match[0])
+
+		case atWordStart && italicPattern.MatchString(rest):
+			match := italicPattern.FindStringSubmatch(rest)
+			if match[1] != match[3] {
+				plain.WriteByte(text[i])
+				i++
+				continue
+			}
+			emit()
+			b.WriteString(r.inline(match[2], base.Italic(true)))
+			i += len(match[0])
+
+		case linkPattern.MatchString(rest):
+			match := linkPattern.FindStringSubmatch(rest)
+			emit()
+			b.WriteString(base.Underline(true).Foreground(ui.ActiveTheme.AccentUser).Render(match[1]))
+			b.WriteString(r.mutedStyle().Render(" (" + match[2] + ")"))
+			i += len(match[0])
+
+		default:
+			plain.WriteByte(text[i])
+			i++
+		}
+	}
+	emit()
+
+	return b.String()
+}
+
+// isWordByte reports whether c is part of a word for emphasis purposes
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,118 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// plain renders src and strips styling so tests can compare text
+func plain(src string, width int) string {
+	return ansiPattern.ReplaceAllString(Render(src, width), "")
+}
+
+func TestRender_Blocks(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"heading", "## Overview", "Overview"},
+		{"bullet", "- first\n* second", "• first\n• second"},
+		{"nested bullet", "- outer\n  - inner", "• outer\n  • inner"},
+		{"ordered", "1. one\n2. two", "1. one\n2. two"},
+		{"quote", "> note this", "│ note this"},
+		{"paragraph joins lines", "one\ntwo", "one two"},
+		{"blank lines collapse", "one\n\n\n\ntwo", "one\n\ntwo"},
+		{"rule", "---", strings.Repeat("─", 20)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := plain(tt.src, 20); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_Inline(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"bold", "a **bold** word", "a bold word"},
+		{"bold underscores", "a __bold__ word", "a bold word"},
+		{"italic", "an *italic* word", "an italic word"},
+		{"code span", "run `go test`", "run go test"},
+		{"link", "see [docs](https://example.com)", "see docs (https://example.com)"},
+		{"snake_case untouched", "call my_func_name", "call my_func_name"},
+		{"unmatched marker", "2 * 3 = 6", "2 * 3 = 6"},
+		{"code keeps markers", "`**not bold**`", "**not bold**"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := plain(tt.src, 80); got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRender_CodeBlock(t *testing.T) {
+	src := "Here it is:\n\n```mermaid\ngraph TD\n    A --> B\n```\n\nDone."
+
+	var gotLang string
+	var gotLines []string
+	r := NewRenderer(40)
+	r.Code = func(lang string, lines []string) string {
+		gotLang = lang
+		gotLines = lines
+		return strings.Join(lines, "\n")
+	}
+	got := ansiPattern.ReplaceAllString(r.Render(src), "")
+
+	if gotLang != "mermaid" {
+		t.Errorf("Code block lang = %q, want %q", gotLang, "mermaid")
+	}
+	if len(gotLines) != 2 || gotLines[1] != "    A --> B" {
+		t.Errorf("Code block lines = %q, want source lines verbatim", gotLines)
+	}
+	want := "Here it is:\n\ngraph TD\n    A --> B\n\nDone."
+	if got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestRender_UnclosedFence(t *testing.T) {
+	got := plain("```go\nfunc main() {}", 40)
+	if !strings.Contains(got, "func main() {}") {
+		t.Errorf("Render() = %q, want code from unclosed fence", got)
+	}
+}
+
+func TestRender_Wraps(t *testing.T) {
+	src := "- " + strings.Repeat("word ", 20)
+	out := Render(src, 30)
+
+	lines := strings.Split(out, "\n")
+	if len(lines) < 2 {
+		t.Fatalf("Render() produced %d lines, want wrapped output", len(lines))
+	}
+	for i, line := range lines {
+		if w := lipgloss.Width(line); w > 30 {
+			t.Errorf("Line %d width = %d, want <= 30", i, w)
+		}
+	}
+
+	// Continuation lines hang under the bullet text
+	if second := ansiPattern.ReplaceAllString(lines[1], ""); !strings.HasPrefix(second, "  word") {
+		t.Errorf("Continuation line = %q, want hanging indent", second)
+	}
+}
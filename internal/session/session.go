@@ -0,0 +1,132 @@
+package session
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mnesler/hauk-tui/internal/chat"
+	"github.com/mnesler/hauk-tui/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Session is a saved conversation, including every branch
+type Session struct {
+	Name         string     `yaml:"name"`
+	SavedAt      time.Time  `yaml:"saved_at"`
+	Diagram      string     `yaml:"diagram,omitempty"` // Current diagram when saved
+	Conversation *chat.Tree `yaml:"conversation"`
+}
+
+// Dir returns the directory sessions are saved in
+func Dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "sessions"), nil
+}
+
+// DefaultName returns a timestamp-based session name
+func DefaultName(t time.Time) string {
+	return t.Format("2006-01-02-150405")
+}
+
+// Path returns the file a session with the given name is stored in
+func Path(name string) (string, error) {
+	if err := validateName(name); err != nil {
+		return "", err
+	}
+
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".yaml"), nil
+}
+
+// Save writes the session to disk, overwriting any session with the same name
+func Save(s *Session) error {
+	path, err := Path(s.Name)
+	if err != nil {
+		return err
+	}
+
+	if mkdirErr := os.MkdirAll(filepath.Dir(path), 0755); mkdirErr != nil {
+		return fmt.Errorf("failed to create sessions directory: %w", mkdirErr)
+	}
+
+	s.SavedAt = time.Now()
+	data, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("failed to marshal session: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write session file: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the session with the given name
+func Load(name string) (*Session, error) {
+	path, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session %q: %w", name, err)
+	}
+
+	var s Session
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse session %q: %w", name, err)
+	}
+	if s.Conversation == nil {
+		s.Conversation = chat.NewTree()
+	}
+	s.Name = name
+
+	return &s, nil
+}
+
+// List returns the names of all saved sessions, sorted
+func List() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sessions directory: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yaml"))
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// validateName rejects names that would escape the sessions directory
+func validateName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid session name %q", name)
+	}
+	return nil
+}
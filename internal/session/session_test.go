@@ -0,0 +1,100 @@
+package session
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mnesler/hauk-tui/internal/chat"
+)
+
+func TestSaveAndLoad_KeepsBranches(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	tree := chat.NewTree()
+	user := tree.Append(chat.NewMessage(chat.RoleUser, "draw it"))
+	first := tree.Append(chat.NewMessage(chat.RoleAgent, "first answer"))
+	tree.Branch(first.ID, chat.NewMessage(chat.RoleAgent, "second answer"))
+
+	s := &Session{Name: "demo", Diagram: "graph TD\n    A --> B", Conversation: tree}
+	if err := Save(s); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load("demo")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if loaded.Diagram != s.Diagram {
+		t.Errorf("Loaded Diagram = %q, want %q", loaded.Diagram, s.Diagram)
+	}
+
+	messages := loaded.Conversation.Messages()
+	if len(messages) != 2 || messages[1].Content != "second answer" {
+		t.Fatalf("Loaded active branch = %+v, want user message and second answer", messages)
+	}
+
+	// The earlier answer is still reachable as a sibling
+	last := loaded.Conversation.Last()
+	if index, count := loaded.Conversation.Siblings(last.ID); index != 1 || count != 2 {
+		t.Errorf("Siblings() = %d, %d, want 1, 2", index, count)
+	}
+	if loaded.Conversation.SelectSibling(last.ID, -1) == nil {
+		t.Fatal("SelectSibling(-1) = nil, want the first answer")
+	}
+	if got := loaded.Conversation.Last().Message.Content; got != "first answer" {
+		t.Errorf("After SelectSibling, last content = %q, want %q", got, "first answer")
+	}
+
+	// New nodes must not reuse saved IDs
+	added := loaded.Conversation.Append(chat.NewMessage(chat.RoleUser, "more"))
+	if added.ID <= last.ID || added.ID == user.ID {
+		t.Errorf("Appended node ID = %d, collides with saved IDs", added.ID)
+	}
+}
+
+func TestList(t *testing.T) {
+	originalHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", originalHome)
+	os.Setenv("HOME", t.TempDir())
+
+	names, err := List()
+	if err != nil {
+		t.Fatalf("List() with no sessions error = %v", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("List() with no sessions = %q, want empty", names)
+	}
+
+	for _, name := range []string{"zeta", "alpha"} {
+		if err := Save(&Session{Name: name, Conversation: chat.NewTree()}); err != nil {
+			t.Fatalf("Save(%q) error = %v", name, err)
+		}
+	}
+
+	names, err = List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(names) != 2 || names[0] != "alpha" || names[1] != "zeta" {
+		t.Errorf("List() = %q, want [alpha zeta]", names)
+	}
+}
+
+func TestPath_InvalidName(t *testing.T) {
+	for _, name := range []string{"", "..", "../escape", `a\b`} {
+		if _, err := Path(name); err == nil {
+			t.Errorf("Path(%q) error = nil, want error", name)
+		}
+	}
+}
+
+func TestDefaultName(t *testing.T) {
+	ts := time.Date(2026, 3, 4, 5, 6, 7, 0, time.UTC)
+	if got := DefaultName(ts); got != "2026-03-04-050607" {
+		t.Errorf("DefaultName() = %q, want %q", got, "2026-03-04-050607")
+	}
+}
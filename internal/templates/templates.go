@@ -0,0 +1,130 @@
+// Package templates provides starter diagrams for /new. Built-in templates
+// are copied into the config directory the first time it's read, where
+// they can be edited and new ones added as .mmd files.
+package templates
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mnesler/hauk-tui/internal/config"
+)
+
+//go:embed builtin/*.mmd
+var builtin embed.FS
+
+// Ext is the extension of template files
+const Ext = ".mmd"
+
+// Template is a named starter diagram
+type Template struct {
+	Name        string // File name without the extension, e.g. c4-context
+	Description string // From a leading %% comment, if any
+	Source      string // Mermaid source, without the description
+}
+
+// Dir returns the directory templates are kept in
+func Dir() (string, error) {
+	dir, err := config.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "templates"), nil
+}
+
+// Load returns the templates in Dir, creating it with the built-in
+// templates if it doesn't exist yet
+func Load() ([]Template, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	return LoadDir(dir)
+}
+
+// LoadDir returns the templates in dir sorted by name, creating dir with
+// the built-in templates if it doesn't exist. Templates deleted from an
+// existing dir stay deleted.
+func LoadDir(dir string) ([]Template, error) {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := install(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read templates directory: %w", err)
+	}
+
+	var list []Template
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != Ext {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", entry.Name(), err)
+		}
+		list = append(list, Parse(strings.TrimSuffix(entry.Name(), Ext), string(data)))
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list, nil
+}
+
+// Builtin returns the built-in templates sorted by name
+func Builtin() []Template {
+	entries, _ := builtin.ReadDir("builtin")
+	list := make([]Template, 0, len(entries))
+	for _, entry := range entries {
+		data, _ := builtin.ReadFile("builtin/" + entry.Name())
+		list = append(list, Parse(strings.TrimSuffix(entry.Name(), Ext), string(data)))
+	}
+	return list
+}
+
+// Find returns the template with the given name
+func Find(list []Template, name string) (Template, bool) {
+	name = strings.TrimSuffix(name, Ext)
+	for _, t := range list {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// Parse splits a template file into its description, the first line when
+// it's a %% comment, and the diagram source
+func Parse(name, data string) Template {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	t := Template{Name: name, Source: strings.TrimSpace(data)}
+
+	first, rest, _ := strings.Cut(t.Source, "\n")
+	if strings.HasPrefix(first, "%%") && !strings.HasPrefix(first, "%%{") {
+		t.Description = strings.TrimSpace(strings.TrimPrefix(first, "%%"))
+		t.Source = strings.TrimSpace(rest)
+	}
+	return t
+}
+
+// install writes the built-in templates to a new dir
+func install(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create templates directory: %w", err)
+	}
+
+	entries, _ := builtin.ReadDir("builtin")
+	for _, entry := range entries {
+		data, _ := builtin.ReadFile("builtin/" + entry.Name())
+		if err := os.WriteFile(filepath.Join(dir, entry.Name()), data, 0644); err != nil {
+			return fmt.Errorf("failed to write template %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mnesler/hauk-tui/internal/diagram"
+)
+
+func TestLoadDir_InstallsBuiltins(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "templates")
+
+	list, err := LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tmpl := range list {
+		names = append(names, tmpl.Name)
+		if tmpl.Description == "" || strings.HasPrefix(tmpl.Source, "%%") {
+			t.Errorf("%s: description not split from source", tmpl.Name)
+		}
+	}
+	want := "c4-context er-skeleton request-lifecycle state-machine"
+	if got := strings.Join(names, " "); got != want {
+		t.Errorf("names = %q, want %q", got, want)
+	}
+
+	// User changes are kept: a deleted built-in stays deleted and new
+	// files are listed
+	if err := os.Remove(filepath.Join(dir, "er-skeleton.mmd")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mine.mmd"), []byte("flowchart LR\n    A --> B\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("ignored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err = LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Find(list, "er-skeleton"); ok {
+		t.Error("deleted built-in was reinstalled")
+	}
+	mine, ok := Find(list, "mine.mmd")
+	if !ok || mine.Source != "flowchart LR\n    A --> B" || mine.Description != "" {
+		t.Errorf("user template = %+v, found %v", mine, ok)
+	}
+	if len(list) != 4 {
+		t.Errorf("got %d templates, want 4", len(list))
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantDesc string
+		wantSrc  string
+	}{
+		{"description", "%% Login flow\r\nsequenceDiagram\r\n    A->>B: hi\r\n", "Login flow", "sequenceDiagram\n    A->>B: hi"},
+		{"no description", "graph TD\n    A --> B\n", "", "graph TD\n    A --> B"},
+		{"init directive kept", "%%{init: {'theme': 'dark'}}%%\ngraph TD", "", "%%{init: {'theme': 'dark'}}%%\ngraph TD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse("x", tt.data)
+			if got.Description != tt.wantDesc || got.Source != tt.wantSrc {
+				t.Errorf("Parse() = %q, %q, want %q, %q", got.Description, got.Source, tt.wantDesc, tt.wantSrc)
+			}
+		})
+	}
+}
+
+func TestBuiltin(t *testing.T) {
+	for _, tmpl := range Builtin() {
+		if diags := diagram.Validate(tmpl.Source); len(diags) > 0 {
+			t.Errorf("%s: %v", tmpl.Name, diags)
+		}
+	}
+	if len(Builtin()) != 4 {
+		t.Errorf("got %d built-in templates, want 4", len(Builtin()))
+	}
+}